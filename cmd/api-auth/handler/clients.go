@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+
+	"github.com/pkg/errors"
+)
+
+// Client defines the tokens issued to a client type. Every client is requested with device
+// credentials, so it grants at most the user role and no scopes.
+type Client struct {
+	Role       auth.Role `json:"role"`        // role granted by the access token, a name or a number
+	Scopes     []string  `json:"scopes"`      // rejected if set, elevated access is not granted to devices
+	AccessTTL  int64     `json:"access_ttl"`  // access token lifetime in seconds
+	RefreshTTL int64     `json:"refresh_ttl"` // refresh token lifetime in seconds, extended on every refresh
+}
+
+// AccessExpiresIn returns the access token lifetime.
+func (c Client) AccessExpiresIn() time.Duration {
+	return time.Duration(c.AccessTTL) * time.Second
+}
+
+// RefreshExpiresIn returns the refresh token lifetime.
+func (c Client) RefreshExpiresIn() time.Duration {
+	return time.Duration(c.RefreshTTL) * time.Second
+}
+
+// DefaultClients returns the client types used if none are configured.
+func DefaultClients() map[string]Client {
+	return map[string]Client{
+		"app": {
+			Role:       auth.User,
+			AccessTTL:  int64((15 * time.Minute).Seconds()),
+			RefreshTTL: int64((30 * 24 * time.Hour).Seconds()),
+		},
+	}
+}
+
+// ParseClients decodes client types from JSON, e.g. {"app":{"role":"user","access_ttl":900,"refresh_ttl":2592000}}.
+// An empty document returns DefaultClients.
+func ParseClients(data string) (map[string]Client, error) {
+	if data == "" {
+		return DefaultClients(), nil
+	}
+
+	var parsed map[string]Client
+	if err := serializer.UnmarshalJSON([]byte(data), &parsed); err != nil {
+		return nil, errors.Wrap(err, "invalid clients config")
+	}
+	if len(parsed) == 0 {
+		return nil, errors.New("invalid clients config: no clients")
+	}
+	for name, c := range parsed {
+		if c.Role == auth.Device || c.Role > auth.User || !auth.RoleIsValid(c.Role) {
+			return nil, errors.Errorf("invalid clients config: client '%s' has unsupported role", name)
+		}
+		if len(c.Scopes) > 0 {
+			return nil, errors.Errorf("invalid clients config: client '%s' grants scopes", name)
+		}
+		if c.AccessTTL <= 0 || c.RefreshTTL <= 0 {
+			return nil, errors.Errorf("invalid clients config: client '%s' requires access_ttl and refresh_ttl", name)
+		}
+	}
+	return parsed, nil
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingorefresh"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	codeInvalidGrant = "invalid_grant"
+
+	// refresh tokens have the "<family id>.<secret>" format.
+	refreshTokenSeparator = "."
+)
+
+var errRefreshTokenReused = errors.New("refresh token reused")
+
+// invalidGrant returns the error for refresh tokens which are unknown, expired, revoked or reused.
+func invalidGrant(err error) *api.HandleError {
+	return &api.HandleError{
+		Status:  http.StatusUnauthorized,
+		Code:    codeInvalidGrant,
+		Message: "invalid refresh token",
+		Err:     err,
+	}
+}
+
+// newRefreshSecret returns a random refresh token secret and its hash.
+func newRefreshSecret() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", errors.Wrap(err, "failed to generate refresh token")
+	}
+	secret := base64.RawURLEncoding.EncodeToString(buf)
+	return secret, hashSecret(secret), nil
+}
+
+func newFamilyID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, "failed to generate token family id")
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseRefreshToken splits the refresh token into the family id and the secret.
+func parseRefreshToken(token string) (string, string, bool) {
+	familyID, secret, ok := strings.Cut(token, refreshTokenSeparator)
+	if !ok || familyID == "" || secret == "" {
+		return "", "", false
+	}
+	return familyID, secret, true
+}
+
+func familyKey(familyID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		applingorefresh.ColumnId: &types.AttributeValueMemberS{Value: familyID},
+	}
+}
+
+// getFamily returns the refresh token family, nil if it does not exist.
+func getFamily(ctx context.Context, familyID string) (*applingorefresh.SchemaItem, error) {
+	result, err := dbDynamo.Get(ctx, applingorefresh.TableSchema.TableName, familyKey(familyID))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get token family")
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var family applingorefresh.SchemaItem
+	if err := attributevalue.UnmarshalMap(result.Item, &family); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal token family")
+	}
+	return &family, nil
+}
+
+// revokeFamily marks the family as revoked, so none of its refresh tokens can be used.
+func revokeFamily(ctx context.Context, familyID string) error {
+	update := expression.Set(
+		expression.Name(applingorefresh.ColumnIsRevoked),
+		expression.Value(applingorefresh.BoolToInt(true)),
+	)
+	condition := expression.AttributeExists(expression.Name(applingorefresh.ColumnId))
+
+	if err := dbDynamo.Update(ctx, applingorefresh.TableSchema.TableName, familyKey(familyID), update, condition); err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to revoke token family")
+	}
+	return nil
+}
+
+// createFamily starts a refresh token family for the subject and returns the first refresh token.
+func createFamily(ctx context.Context, subject, device, clientName string, client Client) (string, error) {
+	familyID, err := newFamilyID()
+	if err != nil {
+		return "", err
+	}
+	secret, hash, err := newRefreshSecret()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	item, err := applingorefresh.PutItem(applingorefresh.SchemaItem{
+		Id:         familyID,
+		Subject:    subject,
+		Client:     clientName,
+		Device:     device,
+		TokenHash:  hash,
+		Generation: 1,
+		Created:    int(now.Unix()),
+		ExpiresAt:  int(now.Add(client.RefreshExpiresIn()).Unix()),
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := dbDynamo.Put(
+		ctx,
+		applingorefresh.TableSchema.TableName,
+		item,
+		expression.AttributeNotExists(expression.Name(applingorefresh.ColumnId)),
+	); err != nil {
+		return "", errors.Wrap(err, "failed to create token family")
+	}
+	return familyID + refreshTokenSeparator + secret, nil
+}
+
+// rotateFamily replaces the current refresh token of the family and returns the new one.
+// A concurrent rotation with the same token is reported as reuse.
+func rotateFamily(ctx context.Context, family *applingorefresh.SchemaItem, client Client) (string, error) {
+	secret, hash, err := newRefreshSecret()
+	if err != nil {
+		return "", err
+	}
+
+	update := expression.
+		Set(expression.Name(applingorefresh.ColumnTokenHash), expression.Value(hash)).
+		Set(expression.Name(applingorefresh.ColumnGeneration), expression.Value(family.Generation+1)).
+		Set(expression.Name(applingorefresh.ColumnExpiresAt), expression.Value(time.Now().Add(client.RefreshExpiresIn()).Unix()))
+	condition := expression.Name(applingorefresh.ColumnTokenHash).Equal(expression.Value(family.TokenHash)).
+		And(expression.Name(applingorefresh.ColumnIsRevoked).Equal(expression.Value(applingorefresh.BoolToInt(false))))
+
+	if err := dbDynamo.Update(ctx, applingorefresh.TableSchema.TableName, familyKey(family.Id), update, condition); err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return "", errRefreshTokenReused
+		}
+		return "", errors.Wrap(err, "failed to rotate refresh token")
+	}
+	return family.Id + refreshTokenSeparator + secret, nil
+}
+
+// issueTokens signs the access token for the subject and returns it with the refresh token.
+func issueTokens(subject, refreshToken string, client Client) (applingoapi.TokenData, error) {
+	accessToken, err := authenticator.IssueToken(auth.Claims{
+		Role:             client.Role,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: subject},
+	}, client.AccessExpiresIn())
+	if err != nil {
+		return applingoapi.TokenData{}, errors.Wrap(err, "failed to issue access token")
+	}
+	return applingoapi.TokenData{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(client.AccessTTL),
+	}, nil
+}
+
+// logReuse reports refresh token reuse, the family is revoked as its tokens may be stolen.
+func logReuse(logger zerolog.Logger, family *applingorefresh.SchemaItem) {
+	logger.Warn().
+		Str("family", family.Id).
+		Str("subject", family.Subject).
+		Int("generation", family.Generation).
+		Msg("Refresh token reuse detected, token family revoked")
+}
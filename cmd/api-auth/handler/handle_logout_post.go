@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+
+	"github.com/rs/zerolog"
+)
+
+func handleLogoutPost(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
+	req := api.MustGetBody[applingoapi.RequestPostAuthLogoutV1](ctx)
+
+	// logout is idempotent, unknown tokens are ignored.
+	familyID, secret, ok := parseRefreshToken(req.RefreshToken)
+	if !ok {
+		return openapi.DataResponseSuccess, nil
+	}
+	family, err := getFamily(ctx, familyID)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	if family == nil || hashSecret(secret) != family.TokenHash {
+		return openapi.DataResponseSuccess, nil
+	}
+
+	if err := revokeFamily(ctx, family.Id); err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	return openapi.DataResponseSuccess, nil
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingorefresh"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const subjectQueryLimit = 100
+
+func handleTokenDelete(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
+	params := applingoapi.DeleteAuthTokenV1Params{
+		Subject: baseParams.GetStringDefault("subject", ""),
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, api.NewValidationError(err)
+	}
+
+	var startKey map[string]types.AttributeValue
+	for {
+		queryInput, err := dbDynamo.BuildQueryInput(cloud.QueryInput{
+			IndexName:         applingorefresh.IndexSubjectIndex,
+			KeyCondition:      expression.Key(applingorefresh.ColumnSubject).Equal(expression.Value(params.Subject)),
+			ProjectionFields:  []string{applingorefresh.ColumnId},
+			Limit:             subjectQueryLimit,
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		result, err := dbDynamo.Query(ctx, applingorefresh.TableSchema.TableName, queryInput)
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to query token families")}
+		}
+
+		for _, item := range result.Items {
+			id, ok := item[applingorefresh.ColumnId].(*types.AttributeValueMemberS)
+			if !ok {
+				continue
+			}
+			if err := revokeFamily(ctx, id.Value); err != nil {
+				return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+			}
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return nil, nil
+		}
+		startKey = result.LastEvaluatedKey
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingorefresh"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+func handleTokenPost(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
+	req := api.MustGetBody[applingoapi.RequestPostAuthTokenV1](ctx)
+
+	client, ok := clients[req.Client]
+	if !ok {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Message: "unknown client", Err: errors.Errorf("unknown client: %s", req.Client)}
+	}
+
+	switch req.GrantType {
+	case applingoapi.Device:
+		return grantDevice(ctx, req.Client, client)
+	case applingoapi.RefreshToken:
+		return grantRefreshToken(ctx, logger, req, client)
+	default:
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: errors.Errorf("unsupported grant type: %s", req.GrantType)}
+	}
+}
+
+// grantDevice starts a token family for a registered device, the profile of the device becomes the token subject.
+func grantDevice(ctx context.Context, clientName string, client Client) (any, *api.HandleError) {
+	meta := api.MustGetMetaData(ctx)
+	if !meta.IsDevice() || meta.GetDevice() == "" || meta.GetIdentifier() == "" {
+		return nil, &api.HandleError{Status: http.StatusForbidden, Message: "registered device required", Err: errors.New("device grant without device id")}
+	}
+	subject := meta.GetIdentifier()
+
+	refreshToken, err := createFamily(ctx, subject, meta.GetDevice(), clientName, client)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	data, err := issueTokens(subject, refreshToken, client)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	return openapi.DataResponseToken(data), nil
+}
+
+// grantRefreshToken exchanges the current refresh token of a family for a new token pair.
+// Presenting an already rotated token revokes the family.
+func grantRefreshToken(ctx context.Context, logger zerolog.Logger, req *applingoapi.RequestPostAuthTokenV1, client Client) (any, *api.HandleError) {
+	familyID, secret, ok := parseRefreshToken(*req.RefreshToken)
+	if !ok {
+		return nil, invalidGrant(errors.New("malformed refresh token"))
+	}
+	family, err := getFamily(ctx, familyID)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	switch {
+	case family == nil:
+		return nil, invalidGrant(errors.New("token family not found"))
+	case family.IsRevoked == 1:
+		return nil, invalidGrant(errors.New("token family revoked"))
+	case int64(family.ExpiresAt) <= time.Now().Unix():
+		return nil, invalidGrant(errors.New("token family expired"))
+	case family.Client != req.Client:
+		return nil, invalidGrant(errors.New("token family issued to another client"))
+	}
+
+	meta := api.MustGetMetaData(ctx)
+	if id := meta.GetIdentifier(); id != "" {
+		if meta.IsDevice() && family.Device != "" && meta.GetDevice() != family.Device {
+			return nil, invalidGrant(errors.New("token family issued to another device"))
+		}
+		if meta.IsUser() && id != family.Subject {
+			return nil, invalidGrant(errors.New("token family issued to another subject"))
+		}
+	}
+
+	if hashSecret(secret) != family.TokenHash {
+		return nil, revokeReused(ctx, logger, family)
+	}
+	refreshToken, err := rotateFamily(ctx, family, client)
+	if err != nil {
+		if errors.Is(err, errRefreshTokenReused) {
+			return nil, revokeReused(ctx, logger, family)
+		}
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	data, err := issueTokens(family.Subject, refreshToken, client)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	return openapi.DataResponseToken(data), nil
+}
+
+func revokeReused(ctx context.Context, logger zerolog.Logger, family *applingorefresh.SchemaItem) *api.HandleError {
+	if err := revokeFamily(ctx, family.Id); err != nil {
+		return &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	logReuse(logger, family)
+	return &api.HandleError{
+		Status:  http.StatusUnauthorized,
+		Code:    codeInvalidGrant,
+		Message: "refresh token reuse detected",
+		Err:     errRefreshTokenReused,
+	}
+}
@@ -0,0 +1,62 @@
+// Package handler implements the token API routes.
+// It issues short-lived access tokens and single-use refresh tokens grouped in families,
+// a reused refresh token revokes its whole family.
+// It is served by the api-auth Lambda and by the local server.
+package handler
+
+import (
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+)
+
+var (
+	validate      = validator.New()
+	dbDynamo      cloud.DynamoAPI
+	authenticator *auth.Authenticator
+	clients       map[string]Client
+)
+
+// Config holds the handler dependencies.
+type Config struct {
+	Dynamo        cloud.DynamoAPI
+	Authenticator *auth.Authenticator
+
+	// Clients defines the tokens issued per client type, DefaultClients if nil.
+	Clients map[string]Client
+}
+
+// Routes sets up the handler dependencies and returns the routes map.
+func Routes(cfg Config) map[string]api.HandleFunc {
+	dbDynamo = cfg.Dynamo
+	authenticator = cfg.Authenticator
+
+	clients = cfg.Clients
+	if clients == nil {
+		clients = DefaultClients()
+	}
+
+	return map[string]api.HandleFunc{
+		// issue or refresh tokens
+		"POST:/v1/auth/token": api.Chain(
+			handleTokenPost,
+			api.WithPermissions(auth.Device),
+			api.WithJSONBody[applingoapi.RequestPostAuthTokenV1](validate),
+		),
+
+		// revoke all refresh tokens of a subject
+		"DELETE:/v1/auth/token": api.Chain(
+			handleTokenDelete,
+			api.WithUser(auth.Admin),
+		),
+
+		// revoke the refresh token family
+		"POST:/v1/auth/logout": api.Chain(
+			handleLogoutPost,
+			api.WithPermissions(auth.Device),
+			api.WithJSONBody[applingoapi.RequestPostAuthLogoutV1](validate),
+		),
+	}
+}
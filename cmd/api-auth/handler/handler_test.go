@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingorefresh"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "test-secret"
+
+func newTestAPI(t *testing.T) (*api.API, *cloud.MemoryDynamo) {
+	t.Helper()
+
+	db := cloud.NewMemoryDynamo(cloud.NewMemoryTable(applingorefresh.TableSchema))
+
+	return api.NewLambda(api.Config{}, Routes(Config{
+		Dynamo:        db,
+		Authenticator: auth.NewAuthenticator("", testSecret),
+	})), db
+}
+
+func request(method, path string, kind auth.Kind, role auth.Role, identifier, body string, query map[string]string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		HTTPMethod:            method,
+		Path:                  path,
+		Body:                  body,
+		QueryStringParameters: query,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			HTTPMethod: method,
+			Authorizer: map[string]any{
+				"kind":       strconv.Itoa(int(kind)),
+				"role":       strconv.Itoa(int(role)),
+				"identifier": identifier,
+			},
+		},
+	}
+}
+
+// testProfiles links the registered test devices to their profiles, as the authorizer does.
+var testProfiles = map[string]string{"device-1": "profile-1", "device-2": "profile-2", "device-3": "profile-1"}
+
+func deviceToken(t *testing.T, a *api.API, device, body string) (int, applingoapi.TokenData) {
+	t.Helper()
+
+	req := request(http.MethodPost, "/v1/auth/token", auth.HMAC, auth.Device, testProfiles[device], body, nil)
+	if device != "" {
+		req.RequestContext.Authorizer["device"] = device
+	}
+	resp, err := a.Handle(context.Background(), req)
+	require.NoError(t, err)
+
+	var out applingoapi.ResponsePostAuthTokenV1
+	if resp.StatusCode == http.StatusCreated {
+		require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	}
+	return resp.StatusCode, out.Data
+}
+
+func refreshBody(token string) string {
+	return `{"grant_type":"refresh_token","client":"app","refresh_token":"` + token + `"}`
+}
+
+func getFamilyItem(t *testing.T, db *cloud.MemoryDynamo, token string) map[string]types.AttributeValue {
+	t.Helper()
+
+	familyID, _, ok := parseRefreshToken(token)
+	require.True(t, ok)
+	out, err := db.Get(context.Background(), applingorefresh.TableSchema.TableName, familyKey(familyID))
+	require.NoError(t, err)
+	return out.Item
+}
+
+func TestTokenPostDevice(t *testing.T) {
+	a, _ := newTestAPI(t)
+
+	status, data := deviceToken(t, a, "device-1", `{"grant_type":"device","client":"app"}`)
+	require.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "Bearer", data.TokenType)
+	assert.Equal(t, 900, data.ExpiresIn)
+	assert.NotEmpty(t, data.RefreshToken)
+
+	claims, err := auth.NewAuthenticator("", testSecret).ValidateJWTToken(data.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "profile-1", claims.UserID())
+	assert.Equal(t, auth.User, claims.Role)
+
+	status, _ = deviceToken(t, a, "", `{"grant_type":"device","client":"app"}`)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	status, _ = deviceToken(t, a, "device-1", `{"grant_type":"device","client":"web"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = deviceToken(t, a, "device-1", `{"grant_type":"refresh_token","client":"app"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestTokenPostRefresh(t *testing.T) {
+	a, db := newTestAPI(t)
+
+	status, first := deviceToken(t, a, "device-1", `{"grant_type":"device","client":"app"}`)
+	require.Equal(t, http.StatusCreated, status)
+
+	status, second := deviceToken(t, a, "device-1", refreshBody(first.RefreshToken))
+	require.Equal(t, http.StatusCreated, status)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "2"}, getFamilyItem(t, db, second.RefreshToken)[applingorefresh.ColumnGeneration])
+
+	// another device cannot use the token, even one of the same profile.
+	status, _ = deviceToken(t, a, "device-2", refreshBody(second.RefreshToken))
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, _ = deviceToken(t, a, "device-3", refreshBody(second.RefreshToken))
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// reusing the rotated token revokes the whole family.
+	status, _ = deviceToken(t, a, "device-1", refreshBody(first.RefreshToken))
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1"}, getFamilyItem(t, db, first.RefreshToken)[applingorefresh.ColumnIsRevoked])
+
+	status, _ = deviceToken(t, a, "device-1", refreshBody(second.RefreshToken))
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, _ = deviceToken(t, a, "device-1", refreshBody("malformed"))
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestLogoutPost(t *testing.T) {
+	a, db := newTestAPI(t)
+
+	status, data := deviceToken(t, a, "device-1", `{"grant_type":"device","client":"app"}`)
+	require.Equal(t, http.StatusCreated, status)
+
+	for _, token := range []string{data.RefreshToken, data.RefreshToken, "unknown.token"} {
+		resp, err := a.Handle(context.Background(), request(http.MethodPost, "/v1/auth/logout", auth.HMAC, auth.Device, "device-1", `{"refresh_token":"`+token+`"}`, nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode, resp.Body)
+	}
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1"}, getFamilyItem(t, db, data.RefreshToken)[applingorefresh.ColumnIsRevoked])
+
+	status, _ = deviceToken(t, a, "device-1", refreshBody(data.RefreshToken))
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestTokenDelete(t *testing.T) {
+	a, db := newTestAPI(t)
+
+	var tokens []string
+	for range 2 {
+		status, data := deviceToken(t, a, "device-1", `{"grant_type":"device","client":"app"}`)
+		require.Equal(t, http.StatusCreated, status)
+		tokens = append(tokens, data.RefreshToken)
+	}
+	status, other := deviceToken(t, a, "device-2", `{"grant_type":"device","client":"app"}`)
+	require.Equal(t, http.StatusCreated, status)
+
+	query := map[string]string{"subject": "profile-1"}
+	resp, err := a.Handle(context.Background(), request(http.MethodDelete, "/v1/auth/token", auth.JWT, auth.User, "user-1", "", query))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, err = a.Handle(context.Background(), request(http.MethodDelete, "/v1/auth/token", auth.JWT, auth.Admin, "admin-1", "", query))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode, resp.Body)
+
+	for _, token := range tokens {
+		assert.Equal(t, &types.AttributeValueMemberN{Value: "1"}, getFamilyItem(t, db, token)[applingorefresh.ColumnIsRevoked])
+	}
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "0"}, getFamilyItem(t, db, other.RefreshToken)[applingorefresh.ColumnIsRevoked])
+}
+
+func TestParseClients(t *testing.T) {
+	got, err := ParseClients(`{"web":{"role":"user","access_ttl":300,"refresh_ttl":3600}}`)
+	require.NoError(t, err)
+	assert.Equal(t, Client{Role: auth.User, AccessTTL: 300, RefreshTTL: 3600}, got["web"])
+
+	got, err = ParseClients("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultClients(), got)
+
+	for _, data := range []string{
+		`{"app":{"role":"device","access_ttl":300,"refresh_ttl":3600}}`,
+		`{"app":{"role":"manager","access_ttl":300,"refresh_ttl":3600}}`,
+		`{"app":{"role":"admin","access_ttl":300,"refresh_ttl":3600}}`,
+		`{"app":{"role":"user","scopes":["dictionary:write"],"access_ttl":300,"refresh_ttl":3600}}`,
+		`{"app":{"role":"user","access_ttl":0,"refresh_ttl":3600}}`,
+		`{"app":{"role":"unknown","access_ttl":300,"refresh_ttl":3600}}`,
+		`not json`,
+	} {
+		_, err := ParseClients(data)
+		assert.Error(t, err, data)
+	}
+}
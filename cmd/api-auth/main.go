@@ -0,0 +1,71 @@
+// Package main implements the Lambda API for user tokens.
+// It issues signed access tokens with rotating refresh tokens
+// and revokes refresh tokens on logout or by an admin.
+package main
+
+import (
+	"context"
+	"os"
+	"runtime/debug"
+
+	"github.com/Mad-Pixels/applingo-api/cmd/api-auth/handler"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+var (
+	awsRegion   = os.Getenv("AWS_REGION")
+	jwtSecret   = os.Getenv("JWT_SECRET")
+	jwtIssuer   = os.Getenv("JWT_ISSUER")
+	jwtAudience = os.Getenv("JWT_AUDIENCE")
+	authClients = os.Getenv("AUTH_CLIENTS")
+
+	dbDynamo *cloud.Dynamo
+	clients  map[string]handler.Client
+)
+
+func init() {
+	debug.SetGCPercent(500)
+
+	if jwtSecret == "" {
+		panic("JWT_SECRET environment variable must be set")
+	}
+	var err error
+	if clients, err = handler.ParseClients(authClients); err != nil {
+		panic("invalid AUTH_CLIENTS: " + err.Error())
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
+	if err != nil {
+		panic("unable to load AWS SDK config: " + err.Error())
+	}
+	dbDynamo = cloud.NewDynamo(cfg)
+}
+
+func main() {
+	lambda.Start(
+		api.NewLambda(
+			api.Config{
+				EnableRequestLogging: true,
+				Middlewares: []api.Middleware{
+					api.WithRecovery(),
+					api.WithTiming(),
+				},
+			},
+			handler.Routes(handler.Config{
+				Dynamo: dbDynamo,
+				Authenticator: auth.NewAuthenticator(
+					"",
+					jwtSecret,
+					auth.WithIssuer(jwtIssuer),
+					auth.WithAudience(jwtAudience),
+				),
+				Clients: clients,
+			}),
+		).Handle,
+	)
+}
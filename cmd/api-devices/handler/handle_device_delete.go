@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodevice"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+func handleDeviceDelete(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
+	params := applingoapi.DeleteDeviceV1Params{
+		Id: baseParams.GetStringDefault("id", ""),
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, api.NewValidationError(err)
+	}
+
+	// revoked devices stay in the table, the profile registers a new device to get new credentials.
+	key := map[string]types.AttributeValue{
+		applingodevice.ColumnId: &types.AttributeValueMemberS{Value: params.Id},
+	}
+	update := expression.
+		Set(expression.Name(applingodevice.ColumnIsRevoked), expression.Value(applingodevice.BoolToInt(true))).
+		Set(expression.Name(applingodevice.ColumnRevokedAt), expression.Value(time.Now().Unix()))
+	condition := expression.AttributeExists(expression.Name(applingodevice.ColumnId))
+
+	if err := dbDynamo.Update(ctx, applingodevice.TableSchema.TableName, key, update, condition); err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("device not found")}
+		}
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to revoke device")}
+	}
+	return nil, nil
+}
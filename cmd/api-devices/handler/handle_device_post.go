@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodevice"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprofile"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/device"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// handleDevicePost registers a device of the profile and returns its credentials. The first device of a
+// profile may register with the shared token, every further device is added by a request signed by an
+// active device of the same profile, so knowing the profile id is not enough to get its credentials.
+func handleDevicePost(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
+	if !deviceKeys.Enabled() {
+		return nil, &api.HandleError{Status: http.StatusServiceUnavailable, Err: errors.New("device master key is not configured")}
+	}
+	req := api.MustGetBody[applingoapi.RequestPostDeviceV1](ctx)
+
+	meta := api.MustGetMetaData(ctx)
+	signed := meta.GetDevice() != ""
+	if signed && meta.GetIdentifier() != req.Id {
+		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("profile of another device")}
+	}
+
+	// devices are bound to an existing profile.
+	profile, err := dbDynamo.Get(ctx, applingoprofile.TableSchema.TableName, map[string]types.AttributeValue{
+		applingoprofile.ColumnId: &types.AttributeValueMemberS{Value: req.Id},
+	})
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to get profile")}
+	}
+	if profile.Item == nil {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Message: "profile not found", Err: errors.New("profile not found")}
+	}
+	if !signed {
+		registered, err := device.Registered(ctx, dbDynamo, req.Id)
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		if registered {
+			return nil, &api.HandleError{
+				Status:  http.StatusForbidden,
+				Message: "profile already has a device, register the new one from it",
+				Err:     errors.New("unsigned registration of a profile with devices"),
+			}
+		}
+	}
+
+	// the device id is generated here, so a profile may have any number of devices and registering
+	// again after a reinstall or a revocation gets a new id instead of a conflict.
+	deviceID := uuid.New().String()
+	item, err := applingodevice.PutItem(applingodevice.SchemaItem{
+		Id:        deviceID,
+		ProfileId: req.Id,
+		Created:   int(time.Now().Unix()),
+	})
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	if err := dbDynamo.Put(
+		ctx,
+		applingodevice.TableSchema.TableName,
+		item,
+		expression.AttributeNotExists(expression.Name(applingodevice.ColumnId)),
+	); err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil, &api.HandleError{Status: http.StatusConflict, Message: "device id collision, retry the registration", Err: err}
+		}
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	return openapi.DataResponseDevice(applingoapi.DeviceData{
+		Id:        deviceID,
+		ProfileId: req.Id,
+		Secret:    deviceKeys.Secret(deviceID),
+	}), nil
+}
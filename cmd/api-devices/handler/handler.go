@@ -0,0 +1,44 @@
+// Package handler implements the device registration API routes.
+// It is served by the api-devices Lambda and by the local server.
+package handler
+
+import (
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+)
+
+var (
+	validate   = validator.New()
+	dbDynamo   cloud.DynamoAPI
+	deviceKeys *auth.DeviceKeys
+)
+
+// Config holds the handler dependencies.
+type Config struct {
+	Dynamo     cloud.DynamoAPI
+	DeviceKeys *auth.DeviceKeys
+}
+
+// Routes sets up the handler dependencies and returns the routes map.
+func Routes(cfg Config) map[string]api.HandleFunc {
+	dbDynamo = cfg.Dynamo
+	deviceKeys = cfg.DeviceKeys
+
+	return map[string]api.HandleFunc{
+		// register device
+		"POST:/v1/device": api.Chain(
+			handleDevicePost,
+			api.WithDevice(),
+			api.WithJSONBody[applingoapi.RequestPostDeviceV1](validate),
+		),
+
+		// revoke device
+		"DELETE:/v1/device": api.Chain(
+			handleDeviceDelete,
+			api.WithUser(auth.Admin),
+		),
+	}
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodevice"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprofile"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPI(t *testing.T) (*api.API, *cloud.MemoryDynamo) {
+	t.Helper()
+
+	db := cloud.NewMemoryDynamo(
+		cloud.NewMemoryTable(applingodevice.TableSchema),
+		cloud.NewMemoryTable(applingoprofile.TableSchema),
+	)
+	item, err := applingoprofile.PutItem(applingoprofile.SchemaItem{Id: "profile-1", Level: 1})
+	require.NoError(t, err)
+	require.NoError(t, db.Put(context.Background(), applingoprofile.TableSchema.TableName, item, expression.ConditionBuilder{}))
+
+	return api.NewLambda(api.Config{}, Routes(Config{Dynamo: db, DeviceKeys: auth.NewDeviceKeys("master")})), db
+}
+
+func request(method string, kind auth.Kind, role auth.Role, body string, query map[string]string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		HTTPMethod:            method,
+		Path:                  "/v1/device",
+		Body:                  body,
+		QueryStringParameters: query,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			HTTPMethod: method,
+			Authorizer: map[string]any{
+				"kind": strconv.Itoa(int(kind)),
+				"role": strconv.Itoa(int(role)),
+			},
+		},
+	}
+}
+
+// registerDevice registers a device of the profile, signed by the device when it is set
+// or with the shared token otherwise.
+func registerDevice(t *testing.T, a *api.API, profileID string, device *applingoapi.DeviceData) (int, applingoapi.DeviceData) {
+	t.Helper()
+
+	req := request(http.MethodPost, auth.HMAC, auth.Device, `{"id":"`+profileID+`"}`, nil)
+	if device != nil {
+		req.RequestContext.Authorizer["device"] = string(device.Id)
+		req.RequestContext.Authorizer["identifier"] = string(device.ProfileId)
+	}
+	resp, err := a.Handle(context.Background(), req)
+	require.NoError(t, err)
+
+	var out applingoapi.ResponsePostDeviceV1
+	if resp.StatusCode == http.StatusCreated {
+		require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	}
+	return resp.StatusCode, out.Data
+}
+
+func getDevice(t *testing.T, db *cloud.MemoryDynamo, id string) map[string]types.AttributeValue {
+	t.Helper()
+
+	out, err := db.Get(context.Background(), applingodevice.TableSchema.TableName, map[string]types.AttributeValue{
+		applingodevice.ColumnId: &types.AttributeValueMemberS{Value: id},
+	})
+	require.NoError(t, err)
+	return out.Item
+}
+
+func TestDevicePost(t *testing.T) {
+	a, db := newTestAPI(t)
+
+	status, first := registerDevice(t, a, "profile-1", nil)
+	require.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "profile-1", string(first.ProfileId))
+	assert.NotEqual(t, "profile-1", string(first.Id))
+	assert.Equal(t, auth.NewDeviceKeys("master").Secret(string(first.Id)), first.Secret)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "profile-1"}, getDevice(t, db, string(first.Id))[applingodevice.ColumnProfileId])
+
+	// further devices are not registered with the shared token.
+	status, _ = registerDevice(t, a, "profile-1", nil)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// a second device registered by the first one gets its own id and secret.
+	status, second := registerDevice(t, a, "profile-1", &first)
+	require.Equal(t, http.StatusCreated, status)
+	assert.NotEqual(t, first.Id, second.Id)
+	assert.NotEqual(t, first.Secret, second.Secret)
+
+	// a device does not register devices of another profile.
+	other := applingoapi.DeviceData{Id: "device-9", ProfileId: "profile-9"}
+	status, _ = registerDevice(t, a, "profile-1", &other)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	status, _ = registerDevice(t, a, "profile-2", nil)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestDevicePostLegacy(t *testing.T) {
+	a, db := newTestAPI(t)
+
+	// devices registered before the link used the profile id as the device id.
+	item, err := applingodevice.PutItem(applingodevice.SchemaItem{Id: "profile-1"})
+	require.NoError(t, err)
+	require.NoError(t, db.Put(context.Background(), applingodevice.TableSchema.TableName, item, expression.ConditionBuilder{}))
+
+	status, _ := registerDevice(t, a, "profile-1", nil)
+	assert.Equal(t, http.StatusForbidden, status)
+}
+
+func TestDeviceDelete(t *testing.T) {
+	a, db := newTestAPI(t)
+
+	status, device := registerDevice(t, a, "profile-1", nil)
+	require.Equal(t, http.StatusCreated, status)
+
+	query := map[string]string{"id": string(device.Id)}
+	resp, err := a.Handle(context.Background(), request(http.MethodDelete, auth.JWT, auth.User, "", query))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, err = a.Handle(context.Background(), request(http.MethodDelete, auth.JWT, auth.Admin, "", query))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode, resp.Body)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1"}, getDevice(t, db, string(device.Id))[applingodevice.ColumnIsRevoked])
+
+	// the profile without active devices registers again with the shared token.
+	status, again := registerDevice(t, a, "profile-1", nil)
+	require.Equal(t, http.StatusCreated, status)
+	assert.NotEqual(t, device.Id, again.Id)
+
+	resp, err = a.Handle(context.Background(), request(http.MethodDelete, auth.JWT, auth.Admin, "", map[string]string{"id": "device-2"}))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,52 @@
+// Package main implements the Lambda API for device registration.
+// It issues per-device signing secrets bound to a profile
+// and revokes devices so the authorizer denies them.
+package main
+
+import (
+	"context"
+	"os"
+	"runtime/debug"
+
+	"github.com/Mad-Pixels/applingo-api/cmd/api-devices/handler"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+var (
+	awsRegion = os.Getenv("AWS_REGION")
+	masterKey = os.Getenv("DEVICE_MASTER_KEY")
+	dbDynamo  *cloud.Dynamo
+)
+
+func init() {
+	debug.SetGCPercent(500)
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
+	if err != nil {
+		panic("unable to load AWS SDK config: " + err.Error())
+	}
+	dbDynamo = cloud.NewDynamo(cfg)
+}
+
+func main() {
+	lambda.Start(
+		api.NewLambda(
+			api.Config{
+				EnableRequestLogging: true,
+				Middlewares: []api.Middleware{
+					api.WithRecovery(),
+					api.WithTiming(),
+				},
+			},
+			handler.Routes(handler.Config{
+				Dynamo:     dbDynamo,
+				DeviceKeys: auth.NewDeviceKeys(masterKey),
+			}),
+		).Handle,
+	)
+}
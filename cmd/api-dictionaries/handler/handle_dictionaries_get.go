@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	lingo "github.com/Mad-Pixels/applingo-api/lingo-interface/types"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const pageLimit = 150
+
+func handleDictionariesGet(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
+	validSortValues := map[applingoapi.BaseDictSortEnum]struct{}{
+		applingoapi.Date:   {},
+		applingoapi.Rating: {},
+	}
+	paramSort, err := openapi.ParseEnumParam(baseParams.GetStringPtr("sort_by"), validSortValues)
+	if err != nil {
+		return nil, api.NewParamError("sort_by", err)
+	}
+	validTopicGroups := make(map[applingoapi.BaseTopicGroupEnum]struct{})
+	for _, group := range lingo.AllDictionaryTopicGroups() {
+		validTopicGroups[applingoapi.BaseTopicGroupEnum(group.String())] = struct{}{}
+	}
+	paramTopicGroup, err := openapi.ParseEnumParam(baseParams.GetStringPtr("topic_group"), validTopicGroups)
+	if err != nil {
+		return nil, api.NewParamError("topic_group", err)
+	}
+	params := applingoapi.GetDictionariesV1Params{
+		Subcategory:   baseParams.GetStringPtr("subcategory"),
+		LastEvaluated: baseParams.GetStringPtr("last_evaluated"),
+		Level:         baseParams.GetStringPtr("level"),
+		Topic:         baseParams.GetStringPtr("topic"),
+		TopicGroup:    paramTopicGroup,
+		Public:        baseParams.GetBoolPtr("public"),
+		SortBy:        paramSort,
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, api.NewValidationError(err)
+	}
+
+	queryInput, err := buildQueryInput(params)
+	if err != nil {
+		return nil, api.NewParamError("last_evaluated", err)
+	}
+	dynamoQueryInput, err := dbDynamo.BuildQueryInput(*queryInput)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	result, err := dbDynamo.Query(ctx, applingodictionary.TableName, dynamoQueryInput)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	response := applingoapi.DictionariesData{
+		Items: make([]applingoapi.DictionaryItemV1, 0, len(result.Items)),
+	}
+	for _, item := range result.Items {
+		var dict applingodictionary.SchemaItem
+		if err := attributevalue.UnmarshalMap(item, &dict); err != nil {
+			logger.Warn().Err(err).Msg("Failed to unmarshal DynamoDB item")
+			continue
+		}
+
+		response.Items = append(response.Items, dictionaryItem(dict))
+	}
+	if response.LastEvaluated, err = encodeLastEvaluated(result.LastEvaluatedKey); err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	return openapi.DataResponseDictionaries(response), nil
+}
+
+// encodeLastEvaluated returns the page cursor of the query result, nil on the last page.
+func encodeLastEvaluated(lastEvaluatedKey map[string]types.AttributeValue) (*string, error) {
+	if lastEvaluatedKey == nil {
+		return nil, nil
+	}
+	var lastEvaluatedKeyMap map[string]any
+	if err := attributevalue.UnmarshalMap(lastEvaluatedKey, &lastEvaluatedKeyMap); err != nil {
+		return nil, err
+	}
+	lastEvaluatedKeyJSON, err := serializer.MarshalJSON(lastEvaluatedKeyMap)
+	if err != nil {
+		return nil, err
+	}
+	page := base64.StdEncoding.EncodeToString(lastEvaluatedKeyJSON)
+	return &page, nil
+}
+
+// decodeLastEvaluated parses the page cursor returned by encodeLastEvaluated.
+func decodeLastEvaluated(page string) (map[string]types.AttributeValue, error) {
+	lastEvaluatedKeyJSON, err := base64.StdEncoding.DecodeString(page)
+	if err != nil {
+		return nil, errors.New("invalid last_evaluated key: unable to decode base64")
+	}
+
+	var jsonMap map[string]any
+	if err := json.Unmarshal(lastEvaluatedKeyJSON, &jsonMap); err != nil {
+		return nil, errors.New("invalid last_evaluated key: unable to unmarshal JSON")
+	}
+	lastEvaluatedKey, err := applingodictionary.ConvertMapToAttributeValues(jsonMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert last_evaluated to DynamoDB types")
+	}
+	return lastEvaluatedKey, nil
+}
+
+// dictionaryItem converts the table item to the API representation.
+func dictionaryItem(dict applingodictionary.SchemaItem) applingoapi.DictionaryItemV1 {
+	return applingoapi.DictionaryItemV1{
+		Id:          dict.Id,
+		Category:    applingoapi.BaseCategoryEnum(dict.Category),
+		Public:      applingodictionary.IntToBool(dict.IsPublic),
+		Dictionary:  utils.RecordToFileID(dict.Id),
+		Downloads:   int64(dict.Downloads),
+		Created:     int64(dict.Created),
+		Rating:      int32(dict.Rating),
+		Words:       int32(dict.Words),
+		Subcategory: dict.Subcategory,
+		Description: dict.Description,
+		Author:      dict.Author,
+		Name:        dict.Name,
+		Level:       dict.Level,
+		Topic:       dict.Topic,
+		TopicGroup:  topicGroup(dict),
+		Version:     int64(dict.Version),
+	}
+}
+
+// topicGroup returns the stored topic group, items written before topic groups have none.
+func topicGroup(dict applingodictionary.SchemaItem) *applingoapi.BaseTopicGroupEnum {
+	if dict.TopicGroup == "" {
+		return nil
+	}
+	group := applingoapi.BaseTopicGroupEnum(dict.TopicGroup)
+	return &group
+}
+
+func buildQueryInput(params applingoapi.GetDictionariesV1Params) (*cloud.QueryInput, error) {
+	qb := applingodictionary.NewQueryBuilder()
+
+	isPublic := true //nolint:staticcheck
+	if params.Public != nil && !*params.Public {
+		isPublic = false
+	}
+	qb.WithIsPublic(applingodictionary.BoolToInt(isPublic))
+
+	if params.Level != nil {
+		qb.WithLevel(*params.Level)
+	}
+	if params.Subcategory != nil {
+		qb.WithSubcategory(*params.Subcategory)
+	}
+	// the builder picks the index with most matching key parts, the other filters become filter expressions.
+	if params.Topic != nil {
+		qb.WithTopic(*params.Topic)
+	}
+	if params.TopicGroup != nil {
+		qb.WithTopicGroup(string(*params.TopicGroup))
+	}
+
+	sortBy := applingoapi.Date
+	if params.SortBy != nil {
+		sortBy = applingoapi.ParamDictionarySortEnum(*params.SortBy)
+	}
+	useRatingSort := sortBy == applingoapi.Rating
+	if useRatingSort {
+		qb.WithPreferredSortKey(string(applingoapi.Rating))
+		qb.WithRatingGreaterThan(-1)
+	} else {
+		qb.WithPreferredSortKey(string(applingoapi.Date))
+		qb.WithCreatedGreaterThan(0)
+	}
+
+	if params.LastEvaluated != nil {
+		lastEvaluatedKey, err := decodeLastEvaluated(*params.LastEvaluated)
+		if err != nil {
+			return nil, err
+		}
+		qb.StartFrom(lastEvaluatedKey)
+	}
+	qb.Limit(pageLimit)
+
+	indexName, keyCondition, filterCondition, exclusiveStartKey, err := qb.Build()
+	if err != nil {
+		return nil, err
+	}
+	queryInput := &cloud.QueryInput{
+		IndexName:         indexName,
+		KeyCondition:      keyCondition,
+		ProjectionFields:  applingodictionary.IndexProjections[indexName],
+		Limit:             pageLimit,
+		ScanForward:       false,
+		ExclusiveStartKey: exclusiveStartKey,
+	}
+	if filterCondition != nil {
+		queryInput.FilterCondition = *filterCondition
+	}
+	return queryInput, nil
+}
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingosearch"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/search"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	searchPageLimit = 50
+
+	// searchTermRows caps the index entries read per query term.
+	searchTermRows = 1000
+
+	// searchCandidates caps the dictionaries loaded for ranking by rating and downloads.
+	searchCandidates = 200
+)
+
+// searchCursor is the position of the last returned dictionary in the ranked results.
+type searchCursor struct {
+	Score float64 `json:"score"`
+	ID    string  `json:"id"`
+}
+
+type searchHit struct {
+	id          string
+	subcategory string
+	relevance   float64
+}
+
+type searchResult struct {
+	dict  applingodictionary.SchemaItem
+	score float64
+}
+
+func handleDictionariesSearch(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
+	params := applingoapi.SearchDictionariesV1Params{
+		Q:             baseParams.GetStringDefault("q", ""),
+		LastEvaluated: baseParams.GetStringPtr("last_evaluated"),
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, api.NewValidationError(err)
+	}
+	terms := search.Tokenize(params.Q)
+	if len(terms) == 0 {
+		return nil, api.NewParamError("q", errors.New("query has no searchable terms"))
+	}
+	var cursor *searchCursor
+	if params.LastEvaluated != nil {
+		c, err := decodeSearchCursor(*params.LastEvaluated)
+		if err != nil {
+			return nil, api.NewParamError("last_evaluated", err)
+		}
+		cursor = c
+	}
+
+	hits, err := searchHits(ctx, terms)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	dicts, err := hitDictionaries(ctx, logger, hits)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	results := make([]searchResult, 0, len(hits))
+	for _, hit := range hits {
+		dict, ok := dicts[hit.id+"#"+hit.subcategory]
+		// the index may lag behind the table, so visibility is checked on the item itself.
+		if !ok || !applingodictionary.IntToBool(dict.IsPublic) {
+			continue
+		}
+		results = append(results, searchResult{
+			dict:  dict,
+			score: search.Score(hit.relevance, dict.Rating, dict.Downloads),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].dict.Id < results[j].dict.Id
+	})
+
+	start := 0
+	if cursor != nil {
+		start = sort.Search(len(results), func(i int) bool {
+			return results[i].score < cursor.Score || (results[i].score == cursor.Score && results[i].dict.Id > cursor.ID)
+		})
+	}
+	end := min(start+searchPageLimit, len(results))
+
+	response := applingoapi.DictionariesData{
+		Items: make([]applingoapi.DictionaryItemV1, 0, end-start),
+	}
+	for _, result := range results[start:end] {
+		response.Items = append(response.Items, dictionaryItem(result.dict))
+	}
+	if end < len(results) {
+		last := results[end-1]
+		page, err := encodeSearchCursor(searchCursor{Score: last.score, ID: last.dict.Id})
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		response.LastEvaluated = &page
+	}
+	return openapi.DataResponseDictionaries(response), nil
+}
+
+// hitDictionaries reads the dictionaries of the hits in batches, keyed by id and subcategory.
+// Dictionaries which are gone or can not be decoded are left out.
+func hitDictionaries(ctx context.Context, logger zerolog.Logger, hits []searchHit) (map[string]applingodictionary.SchemaItem, error) {
+	keys := make([]map[string]types.AttributeValue, 0, len(hits))
+	for _, hit := range hits {
+		key, err := applingodictionary.CreateKey(hit.id, hit.subcategory)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	items, err := dbDynamo.BatchGet(ctx, applingodictionary.TableSchema.TableName, keys)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get dictionaries")
+	}
+
+	dicts := make(map[string]applingodictionary.SchemaItem, len(items))
+	for _, item := range items {
+		var dict applingodictionary.SchemaItem
+		if err := attributevalue.UnmarshalMap(item, &dict); err != nil {
+			logger.Warn().Err(err).Msg("Failed to unmarshal DynamoDB item")
+			continue
+		}
+		dicts[dict.Id+"#"+dict.Subcategory] = dict
+	}
+	return dicts, nil
+}
+
+// searchHits returns the dictionaries matching every term, the last term also matches as a prefix.
+// Only the searchCandidates most relevant dictionaries are returned.
+func searchHits(ctx context.Context, terms []string) ([]searchHit, error) {
+	var matched map[string]*searchHit
+	for i, term := range terms {
+		prefix := i == len(terms)-1
+
+		entries, err := indexEntries(ctx, term, prefix)
+		if err != nil {
+			return nil, err
+		}
+		next := make(map[string]*searchHit, len(entries))
+		for _, entry := range entries {
+			relevance := search.Relevance(search.Posting{Weight: entry.Weight, PrefixWeight: entry.PrefixWeight}, prefix)
+			if matched == nil {
+				next[entry.DictionaryId] = &searchHit{id: entry.DictionaryId, subcategory: entry.Subcategory, relevance: relevance}
+				continue
+			}
+			if hit, ok := matched[entry.DictionaryId]; ok {
+				hit.relevance += relevance
+				next[entry.DictionaryId] = hit
+			}
+		}
+		if matched = next; len(matched) == 0 {
+			return nil, nil
+		}
+	}
+
+	hits := make([]searchHit, 0, len(matched))
+	for _, hit := range matched {
+		hits = append(hits, *hit)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].relevance != hits[j].relevance {
+			return hits[i].relevance > hits[j].relevance
+		}
+		return hits[i].id < hits[j].id
+	})
+	return hits[:min(len(hits), searchCandidates)], nil
+}
+
+// indexEntries reads the index entries of a term. Entries which only match
+// as a prefix are skipped unless prefix is set.
+func indexEntries(ctx context.Context, term string, prefix bool) ([]applingosearch.SchemaItem, error) {
+	input := cloud.QueryInput{
+		KeyCondition: expression.Key(applingosearch.ColumnToken).Equal(expression.Value(term)),
+		Limit:        searchTermRows,
+		ScanForward:  true,
+	}
+	if !prefix {
+		input.FilterCondition = expression.Name(applingosearch.ColumnWeight).GreaterThan(expression.Value(0))
+	}
+
+	var entries []applingosearch.SchemaItem
+	for len(entries) < searchTermRows {
+		queryInput, err := dbDynamo.BuildQueryInput(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build search query")
+		}
+		result, err := dbDynamo.Query(ctx, applingosearch.TableSchema.TableName, queryInput)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query search index")
+		}
+		var page []applingosearch.SchemaItem
+		if err = attributevalue.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal search index")
+		}
+		entries = append(entries, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+	return entries[:min(len(entries), searchTermRows)], nil
+}
+
+func encodeSearchCursor(cursor searchCursor) (string, error) {
+	data, err := serializer.MarshalJSON(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func decodeSearchCursor(value string) (*searchCursor, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("invalid last_evaluated key: unable to decode base64")
+	}
+	var cursor searchCursor
+	if err := serializer.UnmarshalJSON(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, errors.New("invalid last_evaluated key: unable to unmarshal JSON")
+	}
+	return &cursor, nil
+}
@@ -1,4 +1,4 @@
-package main
+package handler
 
 import (
 	"context"
@@ -9,7 +9,6 @@ import (
 	"github.com/Mad-Pixels/applingo-api/openapi-interface"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
-	"github.com/Mad-Pixels/applingo-api/pkg/auth"
 	"github.com/Mad-Pixels/applingo-api/pkg/utils"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
@@ -18,17 +17,13 @@ import (
 )
 
 func handleDictionaryDelete(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
-	if api.MustGetMetaData(ctx).IsDevice() || !api.MustGetMetaData(ctx).HasPermissions(auth.User) {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
-	}
-
 	params := applingoapi.DeleteDictionaryV1Params{
 		Name:        baseParams.GetStringDefault("name", ""),
 		Author:      baseParams.GetStringDefault("author", ""),
 		Subcategory: baseParams.GetStringDefault("subcategory", ""),
 	}
 	if err := validate.ValidateStruct(&params); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+		return nil, api.NewValidationError(err)
 	}
 
 	id := utils.GenerateDictionaryID(params.Name, params.Author)
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// downloadExpiresIn matches the presign expiration of download URLs in cloud.Bucket.
+const downloadExpiresIn = 30
+
+func handleDictionaryGet(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
+	id := api.GetPathParams(ctx).GetStringDefault("id", "")
+	if err := validate.ValidateField(id, "required,len=32,hexadecimal"); err != nil {
+		return nil, api.NewParamError("id", err)
+	}
+	params := applingoapi.GetDictionaryV1Params{
+		Subcategory: baseParams.GetStringDefault("subcategory", ""),
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, api.NewValidationError(err)
+	}
+
+	result, err := dbDynamo.Get(ctx, applingodictionary.TableSchema.TableName, map[string]types.AttributeValue{
+		applingodictionary.ColumnId:          &types.AttributeValueMemberS{Value: id},
+		applingodictionary.ColumnSubcategory: &types.AttributeValueMemberS{Value: params.Subcategory},
+	})
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to get dictionary")}
+	}
+	if result.Item == nil {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("dictionary not found")}
+	}
+	var dict applingodictionary.SchemaItem
+	if err := attributevalue.UnmarshalMap(result.Item, &dict); err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to unmarshal dictionary")}
+	}
+
+	// private dictionaries are reported as missing to everyone except the author and managers.
+	meta := api.MustGetMetaData(ctx)
+	if !canRead(meta, dict) {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("dictionary is private")}
+	}
+
+	// the download URL is presigned per request, so the ETag covers the item and the caller's vote only.
+	item := dictionaryDetails(dict)
+	if voterKey, err := voter(meta); err == nil {
+		rating, err := getRating(ctx, id, voterKey)
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		vote := applingoapi.None
+		if rating != nil {
+			vote = voteEnum(rating.Vote)
+		}
+		item.MyVote = &vote
+	}
+	etag, err := api.ETag(item)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	headers := map[string]string{
+		api.HeaderETag:         etag,
+		api.HeaderCacheControl: "private, no-cache",
+	}
+	if api.NotModified(ctx, etag) {
+		return &api.Response{Status: http.StatusNotModified, Headers: headers}, nil
+	}
+
+	// version files are immutable, so the URL serves the content the ETag was computed for.
+	fileID := item.Dictionary
+	if dict.Version > 0 {
+		fileID = utils.VersionFileID(dict.Id, dict.Version)
+	}
+	url, err := s3Bucket.DownloadURL(ctx, fileID, serviceDictionaryBucket)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to build download url")}
+	}
+	return &api.Response{
+		Headers: headers,
+		Body: openapi.DataResponseDictionary(applingoapi.DictionaryData{
+			Item:      item,
+			Url:       url,
+			ExpiresIn: downloadExpiresIn,
+		}),
+	}, nil
+}
+
+// dictionaryDetails converts the table item to the API representation with the full metadata.
+func dictionaryDetails(dict applingodictionary.SchemaItem) applingoapi.DictionaryItemV1 {
+	item := dictionaryItem(dict)
+	revision := int64(dict.Revision)
+	item.Revision = &revision
+	return item
+}
+
+// canRead reports whether the caller may see the dictionary.
+func canRead(meta api.MetaData, dict applingodictionary.SchemaItem) bool {
+	switch {
+	case applingodictionary.IntToBool(dict.IsPublic):
+		return true
+	case !meta.IsUser():
+		return false
+	default:
+		return meta.HasPermissions(auth.Manager) || isOwner(meta, dict)
+	}
+}
+
+// isOwner reports whether the caller created the dictionary. The author is free text, so dictionaries
+// created before owner ids were stored have no owner until tool-dictionary-backfill sets it.
+func isOwner(meta api.MetaData, dict applingodictionary.SchemaItem) bool {
+	id := meta.GetIdentifier()
+	return id != "" && dict.Owner == id
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	lingo "github.com/Mad-Pixels/applingo-api/lingo-interface/types"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+var errRevisionConflict = errors.New("dictionary was modified concurrently")
+
+func handleDictionaryPatch(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
+	params := applingoapi.PatchDictionaryV1Params{
+		Id:          baseParams.GetStringDefault("id", ""),
+		Subcategory: baseParams.GetStringDefault("subcategory", ""),
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, api.NewValidationError(err)
+	}
+	req := api.MustGetBody[applingoapi.RequestPatchDictionaryV1](ctx)
+	if req.Name == nil && req.Description == nil && req.Level == nil && req.Topic == nil && req.Public == nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Message: "nothing to update", Err: errors.New("empty patch")}
+	}
+
+	key := map[string]types.AttributeValue{
+		applingodictionary.ColumnId:          &types.AttributeValueMemberS{Value: params.Id},
+		applingodictionary.ColumnSubcategory: &types.AttributeValueMemberS{Value: params.Subcategory},
+	}
+	result, err := dbDynamo.Get(ctx, applingodictionary.TableSchema.TableName, key)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to get dictionary")}
+	}
+	if result.Item == nil {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("dictionary not found")}
+	}
+	var dict applingodictionary.SchemaItem
+	if err := attributevalue.UnmarshalMap(result.Item, &dict); err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to unmarshal dictionary")}
+	}
+
+	meta := api.MustGetMetaData(ctx)
+	if !canRead(meta, dict) {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("dictionary is private")}
+	}
+	if !canEdit(meta, dict) {
+		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("only the author or a manager can update the dictionary")}
+	}
+	if int64(dict.Revision) != req.Revision {
+		return nil, revisionConflict(errRevisionConflict)
+	}
+
+	update := applyPatch(&dict, req)
+	if err := dbDynamo.Update(ctx, applingodictionary.TableSchema.TableName, key, update, revisionCondition(req.Revision)); err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil, revisionConflict(err)
+		}
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to update dictionary")}
+	}
+	return openapi.DataResponseDictionaryItem(dictionaryDetails(dict)), nil
+}
+
+// applyPatch applies the changes to the item and returns the update, the topic group and composite keys
+// are always rewritten so they cannot drift from the level, the topic and the public flag.
+func applyPatch(dict *applingodictionary.SchemaItem, req *applingoapi.RequestPatchDictionaryV1) expression.UpdateBuilder {
+	if req.Name != nil {
+		dict.Name = *req.Name
+	}
+	if req.Description != nil {
+		dict.Description = *req.Description
+	}
+	if req.Level != nil {
+		dict.Level = *req.Level
+	}
+	if req.Topic != nil {
+		dict.Topic = *req.Topic
+	}
+	if req.Public != nil {
+		dict.IsPublic = applingodictionary.BoolToInt(*req.Public)
+	}
+	dict.TopicGroup = lingo.TopicGroupOf(dict.Topic).String()
+	dict.FillCompositeKeys()
+	dict.Revision++
+
+	return expression.
+		Set(expression.Name(applingodictionary.ColumnName), expression.Value(dict.Name)).
+		Set(expression.Name(applingodictionary.ColumnDescription), expression.Value(dict.Description)).
+		Set(expression.Name(applingodictionary.ColumnLevel), expression.Value(dict.Level)).
+		Set(expression.Name(applingodictionary.ColumnTopic), expression.Value(dict.Topic)).
+		Set(expression.Name(applingodictionary.ColumnTopicGroup), expression.Value(dict.TopicGroup)).
+		Set(expression.Name(applingodictionary.ColumnIsPublic), expression.Value(dict.IsPublic)).
+		Set(expression.Name(applingodictionary.ColumnLevelIsPublic), expression.Value(dict.LevelIsPublic)).
+		Set(expression.Name(applingodictionary.ColumnSubcategoryIsPublic), expression.Value(dict.SubcategoryIsPublic)).
+		Set(expression.Name(applingodictionary.ColumnLevelSubcategoryIsPublic), expression.Value(dict.LevelSubcategoryIsPublic)).
+		Set(expression.Name(applingodictionary.ColumnTopicIsPublic), expression.Value(dict.TopicIsPublic)).
+		Set(expression.Name(applingodictionary.ColumnTopicGroupIsPublic), expression.Value(dict.TopicGroupIsPublic)).
+		Set(expression.Name(applingodictionary.ColumnRevision), expression.Value(dict.Revision))
+}
+
+// revisionCondition matches the item at the expected revision, items created before revisions are at 0.
+func revisionCondition(revision int64) expression.ConditionBuilder {
+	current := expression.Name(applingodictionary.ColumnRevision).Equal(expression.Value(revision))
+	if revision == 0 {
+		current = expression.Or(expression.AttributeNotExists(expression.Name(applingodictionary.ColumnRevision)), current)
+	}
+	return expression.AttributeExists(expression.Name(applingodictionary.ColumnId)).And(current)
+}
+
+func revisionConflict(err error) *api.HandleError {
+	return &api.HandleError{
+		Status:  http.StatusConflict,
+		Message: "dictionary was modified, reload it and retry",
+		Err:     err,
+	}
+}
+
+// canEdit reports whether the caller may change the dictionary metadata.
+func canEdit(meta api.MetaData, dict applingodictionary.SchemaItem) bool {
+	if !meta.IsUser() {
+		return false
+	}
+	return meta.HasPermissions(auth.Manager) || meta.HasScope(auth.ScopeDictionaryWrite) || isOwner(meta, dict)
+}
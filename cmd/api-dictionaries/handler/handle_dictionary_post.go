@@ -1,19 +1,17 @@
-package main
+package handler
 
 import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	lingo "github.com/Mad-Pixels/applingo-api/lingo-interface/types"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
-	"github.com/Mad-Pixels/applingo-api/pkg/auth"
-	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
 	"github.com/Mad-Pixels/applingo-api/pkg/utils"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
@@ -21,39 +19,26 @@ import (
 	"github.com/rs/zerolog"
 )
 
-func handleDictionaryPost(ctx context.Context, _ zerolog.Logger, body json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
-	if api.MustGetMetaData(ctx).IsDevice() || !api.MustGetMetaData(ctx).HasPermissions(auth.User) {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
-	}
+func handleDictionaryPost(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
+	req := api.MustGetBody[applingoapi.RequestPostDictionaryV1](ctx)
+	meta := api.MustGetMetaData(ctx)
 
-	var req applingoapi.RequestPostDictionaryV1
-	if err := serializer.UnmarshalJSON(body, &req); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
-	}
-	if err := validate.ValidateStruct(&req); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
-	}
-	levelSubcategoryIsPublic := fmt.Sprintf("%s#%s#%d", req.Level, req.Subcategory, applingodictionary.BoolToInt(req.Public))
-	subcategoryIsPublic := fmt.Sprintf("%s#%d", req.Subcategory, applingodictionary.BoolToInt(req.Public))
-	levelIsPublic := fmt.Sprintf("%s#%d", req.Level, applingodictionary.BoolToInt(req.Public))
-
-	item := applingodictionary.SchemaItem{
+	item := applingodictionary.NewSchemaItem(applingodictionary.SchemaItem{
 		Id:          utils.GenerateDictionaryID(req.Name, req.Author),
 		Name:        req.Name,
 		Author:      req.Author,
+		Owner:       meta.GetIdentifier(),
 		Category:    string(req.Category),
 		Subcategory: req.Subcategory,
 		Description: req.Description,
 		IsPublic:    applingodictionary.BoolToInt(req.Public),
 		Level:       req.Level,
+		Topic:       req.Topic,
+		TopicGroup:  lingo.TopicGroupOf(req.Topic).String(),
 		Created:     int(time.Now().Unix()),
 		Rating:      0,
+	})
 
-		// Composite keys
-		LevelSubcategoryIsPublic: levelSubcategoryIsPublic,
-		LevelIsPublic:            levelIsPublic,
-		SubcategoryIsPublic:      subcategoryIsPublic,
-	}
 	dynamoItem, err := applingodictionary.PutItem(item)
 	if err != nil {
 		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
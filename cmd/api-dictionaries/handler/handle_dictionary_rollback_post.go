@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+	"github.com/Mad-Pixels/applingo-api/pkg/versions"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// handleDictionaryRollbackPost publishes the file of an earlier version as a new version,
+// so the rollback itself stays in the history and can be reverted the same way.
+func handleDictionaryRollbackPost(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
+	pathParams := api.GetPathParams(ctx)
+	id := pathParams.GetStringDefault("id", "")
+	if err := validate.ValidateField(id, "required,len=32,hexadecimal"); err != nil {
+		return nil, api.NewParamError("id", err)
+	}
+	version := pathParams.GetIntDefault("version", 0)
+	if err := validate.ValidateField(version, "required,min=1"); err != nil {
+		return nil, api.NewParamError("version", err)
+	}
+	params := applingoapi.PostDictionaryRollbackV1Params{
+		Subcategory: baseParams.GetStringDefault("subcategory", ""),
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, api.NewValidationError(err)
+	}
+	dict, handleErr := versionedDictionary(ctx, id, params.Subcategory)
+	if handleErr != nil {
+		return nil, handleErr
+	}
+	if _, handleErr := getVersion(ctx, id, version); handleErr != nil {
+		return nil, handleErr
+	}
+	if version == dict.Version {
+		return nil, &api.HandleError{Status: http.StatusConflict, Message: "version is already current", Err: errors.New("rollback to the current version")}
+	}
+
+	record, err := versionStore.Publish(
+		ctx,
+		dict,
+		utils.VersionFileID(id, version),
+		serviceDictionaryBucket,
+		api.MustGetMetaData(ctx).GetIdentifier(),
+		version,
+	)
+	if err != nil {
+		if errors.Is(err, versions.ErrConflict) {
+			return nil, &api.HandleError{Status: http.StatusConflict, Err: err}
+		}
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	return &api.Response{
+		Status: http.StatusCreated,
+		Body:   openapi.DataResponseDictionaryRollback(versionItem(record, record.Version)),
+	}, nil
+}
@@ -1,4 +1,4 @@
-package main
+package handler
 
 import (
 	"context"
@@ -9,8 +9,6 @@ import (
 	"github.com/Mad-Pixels/applingo-api/openapi-interface"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
-	"github.com/Mad-Pixels/applingo-api/pkg/auth"
-	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
 	"github.com/Mad-Pixels/applingo-api/pkg/utils"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
@@ -19,38 +17,56 @@ import (
 	"github.com/rs/zerolog"
 )
 
-func handleDictionaryStatisticPatch(ctx context.Context, _ zerolog.Logger, rawMessage json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
-	if !api.MustGetMetaData(ctx).HasPermissions(auth.Device) {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
-	}
-
+// handleDictionaryStatisticPatch is kept for clients which predate votes. Rating changes are recorded
+// as the caller's vote and downloads are counted once per voter, so repeated calls no longer inflate the counters.
+// Clients with the shared token have no identity to vote with, they still change the counters directly.
+func handleDictionaryStatisticPatch(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
 	params := applingoapi.PatchDictionaryStatisticV1Params{
 		Name:        baseParams.GetStringDefault("name", ""),
 		Author:      baseParams.GetStringDefault("author", ""),
 		Subcategory: baseParams.GetStringDefault("subcategory", ""),
 	}
 	if err := validate.ValidateStruct(&params); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
+		return nil, api.NewValidationError(err)
 	}
 
-	var req applingoapi.RequestPatchDictionaryStatisticV1
-	if err := serializer.UnmarshalJSON(rawMessage, &req); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
-	}
-	if err := validate.ValidateStruct(&req); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
-	}
+	req := api.MustGetBody[applingoapi.RequestPatchDictionaryStatisticV1](ctx)
 
 	if req.Downloads == applingoapi.NoChange && req.Rating == applingoapi.NoChange {
 		return openapi.DataResponseSuccess, nil
 	}
+	var (
+		change     ratingChange
+		downloaded = req.Downloads == applingoapi.Increase
+		vote       = applingoapi.Like
+	)
+	if req.Downloads != applingoapi.NoChange {
+		change.downloaded = &downloaded
+	}
+	if req.Rating == applingoapi.Decrease {
+		vote = applingoapi.Dislike
+	}
+	if req.Rating != applingoapi.NoChange {
+		change.vote = &vote
+	}
 
 	id := utils.GenerateDictionaryID(params.Name, params.Author)
 	key := map[string]types.AttributeValue{
 		applingodictionary.ColumnId:          &types.AttributeValueMemberS{Value: id},
 		applingodictionary.ColumnSubcategory: &types.AttributeValueMemberS{Value: params.Subcategory},
 	}
+	voterKey, err := voter(api.MustGetMetaData(ctx))
+	if err != nil {
+		return nil, updateStatistic(ctx, key, req)
+	}
+	if _, _, err := applyRating(ctx, key, id, voterKey, change); err != nil {
+		return nil, ratingError(err)
+	}
+	return nil, nil
+}
 
+// updateStatistic changes the counters of the dictionary directly, as before votes were recorded.
+func updateStatistic(ctx context.Context, key map[string]types.AttributeValue, req *applingoapi.RequestPatchDictionaryStatisticV1) *api.HandleError {
 	updateBuilder := expression.UpdateBuilder{}
 
 	switch req.Downloads {
@@ -62,18 +78,18 @@ func handleDictionaryStatisticPatch(ctx context.Context, _ zerolog.Logger, rawMe
 
 	switch req.Rating {
 	case applingoapi.Increase:
-		updateBuilder = updateBuilder.Add(expression.Name(applingodictionary.ColumnRating), expression.Value(2))
+		updateBuilder = updateBuilder.Add(expression.Name(applingodictionary.ColumnRating), expression.Value(likeWeight))
 	case applingoapi.Decrease:
-		updateBuilder = updateBuilder.Add(expression.Name(applingodictionary.ColumnRating), expression.Value(-1))
+		updateBuilder = updateBuilder.Add(expression.Name(applingodictionary.ColumnRating), expression.Value(dislikeWeight))
 	}
 
 	condition := expression.AttributeExists(expression.Name(applingodictionary.ColumnId))
 	if err := dbDynamo.Update(ctx, applingodictionary.TableName, key, updateBuilder, condition); err != nil {
 		var conditionErr *types.ConditionalCheckFailedException
 		if errors.As(err, &conditionErr) {
-			return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item not found")}
+			return &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item not found")}
 		}
-		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to update item")}
+		return &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to update item")}
 	}
-	return nil, nil
+	return nil
 }
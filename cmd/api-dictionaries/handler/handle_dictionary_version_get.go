@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+func handleDictionaryVersionGet(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
+	pathParams := api.GetPathParams(ctx)
+	id := pathParams.GetStringDefault("id", "")
+	if err := validate.ValidateField(id, "required,len=32,hexadecimal"); err != nil {
+		return nil, api.NewParamError("id", err)
+	}
+	version := pathParams.GetIntDefault("version", 0)
+	if err := validate.ValidateField(version, "required,min=1"); err != nil {
+		return nil, api.NewParamError("version", err)
+	}
+	params := applingoapi.GetDictionaryVersionV1Params{
+		Subcategory: baseParams.GetStringDefault("subcategory", ""),
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, api.NewValidationError(err)
+	}
+	dict, handleErr := versionedDictionary(ctx, id, params.Subcategory)
+	if handleErr != nil {
+		return nil, handleErr
+	}
+	record, handleErr := getVersion(ctx, id, version)
+	if handleErr != nil {
+		return nil, handleErr
+	}
+
+	url, err := s3Bucket.DownloadURL(ctx, utils.VersionFileID(id, version), serviceDictionaryBucket)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to build download url")}
+	}
+	return openapi.DataResponseDictionaryVersion(applingoapi.DictionaryVersionData{
+		Item:      versionItem(record, dict.Version),
+		Url:       url,
+		ExpiresIn: downloadExpiresIn,
+	}), nil
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoversion"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/rs/zerolog"
+)
+
+func handleDictionaryVersionsGet(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
+	id := api.GetPathParams(ctx).GetStringDefault("id", "")
+	if err := validate.ValidateField(id, "required,len=32,hexadecimal"); err != nil {
+		return nil, api.NewParamError("id", err)
+	}
+	params := applingoapi.GetDictionaryVersionsV1Params{
+		Subcategory:   baseParams.GetStringDefault("subcategory", ""),
+		LastEvaluated: baseParams.GetStringPtr("last_evaluated"),
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, api.NewValidationError(err)
+	}
+	dict, handleErr := versionedDictionary(ctx, id, params.Subcategory)
+	if handleErr != nil {
+		return nil, handleErr
+	}
+
+	qb := applingoversion.NewQueryBuilder().
+		WithDictionaryId(id).
+		Limit(pageLimit)
+	if params.LastEvaluated != nil {
+		lastEvaluatedKey, err := decodeLastEvaluated(*params.LastEvaluated)
+		if err != nil {
+			return nil, api.NewParamError("last_evaluated", err)
+		}
+		qb.StartFrom(lastEvaluatedKey)
+	}
+	indexName, keyCondition, _, exclusiveStartKey, err := qb.Build()
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	dynamoQueryInput, err := dbDynamo.BuildQueryInput(cloud.QueryInput{
+		IndexName:         indexName,
+		KeyCondition:      keyCondition,
+		Limit:             pageLimit,
+		ScanForward:       false,
+		ExclusiveStartKey: exclusiveStartKey,
+	})
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	result, err := dbDynamo.Query(ctx, applingoversion.TableSchema.TableName, dynamoQueryInput)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	response := applingoapi.DictionaryVersionsData{
+		Items: make([]applingoapi.DictionaryVersionItemV1, 0, len(result.Items)),
+	}
+	for _, item := range result.Items {
+		var record applingoversion.SchemaItem
+		if err := attributevalue.UnmarshalMap(item, &record); err != nil {
+			logger.Warn().Err(err).Msg("Failed to unmarshal DynamoDB item")
+			continue
+		}
+		response.Items = append(response.Items, versionItem(record, dict.Version))
+	}
+	if response.LastEvaluated, err = encodeLastEvaluated(result.LastEvaluatedKey); err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	return openapi.DataResponseDictionaryVersions(response), nil
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+func handleDictionaryVotePut(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
+	id := api.GetPathParams(ctx).GetStringDefault("id", "")
+	if err := validate.ValidateField(id, "required,len=32,hexadecimal"); err != nil {
+		return nil, api.NewParamError("id", err)
+	}
+	params := applingoapi.PutDictionaryVoteV1Params{
+		Subcategory: baseParams.GetStringDefault("subcategory", ""),
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, api.NewValidationError(err)
+	}
+	req := api.MustGetBody[applingoapi.RequestPutDictionaryVoteV1](ctx)
+
+	meta := api.MustGetMetaData(ctx)
+	voterKey, err := voter(meta)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusForbidden, Err: err}
+	}
+
+	key := map[string]types.AttributeValue{
+		applingodictionary.ColumnId:          &types.AttributeValueMemberS{Value: id},
+		applingodictionary.ColumnSubcategory: &types.AttributeValueMemberS{Value: params.Subcategory},
+	}
+	result, err := dbDynamo.Get(ctx, applingodictionary.TableSchema.TableName, key)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to get dictionary")}
+	}
+	if result.Item == nil {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errDictionaryNotFound}
+	}
+	var dict applingodictionary.SchemaItem
+	if err := attributevalue.UnmarshalMap(result.Item, &dict); err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to unmarshal dictionary")}
+	}
+	if !canRead(meta, dict) {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("dictionary is private")}
+	}
+
+	rating, delta, err := applyRating(ctx, key, id, voterKey, ratingChange{vote: &req.Vote})
+	if err != nil {
+		return nil, ratingError(err)
+	}
+
+	// the counters are adjusted atomically, the response reflects the caller's change only.
+	dict.Rating += delta
+	item := dictionaryDetails(dict)
+	vote := voteEnum(rating.Vote)
+	item.MyVote = &vote
+	return openapi.DataResponseDictionaryItem(item), nil
+}
+
+// ratingError maps errors of applyRating to API errors.
+func ratingError(err error) *api.HandleError {
+	switch {
+	case errors.Is(err, errDictionaryNotFound):
+		return &api.HandleError{Status: http.StatusNotFound, Err: err}
+	case errors.Is(err, errVoteConflict):
+		return &api.HandleError{Status: http.StatusConflict, Err: err}
+	default:
+		return &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+}
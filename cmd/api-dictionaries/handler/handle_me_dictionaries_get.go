@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+func handleMeDictionariesGet(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
+	params := applingoapi.GetMyDictionariesV1Params{
+		LastEvaluated: baseParams.GetStringPtr("last_evaluated"),
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, api.NewValidationError(err)
+	}
+	owner := api.MustGetMetaData(ctx).GetIdentifier()
+	if owner == "" {
+		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("user identifier is missing")}
+	}
+
+	qb := applingodictionary.NewQueryBuilder().
+		WithOwner(owner).
+		Limit(pageLimit)
+	if params.LastEvaluated != nil {
+		lastEvaluatedKey, err := decodeLastEvaluated(*params.LastEvaluated)
+		if err != nil {
+			return nil, api.NewParamError("last_evaluated", err)
+		}
+		qb.StartFrom(lastEvaluatedKey)
+	}
+	indexName, keyCondition, _, exclusiveStartKey, err := qb.Build()
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	dynamoQueryInput, err := dbDynamo.BuildQueryInput(cloud.QueryInput{
+		IndexName:         indexName,
+		KeyCondition:      keyCondition,
+		Limit:             pageLimit,
+		ScanForward:       false,
+		ExclusiveStartKey: exclusiveStartKey,
+	})
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	result, err := dbDynamo.Query(ctx, applingodictionary.TableSchema.TableName, dynamoQueryInput)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	response := applingoapi.DictionariesData{
+		Items: make([]applingoapi.DictionaryItemV1, 0, len(result.Items)),
+	}
+	for _, item := range result.Items {
+		var dict applingodictionary.SchemaItem
+		if err := attributevalue.UnmarshalMap(item, &dict); err != nil {
+			logger.Warn().Err(err).Msg("Failed to unmarshal DynamoDB item")
+			continue
+		}
+		// the owner edits these items, so the revision for PATCH is included.
+		response.Items = append(response.Items, dictionaryDetails(dict))
+	}
+	if response.LastEvaluated, err = encodeLastEvaluated(result.LastEvaluatedKey); err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	return openapi.DataResponseDictionaries(response), nil
+}
@@ -0,0 +1,74 @@
+// Package handler implements the dictionaries API routes.
+// It is served by the api-dictionaries Lambda and by the local server.
+package handler
+
+import (
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+	"github.com/Mad-Pixels/applingo-api/pkg/versions"
+)
+
+var (
+	validate = validator.New()
+	dbDynamo cloud.DynamoAPI
+
+	s3Bucket                cloud.BucketAPI
+	serviceDictionaryBucket string
+	versionStore            *versions.Store
+)
+
+// Config holds the handler dependencies.
+type Config struct {
+	Dynamo           cloud.DynamoAPI
+	Bucket           cloud.BucketAPI
+	DictionaryBucket string
+}
+
+// Routes sets up the handler dependencies and returns the routes map.
+func Routes(cfg Config) map[string]api.HandleFunc {
+	dbDynamo = cfg.Dynamo
+	s3Bucket = cfg.Bucket
+	serviceDictionaryBucket = cfg.DictionaryBucket
+	versionStore = versions.NewStore(dbDynamo, s3Bucket, serviceDictionaryBucket)
+
+	return map[string]api.HandleFunc{
+		// list
+		"GET:/v1/dictionaries":        api.Chain(handleDictionariesGet, api.WithPermissions(auth.Device)),
+		"GET:/v1/dictionaries/search": api.Chain(handleDictionariesSearch, api.WithPermissions(auth.Device)),
+		"GET:/v1/me/dictionaries":     api.Chain(handleMeDictionariesGet, api.WithUser(auth.User)),
+
+		// item
+		"GET:/v1/dictionaries/{id}": api.Chain(handleDictionaryGet, api.WithPermissions(auth.Device)),
+		"POST:/v1/dictionary": api.Chain(
+			handleDictionaryPost,
+			api.WithUser(auth.User),
+			api.WithJSONBody[applingoapi.RequestPostDictionaryV1](validate),
+		),
+		"PATCH:/v1/dictionary": api.Chain(
+			handleDictionaryPatch,
+			api.WithUser(auth.User),
+			api.WithJSONBody[applingoapi.RequestPatchDictionaryV1](validate),
+		),
+		"DELETE:/v1/dictionary": api.Chain(handleDictionaryDelete, api.WithUser(auth.User)),
+		"PUT:/v1/dictionaries/{id}/vote": api.Chain(
+			handleDictionaryVotePut,
+			api.WithPermissions(auth.Device),
+			api.WithJSONBody[applingoapi.RequestPutDictionaryVoteV1](validate),
+		),
+
+		// versions
+		"GET:/v1/dictionaries/{id}/versions":                     api.Chain(handleDictionaryVersionsGet, api.WithUser(auth.User)),
+		"GET:/v1/dictionaries/{id}/versions/{version}":           api.Chain(handleDictionaryVersionGet, api.WithUser(auth.User)),
+		"POST:/v1/dictionaries/{id}/versions/{version}/rollback": api.Chain(handleDictionaryRollbackPost, api.WithUser(auth.Manager)),
+
+		// specific
+		"PATCH:/v1/dictionary/statistic": api.Chain(
+			handleDictionaryStatisticPatch,
+			api.WithPermissions(auth.Device),
+			api.WithJSONBody[applingoapi.RequestPatchDictionaryStatisticV1](validate),
+		),
+	}
+}
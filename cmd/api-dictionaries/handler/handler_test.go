@@ -0,0 +1,725 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingorating"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingosearch"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoversion"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/search"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+	"github.com/Mad-Pixels/applingo-api/pkg/versions"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPI(t *testing.T) (*api.API, *cloud.MemoryDynamo) {
+	t.Helper()
+
+	db := cloud.NewMemoryDynamo(
+		cloud.NewMemoryTable(applingodictionary.TableSchema),
+		cloud.NewMemoryTable(applingosearch.TableSchema),
+		cloud.NewMemoryTable(applingorating.TableSchema),
+		cloud.NewMemoryTable(applingoversion.TableSchema),
+	)
+	return api.NewLambda(api.Config{}, Routes(Config{
+		Dynamo:           db,
+		Bucket:           cloud.NewLocalBucket(t.TempDir(), "http://localhost/_bucket"),
+		DictionaryBucket: "dictionary",
+	})), db
+}
+
+func request(method, path string, kind auth.Kind, role auth.Role, query map[string]string, body string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		HTTPMethod:            method,
+		Path:                  path,
+		QueryStringParameters: query,
+		Body:                  body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			HTTPMethod: method,
+			Authorizer: map[string]any{
+				"kind": strconv.Itoa(int(kind)),
+				"role": strconv.Itoa(int(role)),
+			},
+		},
+	}
+}
+
+func postDictionary(t *testing.T, a *api.API, name, level string) events.APIGatewayProxyResponse {
+	t.Helper()
+	return postTopicDictionary(t, a, name, level, "travel")
+}
+
+func postTopicDictionary(t *testing.T, a *api.API, name, level, topic string) events.APIGatewayProxyResponse {
+	t.Helper()
+
+	body, err := serializer.MarshalJSON(applingoapi.RequestPostDictionaryV1{
+		Author:      "author",
+		Category:    applingoapi.Language,
+		Description: "description",
+		Filename:    utils.RecordToFileID(utils.GenerateDictionaryID(name, "author")),
+		Level:       level,
+		Name:        name,
+		Public:      true,
+		Subcategory: "en-ru",
+		Topic:       topic,
+	})
+	require.NoError(t, err)
+
+	req := request(http.MethodPost, "/v1/dictionary", auth.JWT, auth.User, nil, string(body))
+	req.RequestContext.Authorizer["identifier"] = "author"
+
+	resp, err := a.Handle(context.Background(), req)
+	require.NoError(t, err)
+	return resp
+}
+
+func listDictionaries(t *testing.T, a *api.API, query map[string]string) applingoapi.DictionariesData {
+	t.Helper()
+
+	resp, err := a.Handle(context.Background(), request(http.MethodGet, "/v1/dictionaries", auth.HMAC, auth.Device, query, ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode, resp.Body)
+
+	var out struct {
+		Data applingoapi.DictionariesData `json:"data"`
+	}
+	require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	return out.Data
+}
+
+func TestDictionaryPostAndList(t *testing.T) {
+	a, _ := newTestAPI(t)
+
+	require.Equal(t, http.StatusCreated, postDictionary(t, a, "first dictionary", "A1").StatusCode)
+	require.Equal(t, http.StatusCreated, postDictionary(t, a, "second dictionary", "B2").StatusCode)
+	assert.Equal(t, http.StatusConflict, postDictionary(t, a, "first dictionary", "A1").StatusCode)
+
+	all := listDictionaries(t, a, map[string]string{"sort_by": "date"})
+	assert.Len(t, all.Items, 2)
+	assert.Nil(t, all.LastEvaluated)
+
+	byLevel := listDictionaries(t, a, map[string]string{"level": "B2", "sort_by": "rating"})
+	require.Len(t, byLevel.Items, 1)
+	assert.Equal(t, "second dictionary", byLevel.Items[0].Name)
+	assert.Equal(t, utils.RecordToFileID(byLevel.Items[0].Id), byLevel.Items[0].Dictionary)
+}
+
+func TestDictionaryListByTopic(t *testing.T) {
+	a, _ := newTestAPI(t)
+
+	require.Equal(t, http.StatusCreated, postTopicDictionary(t, a, "hotel basics", "A1", "Hotel and Accommodation").StatusCode)
+	require.Equal(t, http.StatusCreated, postTopicDictionary(t, a, "hotel advanced", "B1", "Hotel and Accommodation").StatusCode)
+	require.Equal(t, http.StatusCreated, postTopicDictionary(t, a, "insurance claims", "A1", "Insurance and Claims").StatusCode)
+	require.Equal(t, http.StatusCreated, postTopicDictionary(t, a, "my words", "A1", "my own topic").StatusCode)
+
+	tests := []struct {
+		name  string
+		query map[string]string
+		want  []string
+	}{
+		{"topic", map[string]string{"topic": "Hotel and Accommodation"}, []string{"hotel basics", "hotel advanced"}},
+		{"topic and level", map[string]string{"topic": "Hotel and Accommodation", "level": "A1"}, []string{"hotel basics"}},
+		{"group", map[string]string{"topic_group": "Travel & Transportation"}, []string{"hotel basics", "hotel advanced"}},
+		{
+			"group level and subcategory",
+			map[string]string{"topic_group": "Work & Business", "level": "A1", "subcategory": "en-ru", "sort_by": "rating"},
+			[]string{"insurance claims"},
+		},
+		{"unknown topics", map[string]string{"topic_group": "Other"}, []string{"my words"}},
+		{"topic outside group", map[string]string{"topic": "Insurance and Claims", "topic_group": "Other"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := map[string]string{"sort_by": "date"}
+			for k, v := range tt.query {
+				query[k] = v
+			}
+			data := listDictionaries(t, a, query)
+
+			got := make([]string, 0, len(data.Items))
+			for _, item := range data.Items {
+				got = append(got, item.Name)
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+
+	data := listDictionaries(t, a, map[string]string{"topic": "Insurance and Claims", "sort_by": "date"})
+	require.Len(t, data.Items, 1)
+	require.NotNil(t, data.Items[0].TopicGroup)
+	assert.Equal(t, applingoapi.WorkBusiness, *data.Items[0].TopicGroup)
+
+	resp, err := a.Handle(context.Background(), request(
+		http.MethodGet, "/v1/dictionaries", auth.HMAC, auth.Device, map[string]string{"topic_group": "Sports", "sort_by": "date"}, "",
+	))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func postOwnedDictionary(t *testing.T, a *api.API, name, owner string, public bool) {
+	t.Helper()
+
+	body, err := serializer.MarshalJSON(applingoapi.RequestPostDictionaryV1{
+		Author:      "author",
+		Category:    applingoapi.Language,
+		Description: "description",
+		Filename:    utils.RecordToFileID(utils.GenerateDictionaryID(name, "author")),
+		Level:       "A1",
+		Name:        name,
+		Public:      public,
+		Subcategory: "en-ru",
+		Topic:       "travel",
+	})
+	require.NoError(t, err)
+
+	req := request(http.MethodPost, "/v1/dictionary", auth.JWT, auth.User, nil, string(body))
+	req.RequestContext.Authorizer["identifier"] = owner
+	resp, err := a.Handle(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, resp.Body)
+}
+
+func TestMyDictionaries(t *testing.T) {
+	a, _ := newTestAPI(t)
+	postOwnedDictionary(t, a, "my public words", "user-1", true)
+	postOwnedDictionary(t, a, "my private words", "user-1", false)
+	postOwnedDictionary(t, a, "foreign words", "user-2", false)
+	require.Equal(t, http.StatusCreated, postDictionary(t, a, "scheduled words", "A1").StatusCode)
+
+	list := func(kind auth.Kind, role auth.Role, identifier string) (int, applingoapi.DictionariesData) {
+		req := request(http.MethodGet, "/v1/me/dictionaries", kind, role, nil, "")
+		req.RequestContext.Authorizer["identifier"] = identifier
+		resp, err := a.Handle(context.Background(), req)
+		require.NoError(t, err)
+
+		var out struct {
+			Data applingoapi.DictionariesData `json:"data"`
+		}
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+		}
+		return resp.StatusCode, out.Data
+	}
+
+	status, mine := list(auth.JWT, auth.User, "user-1")
+	require.Equal(t, http.StatusOK, status)
+	assert.ElementsMatch(t, []string{"my public words", "my private words"}, names(mine.Items))
+	assert.Nil(t, mine.LastEvaluated)
+	for _, item := range mine.Items {
+		assert.NotNil(t, item.Revision)
+	}
+
+	status, _ = list(auth.HMAC, auth.Device, "device-1")
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// the owner reads private dictionaries by the owner id, whatever the author says.
+	id := utils.GenerateDictionaryID("my private words", "author")
+	assert.Equal(t, http.StatusOK, getDictionary(t, a, id, auth.JWT, auth.User, "user-1", nil).StatusCode)
+	assert.Equal(t, http.StatusNotFound, getDictionary(t, a, id, auth.JWT, auth.User, "user-2", nil).StatusCode)
+	assert.Equal(t, http.StatusNotFound, getDictionary(t, a, id, auth.JWT, auth.User, "author", nil).StatusCode)
+}
+
+func TestDictionaryPostRequiresUser(t *testing.T) {
+	a, _ := newTestAPI(t)
+
+	resp, err := a.Handle(context.Background(), request(http.MethodPost, "/v1/dictionary", auth.HMAC, auth.Device, nil, "{}"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func patchStatistic(t *testing.T, a *api.API, name, identifier, body string) events.APIGatewayProxyResponse {
+	t.Helper()
+
+	query := map[string]string{"name": name, "author": "author", "subcategory": "en-ru"}
+	req := request(http.MethodPatch, "/v1/dictionary/statistic", auth.HMAC, auth.Device, query, body)
+	req.RequestContext.Authorizer["identifier"] = identifier
+
+	resp, err := a.Handle(context.Background(), req)
+	require.NoError(t, err)
+	return resp
+}
+
+func dictionaryCounters(t *testing.T, db *cloud.MemoryDynamo, name string) (rating, downloads int) {
+	t.Helper()
+
+	out, err := db.Get(context.Background(), applingodictionary.TableName, map[string]types.AttributeValue{
+		applingodictionary.ColumnId:          &types.AttributeValueMemberS{Value: utils.GenerateDictionaryID(name, "author")},
+		applingodictionary.ColumnSubcategory: &types.AttributeValueMemberS{Value: "en-ru"},
+	})
+	require.NoError(t, err)
+	var dict applingodictionary.SchemaItem
+	require.NoError(t, attributevalue.UnmarshalMap(out.Item, &dict))
+	return dict.Rating, dict.Downloads
+}
+
+func TestDictionaryStatisticPatch(t *testing.T) {
+	a, db := newTestAPI(t)
+	require.Equal(t, http.StatusCreated, postDictionary(t, a, "first dictionary", "A1").StatusCode)
+
+	resp := patchStatistic(t, a, "first dictionary", "device-1", `{"downloads":"increase","rating":"increase"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode, resp.Body)
+	rating, downloads := dictionaryCounters(t, db, "first dictionary")
+	assert.Equal(t, 2, rating)
+	assert.Equal(t, 1, downloads)
+
+	// repeated calls of the same device are counted once, a changed vote replaces the previous one.
+	require.Equal(t, http.StatusOK, patchStatistic(t, a, "first dictionary", "device-1", `{"downloads":"increase","rating":"increase"}`).StatusCode)
+	require.Equal(t, http.StatusOK, patchStatistic(t, a, "first dictionary", "device-1", `{"downloads":"no_change","rating":"decrease"}`).StatusCode)
+	require.Equal(t, http.StatusOK, patchStatistic(t, a, "first dictionary", "device-2", `{"downloads":"increase","rating":"increase"}`).StatusCode)
+	rating, downloads = dictionaryCounters(t, db, "first dictionary")
+	assert.Equal(t, 1, rating)
+	assert.Equal(t, 2, downloads)
+
+	assert.Equal(t, http.StatusNotFound, patchStatistic(t, a, "missing dictionary", "device-1", `{"downloads":"increase","rating":"no_change"}`).StatusCode)
+
+	// clients with the shared token have no identity and change the counters directly.
+	for range 2 {
+		require.Equal(t, http.StatusOK, patchStatistic(t, a, "first dictionary", "", `{"downloads":"increase","rating":"increase"}`).StatusCode)
+	}
+	rating, downloads = dictionaryCounters(t, db, "first dictionary")
+	assert.Equal(t, 5, rating)
+	assert.Equal(t, 4, downloads)
+	assert.Equal(t, http.StatusNotFound, patchStatistic(t, a, "missing dictionary", "", `{"downloads":"increase","rating":"no_change"}`).StatusCode)
+}
+
+func putVote(t *testing.T, a *api.API, id string, kind auth.Kind, role auth.Role, identifier, vote string) events.APIGatewayProxyResponse {
+	t.Helper()
+
+	req := request(http.MethodPut, "/v1/dictionaries/"+id+"/vote", kind, role, map[string]string{"subcategory": "en-ru"}, `{"vote":"`+vote+`"}`)
+	req.RequestContext.Authorizer["identifier"] = identifier
+
+	resp, err := a.Handle(context.Background(), req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestDictionaryVote(t *testing.T) {
+	a, db := newTestAPI(t)
+	require.Equal(t, http.StatusCreated, postDictionary(t, a, "first dictionary", "A1").StatusCode)
+	id := utils.GenerateDictionaryID("first dictionary", "author")
+
+	resp := putVote(t, a, id, auth.HMAC, auth.Device, "device-1", "like")
+	require.Equal(t, http.StatusOK, resp.StatusCode, resp.Body)
+	var out applingoapi.ResponsePatchDictionaryV1
+	require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	assert.Equal(t, int32(2), out.Data.Rating)
+	require.NotNil(t, out.Data.MyVote)
+	assert.Equal(t, applingoapi.Like, *out.Data.MyVote)
+
+	// the device and a token issued to its profile are the same voter.
+	require.Equal(t, http.StatusOK, putVote(t, a, id, auth.HMAC, auth.Device, "profile-1", "like").StatusCode)
+	require.Equal(t, http.StatusOK, putVote(t, a, id, auth.JWT, auth.User, "profile-1", "dislike").StatusCode)
+	rating, _ := dictionaryCounters(t, db, "first dictionary")
+	assert.Equal(t, 1, rating)
+
+	var got applingoapi.ResponseGetDictionaryV1
+	resp = getDictionary(t, a, id, auth.HMAC, auth.Device, "profile-1", nil)
+	require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &got))
+	require.NotNil(t, got.Data.Item.MyVote)
+	assert.Equal(t, applingoapi.Dislike, *got.Data.Item.MyVote)
+
+	require.Equal(t, http.StatusOK, putVote(t, a, id, auth.HMAC, auth.Device, "profile-1", "none").StatusCode)
+	rating, _ = dictionaryCounters(t, db, "first dictionary")
+	assert.Equal(t, 2, rating)
+	var anonymous applingoapi.ResponseGetDictionaryV1
+	resp = getDictionary(t, a, id, auth.HMAC, auth.Device, "", nil)
+	require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &anonymous))
+	assert.Nil(t, anonymous.Data.Item.MyVote)
+
+	assert.Equal(t, http.StatusForbidden, putVote(t, a, id, auth.HMAC, auth.Device, "", "like").StatusCode)
+	assert.Equal(t, http.StatusBadRequest, putVote(t, a, id, auth.HMAC, auth.Device, "device-1", "love").StatusCode)
+	assert.Equal(t, http.StatusNotFound, putVote(t, a, utils.GenerateDictionaryID("missing", "author"), auth.HMAC, auth.Device, "device-1", "like").StatusCode)
+}
+
+func TestDictionaryDelete(t *testing.T) {
+	a, _ := newTestAPI(t)
+	require.Equal(t, http.StatusCreated, postDictionary(t, a, "first dictionary", "A1").StatusCode)
+
+	query := map[string]string{"name": "first dictionary", "author": "author", "subcategory": "en-ru"}
+	resp, err := a.Handle(context.Background(), request(http.MethodDelete, "/v1/dictionary", auth.JWT, auth.User, query, ""))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, listDictionaries(t, a, map[string]string{"sort_by": "date"}).Items)
+
+	resp, err = a.Handle(context.Background(), request(http.MethodDelete, "/v1/dictionary", auth.JWT, auth.User, query, ""))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func getDictionary(t *testing.T, a *api.API, id string, kind auth.Kind, role auth.Role, identifier string, headers map[string]string) events.APIGatewayProxyResponse {
+	t.Helper()
+
+	req := request(http.MethodGet, "/v1/dictionaries/"+id, kind, role, map[string]string{"subcategory": "en-ru"}, "")
+	req.RequestContext.Authorizer["identifier"] = identifier
+	req.Headers = headers
+
+	resp, err := a.Handle(context.Background(), req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestDictionaryGet(t *testing.T) {
+	a, db := newTestAPI(t)
+	require.Equal(t, http.StatusCreated, postDictionary(t, a, "first dictionary", "A1").StatusCode)
+	id := utils.GenerateDictionaryID("first dictionary", "author")
+
+	resp := getDictionary(t, a, id, auth.HMAC, auth.Device, "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, resp.Body)
+	etag := resp.Headers[api.HeaderETag]
+	require.NotEmpty(t, etag)
+
+	var out applingoapi.ResponseGetDictionaryV1
+	require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	assert.Equal(t, "first dictionary", out.Data.Item.Name)
+	assert.Equal(t, "http://localhost/_bucket/dictionary/"+utils.RecordToFileID(id), out.Data.Url)
+
+	resp = getDictionary(t, a, id, auth.HMAC, auth.Device, "", map[string]string{"if-none-match": etag})
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Empty(t, resp.Body)
+	assert.Equal(t, etag, resp.Headers[api.HeaderETag])
+
+	// a statistic change invalidates the cached copy.
+	require.Equal(t, http.StatusOK, patchStatistic(t, a, "first dictionary", "device-1", `{"downloads":"increase","rating":"no_change"}`).StatusCode)
+	resp = getDictionary(t, a, id, auth.HMAC, auth.Device, "", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEqual(t, etag, resp.Headers[api.HeaderETag])
+
+	assert.Equal(t, http.StatusNotFound, getDictionary(t, a, utils.GenerateDictionaryID("missing", "author"), auth.HMAC, auth.Device, "", nil).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, getDictionary(t, a, "not-an-id", auth.HMAC, auth.Device, "", nil).StatusCode)
+
+	item, err := applingodictionary.PutItem(applingodictionary.SchemaItem{
+		Id:          utils.GenerateDictionaryID("private dictionary", "owner"),
+		Name:        "private dictionary",
+		Author:      "owner",
+		Owner:       "owner-1",
+		Subcategory: "en-ru",
+		IsPublic:    applingodictionary.BoolToInt(false),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Put(context.Background(), applingodictionary.TableName, item, expression.ConditionBuilder{}))
+
+	privateID := utils.GenerateDictionaryID("private dictionary", "owner")
+	assert.Equal(t, http.StatusNotFound, getDictionary(t, a, privateID, auth.HMAC, auth.Device, "", nil).StatusCode)
+	assert.Equal(t, http.StatusNotFound, getDictionary(t, a, privateID, auth.JWT, auth.User, "someone", nil).StatusCode)
+	assert.Equal(t, http.StatusNotFound, getDictionary(t, a, privateID, auth.JWT, auth.User, "owner", nil).StatusCode)
+	assert.Equal(t, http.StatusOK, getDictionary(t, a, privateID, auth.JWT, auth.User, "owner-1", nil).StatusCode)
+	assert.Equal(t, http.StatusOK, getDictionary(t, a, privateID, auth.JWT, auth.Admin, "admin", nil).StatusCode)
+
+	// private dictionaries without an owner are not matched by the free text author.
+	item, err = applingodictionary.PutItem(applingodictionary.SchemaItem{
+		Id:          utils.GenerateDictionaryID("legacy dictionary", "owner"),
+		Name:        "legacy dictionary",
+		Author:      "owner",
+		Subcategory: "en-ru",
+		IsPublic:    applingodictionary.BoolToInt(false),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Put(context.Background(), applingodictionary.TableName, item, expression.ConditionBuilder{}))
+
+	legacyID := utils.GenerateDictionaryID("legacy dictionary", "owner")
+	assert.Equal(t, http.StatusNotFound, getDictionary(t, a, legacyID, auth.JWT, auth.User, "owner", nil).StatusCode)
+	assert.Equal(t, http.StatusOK, getDictionary(t, a, legacyID, auth.JWT, auth.Manager, "manager", nil).StatusCode)
+	assert.Equal(t, http.StatusNotFound, patchDictionary(t, a, legacyID, "owner", auth.User, `{"revision":0,"level":"B1"}`).StatusCode)
+}
+
+func patchDictionary(t *testing.T, a *api.API, id, identifier string, role auth.Role, body string) events.APIGatewayProxyResponse {
+	t.Helper()
+
+	req := request(http.MethodPatch, "/v1/dictionary", auth.JWT, role, map[string]string{"id": id, "subcategory": "en-ru"}, body)
+	req.RequestContext.Authorizer["identifier"] = identifier
+
+	resp, err := a.Handle(context.Background(), req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestDictionaryPatch(t *testing.T) {
+	a, db := newTestAPI(t)
+	require.Equal(t, http.StatusCreated, postDictionary(t, a, "first dictionary", "A1").StatusCode)
+	id := utils.GenerateDictionaryID("first dictionary", "author")
+
+	assert.Equal(t, http.StatusForbidden, patchDictionary(t, a, id, "someone", auth.User, `{"revision":0,"level":"B1"}`).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, patchDictionary(t, a, id, "author", auth.User, `{"revision":0}`).StatusCode)
+	assert.Equal(t, http.StatusNotFound, patchDictionary(t, a, utils.GenerateDictionaryID("missing", "author"), "author", auth.User, `{"revision":0,"level":"B1"}`).StatusCode)
+
+	resp := patchDictionary(t, a, id, "author", auth.User, `{"revision":0,"level":"B1","public":false,"name":"renamed dictionary"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode, resp.Body)
+
+	var out applingoapi.ResponsePatchDictionaryV1
+	require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	assert.Equal(t, "renamed dictionary", out.Data.Name)
+	assert.Equal(t, "B1", out.Data.Level)
+	assert.False(t, out.Data.Public)
+	require.NotNil(t, out.Data.Revision)
+	assert.Equal(t, int64(1), *out.Data.Revision)
+
+	item, err := db.Get(context.Background(), applingodictionary.TableName, map[string]types.AttributeValue{
+		applingodictionary.ColumnId:          &types.AttributeValueMemberS{Value: id},
+		applingodictionary.ColumnSubcategory: &types.AttributeValueMemberS{Value: "en-ru"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "B1#0"}, item.Item[applingodictionary.ColumnLevelIsPublic])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "en-ru#0"}, item.Item[applingodictionary.ColumnSubcategoryIsPublic])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "B1#en-ru#0"}, item.Item[applingodictionary.ColumnLevelSubcategoryIsPublic])
+
+	// the dictionary left the public indexes.
+	assert.Empty(t, listDictionaries(t, a, map[string]string{"sort_by": "date"}).Items)
+
+	// a stale revision is rejected.
+	assert.Equal(t, http.StatusConflict, patchDictionary(t, a, id, "author", auth.User, `{"revision":0,"public":true}`).StatusCode)
+
+	resp = patchDictionary(t, a, id, "manager", auth.Manager, `{"revision":1,"public":true}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode, resp.Body)
+	assert.Len(t, listDictionaries(t, a, map[string]string{"level": "B1", "sort_by": "date"}).Items, 1)
+
+	// the dictionary:write scope lets a user modify any dictionary.
+	req := request(http.MethodPatch, "/v1/dictionary", auth.JWT, auth.User, map[string]string{"id": id, "subcategory": "en-ru"}, `{"revision":2,"level":"B2"}`)
+	req.RequestContext.Authorizer["identifier"] = "editor"
+	req.RequestContext.Authorizer["scopes"] = auth.ScopeDictionaryWrite
+	resp, err = a.Handle(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, resp.Body)
+}
+
+func TestRevisionCondition(t *testing.T) {
+	ctx := context.Background()
+	db := cloud.NewMemoryDynamo(cloud.NewMemoryTable(applingodictionary.TableSchema))
+	key := map[string]types.AttributeValue{
+		applingodictionary.ColumnId:          &types.AttributeValueMemberS{Value: "id"},
+		applingodictionary.ColumnSubcategory: &types.AttributeValueMemberS{Value: "en-ru"},
+	}
+
+	// items stored before revisions were introduced have no revision attribute.
+	require.NoError(t, db.Put(ctx, applingodictionary.TableSchema.TableName, key, expression.ConditionBuilder{}))
+	update := expression.Set(expression.Name(applingodictionary.ColumnRevision), expression.Value(1))
+	require.NoError(t, db.Update(ctx, applingodictionary.TableSchema.TableName, key, update, revisionCondition(0)))
+
+	var conditionErr *types.ConditionalCheckFailedException
+	err := db.Update(ctx, applingodictionary.TableSchema.TableName, key, update, revisionCondition(0))
+	assert.ErrorAs(t, err, &conditionErr)
+}
+
+// putSearchable stores the dictionary together with the index entries the trigger would write.
+func putSearchable(t *testing.T, db *cloud.MemoryDynamo, dict applingodictionary.SchemaItem) {
+	t.Helper()
+	ctx := context.Background()
+
+	item, err := applingodictionary.PutItem(applingodictionary.NewSchemaItem(dict))
+	require.NoError(t, err)
+	require.NoError(t, db.Put(ctx, applingodictionary.TableSchema.TableName, item, expression.ConditionBuilder{}))
+
+	doc := search.Document{Name: dict.Name, Description: dict.Description, Topic: dict.Topic, Author: dict.Author}
+	for token, posting := range search.Index(doc) {
+		entry, err := applingosearch.PutItem(applingosearch.SchemaItem{
+			Token:        token,
+			DictionaryId: dict.Id,
+			Subcategory:  dict.Subcategory,
+			Weight:       posting.Weight,
+			PrefixWeight: posting.PrefixWeight,
+		})
+		require.NoError(t, err)
+		require.NoError(t, db.Put(ctx, applingosearch.TableSchema.TableName, entry, expression.ConditionBuilder{}))
+	}
+}
+
+func searchDictionaries(t *testing.T, a *api.API, query map[string]string) (int, applingoapi.DictionariesData) {
+	t.Helper()
+
+	resp, err := a.Handle(context.Background(), request(http.MethodGet, "/v1/dictionaries/search", auth.HMAC, auth.Device, query, ""))
+	require.NoError(t, err)
+
+	var out struct {
+		Data applingoapi.DictionariesData `json:"data"`
+	}
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	}
+	return resp.StatusCode, out.Data
+}
+
+func names(items []applingoapi.DictionaryItemV1) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		out = append(out, item.Name)
+	}
+	return out
+}
+
+func TestDictionariesSearch(t *testing.T) {
+	a, db := newTestAPI(t)
+
+	for _, dict := range []applingodictionary.SchemaItem{
+		{Name: "Animals", Topic: "nature", IsPublic: 1},
+		{Name: "Wild animals", Topic: "nature", IsPublic: 1, Rating: 50, Downloads: 1000},
+		{Name: "Animation terms", Topic: "cinema", IsPublic: 1},
+		{Name: "Animals draft", Topic: "nature", IsPublic: 0},
+	} {
+		dict.Id = utils.GenerateDictionaryID(dict.Name, "author")
+		dict.Author, dict.Subcategory, dict.Level, dict.Description = "author", "en-ru", "A1", "words"
+		putSearchable(t, db, dict)
+	}
+
+	status, data := searchDictionaries(t, a, map[string]string{"q": "animals"})
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []string{"Wild animals", "Animals"}, names(data.Items))
+	assert.Nil(t, data.LastEvaluated)
+
+	_, data = searchDictionaries(t, a, map[string]string{"q": "Anim"})
+	assert.ElementsMatch(t, []string{"Wild animals", "Animals", "Animation terms"}, names(data.Items))
+	assert.Equal(t, "Wild animals", data.Items[0].Name)
+
+	_, data = searchDictionaries(t, a, map[string]string{"q": "nature anim"})
+	assert.Equal(t, []string{"Wild animals", "Animals"}, names(data.Items))
+
+	// only the last term matches as a prefix.
+	_, data = searchDictionaries(t, a, map[string]string{"q": "anim nature"})
+	assert.Empty(t, data.Items)
+
+	status, _ = searchDictionaries(t, a, map[string]string{"q": "a"})
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = searchDictionaries(t, a, map[string]string{"q": "!!"})
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = searchDictionaries(t, a, map[string]string{"q": "animals", "last_evaluated": "%%"})
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestDictionariesSearchPagination(t *testing.T) {
+	a, db := newTestAPI(t)
+
+	total := searchPageLimit + 5
+	for i := 0; i < total; i++ {
+		name := "colors " + strconv.Itoa(i)
+		putSearchable(t, db, applingodictionary.SchemaItem{
+			Id:          utils.GenerateDictionaryID(name, "author"),
+			Name:        name,
+			Author:      "author",
+			Subcategory: "en-ru",
+			IsPublic:    1,
+			Downloads:   i,
+		})
+	}
+
+	status, first := searchDictionaries(t, a, map[string]string{"q": "colors"})
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, first.Items, searchPageLimit)
+	require.NotNil(t, first.LastEvaluated)
+	assert.Equal(t, "colors "+strconv.Itoa(total-1), first.Items[0].Name)
+
+	_, second := searchDictionaries(t, a, map[string]string{"q": "colors", "last_evaluated": *first.LastEvaluated})
+	require.Len(t, second.Items, total-searchPageLimit)
+	assert.Nil(t, second.LastEvaluated)
+
+	seen := make(map[string]struct{}, total)
+	for _, item := range append(first.Items, second.Items...) {
+		seen[item.Id] = struct{}{}
+	}
+	assert.Len(t, seen, total)
+}
+
+func publishVersion(t *testing.T, dict applingodictionary.SchemaItem, content string) {
+	t.Helper()
+	ctx := context.Background()
+
+	fileID := utils.RecordToFileID(dict.Id)
+	require.NoError(t, s3Bucket.Put(ctx, fileID, "processing", strings.NewReader(content), cloud.ContentTypeJSON))
+	if dict.Version == 0 {
+		require.NoError(t, versionStore.Create(ctx, dict, fileID, "processing", "processing"))
+		return
+	}
+	_, err := versionStore.Publish(ctx, dict, fileID, "processing", "processing", 0)
+	require.NoError(t, err)
+}
+
+func readDictionaryFile(t *testing.T, key string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, s3Bucket.Read(context.Background(), &buf, key, serviceDictionaryBucket))
+	return buf.String()
+}
+
+func TestDictionaryVersions(t *testing.T) {
+	a, _ := newTestAPI(t)
+	dict := applingodictionary.NewSchemaItem(applingodictionary.SchemaItem{
+		Id:          utils.GenerateDictionaryID("versioned words", "author"),
+		Name:        "versioned words",
+		Author:      "author",
+		Owner:       "user-1",
+		Subcategory: "en-ru",
+		IsPublic:    applingodictionary.BoolToInt(true),
+	})
+	publishVersion(t, dict, `{"words":["first"]}`)
+	dict.Version = 1
+	publishVersion(t, dict, `{"words":["second"]}`)
+	assert.Equal(t, `{"words":["second"]}`, readDictionaryFile(t, utils.RecordToFileID(dict.Id)))
+
+	call := func(method, path string, role auth.Role, identifier string) events.APIGatewayProxyResponse {
+		req := request(method, path, auth.JWT, role, map[string]string{"subcategory": "en-ru"}, "")
+		req.RequestContext.Authorizer["identifier"] = identifier
+		resp, err := a.Handle(context.Background(), req)
+		require.NoError(t, err)
+		return resp
+	}
+	versionsPath := "/v1/dictionaries/" + dict.Id + "/versions"
+
+	resp := call(http.MethodGet, versionsPath, auth.User, "user-1")
+	require.Equal(t, http.StatusOK, resp.StatusCode, resp.Body)
+	var list applingoapi.ResponseGetDictionaryVersionsV1
+	require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &list))
+	require.Len(t, list.Data.Items, 2)
+	assert.Equal(t, int64(2), list.Data.Items[0].Version)
+	assert.True(t, list.Data.Items[0].Current)
+	assert.False(t, list.Data.Items[1].Current)
+	assert.Equal(t, http.StatusForbidden, call(http.MethodGet, versionsPath, auth.User, "user-2").StatusCode)
+
+	resp = call(http.MethodGet, versionsPath+"/1", auth.User, "user-1")
+	require.Equal(t, http.StatusOK, resp.StatusCode, resp.Body)
+	var version applingoapi.ResponseGetDictionaryVersionV1
+	require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &version))
+	assert.Equal(t, "http://localhost/_bucket/dictionary/"+utils.VersionFileID(dict.Id, 1), version.Data.Url)
+	assert.Equal(t, `{"words":["first"]}`, readDictionaryFile(t, utils.VersionFileID(dict.Id, 1)))
+	assert.Equal(t, http.StatusNotFound, call(http.MethodGet, versionsPath+"/9", auth.User, "user-1").StatusCode)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodGet, versionsPath+"/0", auth.User, "user-1").StatusCode)
+
+	// rollbacks are published as a new version by managers only.
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, versionsPath+"/1/rollback", auth.User, "user-1").StatusCode)
+	resp = call(http.MethodPost, versionsPath+"/1/rollback", auth.Manager, "manager-1")
+	require.Equal(t, http.StatusCreated, resp.StatusCode, resp.Body)
+	var rollback applingoapi.ResponsePostDictionaryRollbackV1
+	require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &rollback))
+	assert.Equal(t, int64(3), rollback.Data.Version)
+	require.NotNil(t, rollback.Data.RollbackFrom)
+	assert.Equal(t, int64(1), *rollback.Data.RollbackFrom)
+	assert.Equal(t, `{"words":["first"]}`, readDictionaryFile(t, utils.VersionFileID(dict.Id, 3)))
+	assert.Equal(t, `{"words":["first"]}`, readDictionaryFile(t, utils.RecordToFileID(dict.Id)))
+	assert.Equal(t, http.StatusConflict, call(http.MethodPost, versionsPath+"/3/rollback", auth.Manager, "manager-1").StatusCode)
+
+	// devices compare the version with their cached copy.
+	resp = getDictionary(t, a, dict.Id, auth.HMAC, auth.Device, "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, resp.Body)
+	var out applingoapi.ResponseGetDictionaryV1
+	require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	assert.Equal(t, int64(3), out.Data.Item.Version)
+	assert.Equal(t, "http://localhost/_bucket/dictionary/"+utils.VersionFileID(dict.Id, 3), out.Data.Url)
+
+	// a publish based on a stale version is rejected.
+	_, err := versionStore.Publish(context.Background(), dict, utils.VersionFileID(dict.Id, 1), serviceDictionaryBucket, "processing", 1)
+	assert.ErrorIs(t, err, versions.ErrConflict)
+}
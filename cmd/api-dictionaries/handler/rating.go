@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingorating"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// likeWeight and dislikeWeight are the rating contributions of a vote,
+	// they match the steps of the former anonymous counter.
+	likeWeight    = 2
+	dislikeWeight = -1
+
+	// ratingAttempts bounds the retries of a vote racing with another vote of the same voter.
+	ratingAttempts = 3
+)
+
+var (
+	errNoVoter            = errors.New("votes require a device or user identity")
+	errDictionaryNotFound = errors.New("dictionary not found")
+	errVoteConflict       = errors.New("vote was modified concurrently")
+)
+
+// ratingChange is a change of the caller's rating record, nil fields are kept.
+type ratingChange struct {
+	vote       *applingoapi.BaseVoteEnum
+	downloaded *bool
+}
+
+// voter returns the rating key of the caller. Registered devices and the tokens issued to them carry
+// the profile id, so a profile votes once whichever of them is used. Anonymous callers cannot vote.
+func voter(meta api.MetaData) (string, error) {
+	id := meta.GetIdentifier()
+	if id == "" {
+		return "", errNoVoter
+	}
+	return id, nil
+}
+
+// voteValue converts the API vote to the stored value: 1 for like, -1 for dislike and 0 for none.
+func voteValue(vote applingoapi.BaseVoteEnum) int {
+	switch vote {
+	case applingoapi.Like:
+		return 1
+	case applingoapi.Dislike:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// voteEnum converts the stored vote value to the API vote.
+func voteEnum(value int) applingoapi.BaseVoteEnum {
+	switch {
+	case value > 0:
+		return applingoapi.Like
+	case value < 0:
+		return applingoapi.Dislike
+	default:
+		return applingoapi.None
+	}
+}
+
+// voteWeight returns the contribution of the stored vote value to the dictionary rating.
+func voteWeight(value int) int {
+	switch {
+	case value > 0:
+		return likeWeight
+	case value < 0:
+		return dislikeWeight
+	default:
+		return 0
+	}
+}
+
+// getRating returns the caller's rating record of the dictionary, nil if the caller has not voted or downloaded it.
+func getRating(ctx context.Context, dictionaryID, voterKey string) (*applingorating.SchemaItem, error) {
+	key, err := applingorating.CreateKey(dictionaryID, voterKey)
+	if err != nil {
+		return nil, err
+	}
+	result, err := dbDynamo.Get(ctx, applingorating.TableSchema.TableName, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get rating")
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+	var rating applingorating.SchemaItem
+	if err := attributevalue.UnmarshalMap(result.Item, &rating); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal rating")
+	}
+	return &rating, nil
+}
+
+// applyRating stores the change of the voter's record and adjusts the dictionary counters
+// by the difference to the previous record in one transaction, so repeated votes and downloads
+// of the same voter are counted once. It returns the stored record and the rating difference.
+func applyRating(ctx context.Context, dictKey map[string]types.AttributeValue, dictionaryID, voterKey string, change ratingChange) (applingorating.SchemaItem, int, error) {
+	for range ratingAttempts {
+		prev, err := getRating(ctx, dictionaryID, voterKey)
+		if err != nil {
+			return applingorating.SchemaItem{}, 0, err
+		}
+		next := applingorating.SchemaItem{DictionaryId: dictionaryID, Voter: voterKey}
+		if prev != nil {
+			next = *prev
+		}
+		if change.vote != nil {
+			next.Vote = voteValue(*change.vote)
+		}
+		if change.downloaded != nil {
+			next.Downloaded = applingorating.BoolToInt(*change.downloaded)
+		}
+
+		var (
+			ratingDelta    = voteWeight(next.Vote)
+			downloadsDelta = next.Downloaded
+		)
+		if prev != nil {
+			ratingDelta -= voteWeight(prev.Vote)
+			downloadsDelta -= prev.Downloaded
+		}
+		if ratingDelta == 0 && downloadsDelta == 0 {
+			return next, 0, nil
+		}
+		next.UpdatedAt = int(time.Now().Unix())
+
+		writes, err := ratingWrites(dictKey, prev, next, ratingDelta, downloadsDelta)
+		if err != nil {
+			return applingorating.SchemaItem{}, 0, err
+		}
+		err = dbDynamo.TransactWrite(ctx, writes)
+		if err == nil {
+			return next, ratingDelta, nil
+		}
+		var conditionErr *types.ConditionalCheckFailedException
+		if !errors.As(err, &conditionErr) {
+			return applingorating.SchemaItem{}, 0, errors.Wrap(err, "failed to save rating")
+		}
+		// the transaction is canceled either because the dictionary is gone or the record changed in between.
+		exists, err := dbDynamo.Exists(ctx, applingodictionary.TableSchema.TableName, dictKey)
+		if err != nil {
+			return applingorating.SchemaItem{}, 0, err
+		}
+		if !exists {
+			return applingorating.SchemaItem{}, 0, errDictionaryNotFound
+		}
+	}
+	return applingorating.SchemaItem{}, 0, errVoteConflict
+}
+
+// ratingWrites returns the transaction of the rating change. The record is replaced only if it still
+// matches prev, so the counters are adjusted by the difference the record was actually changed by.
+func ratingWrites(dictKey map[string]types.AttributeValue, prev *applingorating.SchemaItem, next applingorating.SchemaItem, ratingDelta, downloadsDelta int) ([]cloud.TransactWriteItem, error) {
+	item, err := applingorating.PutItem(next)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal rating")
+	}
+
+	unchanged := expression.AttributeNotExists(expression.Name(applingorating.ColumnVoter))
+	if prev != nil {
+		unchanged = expression.And(
+			expression.Name(applingorating.ColumnVote).Equal(expression.Value(prev.Vote)),
+			expression.Name(applingorating.ColumnDownloaded).Equal(expression.Value(prev.Downloaded)),
+		)
+	}
+
+	var update expression.UpdateBuilder
+	if ratingDelta != 0 {
+		update = update.Add(expression.Name(applingodictionary.ColumnRating), expression.Value(ratingDelta))
+	}
+	if downloadsDelta != 0 {
+		update = update.Add(expression.Name(applingodictionary.ColumnDownloads), expression.Value(downloadsDelta))
+	}
+	return []cloud.TransactWriteItem{
+		{
+			Table:     applingorating.TableSchema.TableName,
+			Item:      item,
+			Condition: unchanged,
+		},
+		{
+			Table:     applingodictionary.TableSchema.TableName,
+			Key:       dictKey,
+			Update:    &update,
+			Condition: expression.AttributeExists(expression.Name(applingodictionary.ColumnId)),
+		},
+	}, nil
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoversion"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+)
+
+// versionedDictionary returns the dictionary whose versions are requested. Versions are
+// available to the owner and managers, other callers get the same errors as for edits.
+func versionedDictionary(ctx context.Context, id, subcategory string) (applingodictionary.SchemaItem, *api.HandleError) {
+	result, err := dbDynamo.Get(ctx, applingodictionary.TableSchema.TableName, map[string]types.AttributeValue{
+		applingodictionary.ColumnId:          &types.AttributeValueMemberS{Value: id},
+		applingodictionary.ColumnSubcategory: &types.AttributeValueMemberS{Value: subcategory},
+	})
+	if err != nil {
+		return applingodictionary.SchemaItem{}, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to get dictionary")}
+	}
+	if result.Item == nil {
+		return applingodictionary.SchemaItem{}, &api.HandleError{Status: http.StatusNotFound, Err: errDictionaryNotFound}
+	}
+	var dict applingodictionary.SchemaItem
+	if err := attributevalue.UnmarshalMap(result.Item, &dict); err != nil {
+		return applingodictionary.SchemaItem{}, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to unmarshal dictionary")}
+	}
+
+	meta := api.MustGetMetaData(ctx)
+	if !canRead(meta, dict) {
+		return applingodictionary.SchemaItem{}, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("dictionary is private")}
+	}
+	if !canEdit(meta, dict) {
+		return applingodictionary.SchemaItem{}, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("only the author or a manager can see the dictionary versions")}
+	}
+	return dict, nil
+}
+
+// getVersion returns the version record of the dictionary.
+func getVersion(ctx context.Context, id string, version int) (applingoversion.SchemaItem, *api.HandleError) {
+	key, err := applingoversion.CreateKey(id, version)
+	if err != nil {
+		return applingoversion.SchemaItem{}, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	result, err := dbDynamo.Get(ctx, applingoversion.TableSchema.TableName, key)
+	if err != nil {
+		return applingoversion.SchemaItem{}, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to get version")}
+	}
+	if result.Item == nil {
+		return applingoversion.SchemaItem{}, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("version not found")}
+	}
+	var record applingoversion.SchemaItem
+	if err := attributevalue.UnmarshalMap(result.Item, &record); err != nil {
+		return applingoversion.SchemaItem{}, &api.HandleError{Status: http.StatusInternalServerError, Err: errors.Wrap(err, "failed to unmarshal version")}
+	}
+	return record, nil
+}
+
+// versionItem converts the version record to the API representation.
+func versionItem(record applingoversion.SchemaItem, current int) applingoapi.DictionaryVersionItemV1 {
+	item := applingoapi.DictionaryVersionItemV1{
+		Version: int64(record.Version),
+		Created: int64(record.Created),
+		Current: record.Version == current,
+	}
+	if record.Actor != "" {
+		item.Actor = &record.Actor
+	}
+	if record.RollbackFrom > 0 {
+		rollbackFrom := int64(record.RollbackFrom)
+		item.RollbackFrom = &rollbackFrom
+	}
+	return item
+}
@@ -6,29 +6,31 @@ import (
 	"os"
 	"runtime/debug"
 
+	"github.com/Mad-Pixels/applingo-api/cmd/api-dictionaries/handler"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
 	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
-	"github.com/Mad-Pixels/applingo-api/pkg/validator"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
 )
 
 var (
-	awsRegion = os.Getenv("AWS_REGION")
-	validate  *validator.Validator
-	dbDynamo  *cloud.Dynamo
+	serviceDictionaryBucket = os.Getenv("SERVICE_DICTIONARY_BUCKET")
+	awsRegion               = os.Getenv("AWS_REGION")
+
+	dbDynamo *cloud.Dynamo
+	s3Bucket *cloud.Bucket
 )
 
 func init() {
 	debug.SetGCPercent(500)
-	validate = validator.New()
 
 	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
 	if err != nil {
 		panic("unable to load AWS SDK config: " + err.Error())
 	}
 	dbDynamo = cloud.NewDynamo(cfg)
+	s3Bucket = cloud.NewBucket(cfg)
 }
 
 func main() {
@@ -36,18 +38,16 @@ func main() {
 		api.NewLambda(
 			api.Config{
 				EnableRequestLogging: true,
+				Middlewares: []api.Middleware{
+					api.WithRecovery(),
+					api.WithTiming(),
+				},
 			},
-			map[string]api.HandleFunc{
-				// list
-				"GET:/v1/dictionaries": handleDictionariesGet,
-
-				// item
-				"POST:/v1/dictionary":   handleDictionaryPost,
-				"DELETE:/v1/dictionary": handleDictionaryDelete,
-
-				// specific
-				"PATCH:/v1/dictionary/statistic": handleDictionaryStatisticPatch,
-			},
+			handler.Routes(handler.Config{
+				Dynamo:           dbDynamo,
+				Bucket:           s3Bucket,
+				DictionaryBucket: serviceDictionaryBucket,
+			}),
 		).Handle,
 	)
 }
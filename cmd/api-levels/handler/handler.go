@@ -0,0 +1,16 @@
+// Package handler implements the levels API routes.
+// It is served by the api-levels Lambda and by the local server.
+package handler
+
+import (
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+)
+
+// Routes returns the routes map.
+func Routes() map[string]api.HandleFunc {
+	return map[string]api.HandleFunc{
+		// list
+		"GET:/v1/levels": api.Chain(handleLevelsGet, api.WithPermissions(auth.Device)),
+	}
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprofile"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/achievement"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/ledger"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/rs/zerolog"
+)
+
+// achievementCatalog returns the catalog of achievements, achievements are not evaluated while it cannot be loaded.
+func achievementCatalog(ctx context.Context, logger zerolog.Logger) achievement.Catalog {
+	if achievements == nil {
+		return achievement.Catalog{}
+	}
+	catalog, err := achievements.Catalog(ctx)
+	if err != nil {
+		logger.Warn().Err(err).Msg("achievements catalog is not available")
+	}
+	return catalog
+}
+
+func profileStreak(profile applingoprofile.SchemaItem) achievement.Streak {
+	return achievement.Streak{
+		Current: profile.Streak,
+		Best:    profile.StreakBest,
+		Day:     profile.StreakDay,
+		Freezes: profile.StreakFreezes,
+	}
+}
+
+func decodeStats(raw string) (achievement.Stats, error) {
+	stats := achievement.Stats{}
+	if raw == "" {
+		return stats, nil
+	}
+	if err := serializer.UnmarshalJSON([]byte(raw), &stats); err != nil {
+		return nil, fmt.Errorf("failed to decode profile stats: %w", err)
+	}
+	return stats, nil
+}
+
+func decodeUnlocked(raw string) (achievement.Unlocked, error) {
+	unlocked := achievement.Unlocked{}
+	if raw == "" {
+		return unlocked, nil
+	}
+	if err := serializer.UnmarshalJSON([]byte(raw), &unlocked); err != nil {
+		return nil, fmt.Errorf("failed to decode profile achievements: %w", err)
+	}
+	return unlocked, nil
+}
+
+func encodeJSON(value any) (string, error) {
+	data, err := serializer.MarshalJSON(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// achievementValues returns the stats of the profile together with the metrics kept in the profile itself.
+func achievementValues(profile applingoprofile.SchemaItem, stats achievement.Stats, streak achievement.Streak, now time.Time) achievement.Stats {
+	values := maps.Clone(stats)
+	values[achievement.MetricLevel] = profile.Level
+	values[achievement.MetricXPTotal] = profileTotal(profile)
+	values[achievement.MetricStreak] = streak.At(ledger.Day(now))
+	values[achievement.MetricBestStreak] = streak.Best
+	return values
+}
+
+// unlockAchievements evaluates the catalog on the profile with the updated stats and streak and stores
+// them in the profile together with the unlocked achievements. The newly unlocked achievements are returned.
+func unlockAchievements(
+	ctx context.Context,
+	logger zerolog.Logger,
+	profile *applingoprofile.SchemaItem,
+	stats achievement.Stats,
+	streak achievement.Streak,
+	now time.Time,
+) ([]achievement.Rule, error) {
+	unlocked, err := decodeUnlocked(profile.Achievements)
+	if err != nil {
+		return nil, err
+	}
+	reached := achievementCatalog(ctx, logger).Unlock(achievementValues(*profile, stats, streak, now), unlocked, now)
+	for _, rule := range reached {
+		streak.Freeze(rule.Freezes)
+	}
+
+	if profile.Stats, err = encodeJSON(stats); err != nil {
+		return nil, fmt.Errorf("failed to encode profile stats: %w", err)
+	}
+	if profile.Achievements, err = encodeJSON(unlocked); err != nil {
+		return nil, fmt.Errorf("failed to encode profile achievements: %w", err)
+	}
+	profile.Streak, profile.StreakBest = streak.Current, streak.Best
+	profile.StreakDay, profile.StreakFreezes = streak.Day, streak.Freezes
+	return reached, nil
+}
+
+func achievementItem(rule achievement.Rule, values achievement.Stats, unlocked achievement.Unlocked) applingoapi.AchievementItemV1 {
+	progress := values.Value(rule)
+	item := applingoapi.AchievementItemV1{
+		Id:       rule.ID,
+		Name:     rule.Name,
+		Metric:   &rule.Metric,
+		Target:   &rule.Target,
+		Progress: &progress,
+	}
+	if rule.Description != "" {
+		item.Description = &rule.Description
+	}
+	if at, ok := unlocked[rule.ID]; ok {
+		item.Unlocked = &at
+	}
+	return item
+}
+
+// unlockedItems returns the newly unlocked achievements, nil if there are none.
+func unlockedItems(rules []achievement.Rule, profile applingoprofile.SchemaItem, now time.Time) (*[]applingoapi.AchievementItemV1, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	stats, err := decodeStats(profile.Stats)
+	if err != nil {
+		return nil, err
+	}
+	unlocked, err := decodeUnlocked(profile.Achievements)
+	if err != nil {
+		return nil, err
+	}
+	values := achievementValues(profile, stats, profileStreak(profile), now)
+
+	items := make([]applingoapi.AchievementItemV1, 0, len(rules))
+	for _, rule := range rules {
+		items = append(items, achievementItem(rule, values, unlocked))
+	}
+	return &items, nil
+}
+
+// dictionaryMeta returns the level and the subcategory of the dictionary, both are empty if it is not found.
+func dictionaryMeta(ctx context.Context, id string) (level, subcategory string, err error) {
+	input, err := dbDynamo.BuildQueryInput(cloud.QueryInput{
+		KeyCondition:     expression.Key(applingodictionary.ColumnId).Equal(expression.Value(id)),
+		ProjectionFields: []string{applingodictionary.ColumnLevel, applingodictionary.ColumnSubcategory},
+		Limit:            1,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	result, err := dbDynamo.Query(ctx, applingodictionary.TableSchema.TableName, input)
+	if err != nil || len(result.Items) == 0 {
+		return "", "", err
+	}
+	var dictionary applingodictionary.SchemaItem
+	if err := attributevalue.UnmarshalMap(result.Items[0], &dictionary); err != nil {
+		return "", "", err
+	}
+	return dictionary.Level, dictionary.Subcategory, nil
+}
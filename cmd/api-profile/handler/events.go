@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoledger"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprofile"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprogress"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/ledger"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	reasonDailyLimit    = "daily XP limit reached"
+	reasonNotReviewed   = "event rejected: no words of the dictionary were reviewed"
+	reasonDuplicateSent = "event is sent twice"
+)
+
+var errEventsConflict = errors.New("ledger was modified concurrently")
+
+func ledgerKey(profileID, id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		applingoledger.ColumnProfileId: &types.AttributeValueMemberS{Value: profileID},
+		applingoledger.ColumnId:        &types.AttributeValueMemberS{Value: id},
+	}
+}
+
+// getLedgerEvent returns the recorded event, found is false if the event was not recorded yet.
+func getLedgerEvent(ctx context.Context, profileID, id string) (item applingoledger.SchemaItem, found bool, err error) {
+	out, err := dbDynamo.Get(ctx, applingoledger.TableSchema.TableName, ledgerKey(profileID, id))
+	if err != nil || out == nil || len(out.Item) == 0 {
+		return item, false, err
+	}
+	if err := attributevalue.UnmarshalMap(out.Item, &item); err != nil {
+		return item, false, err
+	}
+	return item, true, nil
+}
+
+// hasProgress reports whether the profile reviewed any word of the dictionary.
+func hasProgress(ctx context.Context, profileID, dictionaryID string) (bool, error) {
+	input, err := dbDynamo.BuildQueryInput(cloud.QueryInput{
+		IndexName: applingoprogress.IndexDictionaryByDueIndex,
+		KeyCondition: expression.Key(applingoprogress.ColumnProfileIdDictionaryId).
+			Equal(expression.Value(applingoprogress.ComposeProfileIdDictionaryId(profileID, dictionaryID))),
+		ProjectionFields: []string{applingoprogress.ColumnProfileId},
+		Limit:            1,
+	})
+	if err != nil {
+		return false, err
+	}
+	result, err := dbDynamo.Query(ctx, applingoprogress.TableSchema.TableName, input)
+	if err != nil {
+		return false, err
+	}
+	return len(result.Items) > 0, nil
+}
+
+// ledgerEvent converts the event reported by the device.
+func ledgerEvent(item applingoapi.ProfileEventItemV1) ledger.Event {
+	event := ledger.Event{
+		Type:     string(item.Type),
+		Occurred: item.Occurred,
+	}
+	if item.Words != nil {
+		event.Words = *item.Words
+	}
+	if item.Duration != nil {
+		event.Duration = *item.Duration
+	}
+	if item.DictionaryId != nil {
+		event.DictionaryID = *item.DictionaryId
+	}
+	return event
+}
+
+// putLedgerEvent appends the event to the ledger, an event is never overwritten.
+func putLedgerEvent(item applingoledger.SchemaItem) (cloud.TransactWriteItem, error) {
+	av, err := applingoledger.PutItem(item)
+	if err != nil {
+		return cloud.TransactWriteItem{}, err
+	}
+	return cloud.TransactWriteItem{
+		Table:     applingoledger.TableSchema.TableName,
+		Item:      av,
+		Condition: expression.AttributeNotExists(expression.Name(applingoledger.ColumnId)),
+	}, nil
+}
+
+// updateProgress stores the progress and the achievements of the profile read at the revision.
+func updateProgress(profile applingoprofile.SchemaItem, revision int) cloud.TransactWriteItem {
+	update := expression.
+		Set(expression.Name(applingoprofile.ColumnLevel), expression.Value(profile.Level)).
+		Set(expression.Name(applingoprofile.ColumnXp), expression.Value(profile.Xp)).
+		Set(expression.Name(applingoprofile.ColumnXpTotal), expression.Value(profile.XpTotal)).
+		Set(expression.Name(applingoprofile.ColumnXpDay), expression.Value(profile.XpDay)).
+		Set(expression.Name(applingoprofile.ColumnXpToday), expression.Value(profile.XpToday)).
+		Set(expression.Name(applingoprofile.ColumnStreak), expression.Value(profile.Streak)).
+		Set(expression.Name(applingoprofile.ColumnStreakBest), expression.Value(profile.StreakBest)).
+		Set(expression.Name(applingoprofile.ColumnStreakDay), expression.Value(profile.StreakDay)).
+		Set(expression.Name(applingoprofile.ColumnStreakFreezes), expression.Value(profile.StreakFreezes)).
+		Set(expression.Name(applingoprofile.ColumnStats), expression.Value(profile.Stats)).
+		Set(expression.Name(applingoprofile.ColumnAchievements), expression.Value(profile.Achievements)).
+		Set(expression.Name(applingoprofile.ColumnRevision), expression.Value(revision+1))
+	return cloud.TransactWriteItem{
+		Table:     applingoprofile.TableSchema.TableName,
+		Key:       profileKey(profile.Id),
+		Update:    &update,
+		Condition: revisionCondition(revision),
+	}
+}
+
+// earnedToday returns the XP counted against the daily limit of the day.
+func earnedToday(profile applingoprofile.SchemaItem, now time.Time) (day, today int) {
+	day = ledger.Day(now)
+	if profile.XpDay == day {
+		today = profile.XpToday
+	}
+	return day, today
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/ledger"
+
+	"github.com/rs/zerolog"
+)
+
+// handleProfileAchievementsGet returns the streak of the profile and the achievements of the catalog
+// with the progress towards them. Achievements retired from the catalog are kept if they were unlocked.
+func handleProfileAchievementsGet(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
+	params := applingoapi.GetProfileAchievementsV1Params{
+		Id: baseParams.GetStringDefault("id", ""),
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, api.NewValidationError(err)
+	}
+
+	profile, handleErr := getProfile(ctx, params.Id)
+	if handleErr != nil {
+		return nil, handleErr
+	}
+	stats, err := decodeStats(profile.Stats)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	unlocked, err := decodeUnlocked(profile.Achievements)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	now := time.Now()
+	streak := profileStreak(profile)
+	values := achievementValues(profile, stats, streak, now)
+	catalog := achievementCatalog(ctx, logger)
+
+	data := applingoapi.AchievementsData{
+		Streak: applingoapi.ProfileStreakV1{
+			Current: streak.At(ledger.Day(now)),
+			Best:    streak.Best,
+			Freezes: streak.Freezes,
+		},
+		Items: make([]applingoapi.AchievementItemV1, 0, len(catalog.Achievements)),
+	}
+	for _, rule := range catalog.Achievements {
+		data.Items = append(data.Items, achievementItem(rule, values, unlocked))
+	}
+
+	retired := make([]string, 0)
+	for id := range unlocked {
+		if _, ok := catalog.Get(id); !ok {
+			retired = append(retired, id)
+		}
+	}
+	sort.Strings(retired)
+	for _, id := range retired {
+		at := unlocked[id]
+		data.Items = append(data.Items, applingoapi.AchievementItemV1{Id: id, Name: id, Unlocked: &at})
+	}
+	return openapi.DataResponseAchievements(data), nil
+}
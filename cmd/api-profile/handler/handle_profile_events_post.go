@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoledger"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/achievement"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/ledger"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog"
+)
+
+// handleProfileEventsPost appends the learning events of the device to the ledger and awards XP
+// for them by the server rules. The events and the derived progress of the profile are written
+// in one transaction, which is repeated on a fresh profile if it was changed in between.
+// Events already in the ledger are reported with their stored result and are not counted again.
+// Accepted events also extend the streak and the stats of the profile, which unlock achievements.
+// The XP of the stored events reaches the leaderboards through the ledger stream.
+func handleProfileEventsPost(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
+	req := api.MustGetBody[applingoapi.RequestPostProfileEventsV1](ctx)
+	if handleErr := authorizeProfile(ctx, req.Id); handleErr != nil {
+		return nil, handleErr
+	}
+
+	for range syncAttempts {
+		profile, handleErr := getProfile(ctx, req.Id)
+		if handleErr != nil {
+			return nil, handleErr
+		}
+		now := time.Now()
+		total := profileTotal(profile)
+		day, today := earnedToday(profile, now)
+		stats, err := decodeStats(profile.Stats)
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+
+		var (
+			writes []cloud.TransactWriteItem
+			active []int // days of the accepted events
+		)
+		if profile.XpTotal == 0 && total > 0 {
+			write, err := putLedgerEvent(applingoledger.SchemaItem{
+				ProfileId: req.Id,
+				Id:        ledger.LegacyID,
+				Kind:      ledger.TypeLegacy,
+				Xp:        total,
+				Occurred:  int(now.Unix()),
+				Created:   int(now.Unix()),
+			})
+			if err != nil {
+				return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+			}
+			writes = append(writes, write)
+		}
+
+		results := make([]applingoapi.ProfileEventResultV1, 0, len(req.Events))
+		sent := make(map[string]bool, len(req.Events))
+		for _, item := range req.Events {
+			event := ledgerEvent(item)
+			key := ledger.Key(item.Id, event)
+			result := applingoapi.ProfileEventResultV1{Id: item.Id}
+
+			if sent[key] {
+				result.Reason = reason(reasonDuplicateSent)
+				results = append(results, result)
+				continue
+			}
+			sent[key] = true
+
+			stored, found, err := getLedgerEvent(ctx, req.Id, key)
+			if err != nil {
+				return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+			}
+			if found {
+				duplicate := true
+				result.Xp, result.Reason, result.Duplicate = stored.Xp, reason(stored.Reason), &duplicate
+				results = append(results, result)
+				continue
+			}
+
+			xp, rejectReason, handleErr := award(ctx, req.Id, event, now)
+			if handleErr != nil {
+				return nil, handleErr
+			}
+			accepted := rejectReason == ""
+			if limited := ledger.Limit(xp, today); limited < xp {
+				xp, rejectReason = limited, reasonDailyLimit
+			}
+			if xp == 0 && event.Type == ledger.TypeDictionaryFinished {
+				// a dictionary is recorded once, so it is kept out of the ledger until it earns XP.
+				result.Reason = reason(rejectReason)
+				results = append(results, result)
+				continue
+			}
+			today += xp
+			total += xp
+
+			var level, subcategory string
+			if accepted {
+				// the pair of the dictionary counts the XP in its leaderboards.
+				if event.DictionaryID != "" {
+					if level, subcategory, err = dictionaryMeta(ctx, event.DictionaryID); err != nil {
+						return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+					}
+				}
+				countEvent(stats, event, level, subcategory)
+				active = append(active, ledger.Day(time.Unix(event.Occurred, 0)))
+			}
+
+			write, err := putLedgerEvent(applingoledger.SchemaItem{
+				ProfileId:    req.Id,
+				Id:           key,
+				Kind:         event.Type,
+				Xp:           xp,
+				Words:        event.Words,
+				Duration:     event.Duration,
+				DictionaryId: event.DictionaryID,
+				Level:        level,
+				Subcategory:  subcategory,
+				Occurred:     int(event.Occurred),
+				Created:      int(now.Unix()),
+				Reason:       rejectReason,
+			})
+			if err != nil {
+				return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+			}
+			writes = append(writes, write)
+			result.Xp, result.Reason = xp, reason(rejectReason)
+			results = append(results, result)
+		}
+
+		var reached []achievement.Rule
+		if len(writes) > 0 {
+			revision := profile.Revision
+			profile.Level, profile.Xp = ledger.Level(total)
+			profile.XpTotal, profile.XpDay, profile.XpToday = total, day, today
+
+			streak := profileStreak(profile)
+			sort.Ints(active)
+			for _, activeDay := range active {
+				streak = streak.Visit(activeDay)
+			}
+			if reached, err = unlockAchievements(ctx, logger, &profile, stats, streak, now); err != nil {
+				return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+			}
+
+			writes = append(writes, updateProgress(profile, revision))
+			if err := dbDynamo.TransactWrite(ctx, writes); err != nil {
+				var conditionErr *types.ConditionalCheckFailedException
+				if errors.As(err, &conditionErr) {
+					continue
+				}
+				return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+			}
+		}
+
+		data, err := profileData(profile)
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		unlocked, err := unlockedItems(reached, profile, now)
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		return &api.Response{
+			Status: http.StatusOK,
+			Body: openapi.DataResponseProfileEvents(applingoapi.ProfileEventsData{
+				Profile:  data,
+				Events:   results,
+				Unlocked: unlocked,
+			}),
+		}, nil
+	}
+	return nil, &api.HandleError{Status: http.StatusConflict, Message: "profile is being updated, retry", Err: errEventsConflict}
+}
+
+// award returns the XP the event earns and the reason if it earns none.
+func award(ctx context.Context, profileID string, event ledger.Event, now time.Time) (int, string, *api.HandleError) {
+	xp, err := ledger.Award(event, now)
+	if err != nil {
+		if errors.Is(err, ledger.ErrRejected) {
+			return 0, err.Error(), nil
+		}
+		return 0, "", &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	if event.Type == ledger.TypeDictionaryFinished {
+		reviewed, err := hasProgress(ctx, profileID, event.DictionaryID)
+		if err != nil {
+			return 0, "", &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		if !reviewed {
+			return 0, reasonNotReviewed, nil
+		}
+	}
+	return xp, "", nil
+}
+
+// countEvent adds the accepted event to the stats of the profile.
+func countEvent(stats achievement.Stats, event ledger.Event, level, subcategory string) {
+	switch event.Type {
+	case ledger.TypeSessionCompleted:
+		stats[achievement.MetricSessions]++
+	case ledger.TypeWordsReviewed:
+		stats[achievement.MetricWords] += event.Words
+	case ledger.TypeDictionaryFinished:
+		stats.AddDictionary(achievement.MetricDictionariesFinished, level, subcategory)
+	}
+}
+
+func reason(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+
+	"github.com/rs/zerolog"
+)
+
+func handleProfileGet(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
+	params := applingoapi.GetProfileV1Params{
+		Id: baseParams.GetStringDefault("id", ""),
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, api.NewValidationError(err)
+	}
+	if handleErr := authorizeLegacyProfile(ctx, params.Id); handleErr != nil {
+		return nil, handleErr
+	}
+
+	profile, handleErr := getProfile(ctx, params.Id)
+	if handleErr != nil {
+		return nil, handleErr
+	}
+	data, err := profileData(profile)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	return openapi.DataResponseProfile(data), nil
+}
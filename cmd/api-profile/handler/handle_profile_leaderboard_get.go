@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/leaderboard"
+
+	"github.com/rs/zerolog"
+)
+
+// defaultLeaderboardLimit is the number of profiles from the top returned when the device does not ask for a limit.
+const defaultLeaderboardLimit = 10
+
+// handleProfileLeaderboardGet returns the top of the leaderboard of the period, overall or of a language pair,
+// together with the rank of the profile of the caller. Boards are kept up to date from the ledger stream, so reading them
+// is a query of the top and, if the profile is not among it, a count of the entries above the profile.
+func handleProfileLeaderboardGet(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
+	validPeriods := map[applingoapi.BaseLeaderboardPeriodEnum]struct{}{
+		applingoapi.Weekly:  {},
+		applingoapi.AllTime: {},
+	}
+	paramPeriod, err := openapi.ParseEnumParam(baseParams.GetStringPtr("period"), validPeriods)
+	if err != nil {
+		return nil, api.NewParamError("period", err)
+	}
+	if paramPeriod == nil {
+		period := applingoapi.Weekly
+		paramPeriod = &period
+	}
+	params := applingoapi.GetProfileLeaderboardV1Params{
+		Id:          baseParams.GetStringDefault("id", ""),
+		Period:      paramPeriod,
+		Subcategory: baseParams.GetStringPtr("subcategory"),
+		Limit:       baseParams.GetIntPtr("limit"),
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, api.NewValidationError(err)
+	}
+	if handleErr := authorizeProfile(ctx, params.Id); handleErr != nil {
+		return nil, handleErr
+	}
+	limit := defaultLeaderboardLimit
+	if params.Limit != nil && *params.Limit > 0 {
+		limit = *params.Limit
+	}
+	var subcategory string
+	if params.Subcategory != nil {
+		subcategory = *params.Subcategory
+	}
+
+	now := time.Now()
+	board := leaderboard.Board(string(*params.Period), now, subcategory)
+	top, err := leaderboard.Top(ctx, dbDynamo, board, limit)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	data := applingoapi.LeaderboardData{
+		Period: *params.Period,
+		Items:  make([]applingoapi.LeaderboardItemV1, 0, len(top)),
+		Me:     applingoapi.LeaderboardMeV1{Player: leaderboard.Player(params.Id)},
+	}
+	if *params.Period == applingoapi.Weekly {
+		week := leaderboard.Week(now)
+		data.Week = &week
+	}
+	if subcategory != "" {
+		data.Subcategory = &subcategory
+	}
+	for _, entry := range top {
+		item := applingoapi.LeaderboardItemV1{Rank: entry.Rank, Player: leaderboard.Player(entry.ProfileID), Xp: entry.XP}
+		if entry.ProfileID == params.Id {
+			me, rank := true, entry.Rank
+			item.Me = &me
+			data.Me.Rank, data.Me.Xp = &rank, entry.XP
+		}
+		data.Items = append(data.Items, item)
+	}
+
+	if data.Me.Rank == nil {
+		entry, found, err := leaderboard.Get(ctx, dbDynamo, board, params.Id)
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		if found {
+			rank, err := leaderboard.Rank(ctx, dbDynamo, board, entry.XP)
+			if err != nil {
+				return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+			}
+			data.Me.Xp = entry.XP
+			if rank > 0 {
+				data.Me.Rank = &rank
+			}
+		}
+	}
+	return openapi.DataResponseLeaderboard(data), nil
+}
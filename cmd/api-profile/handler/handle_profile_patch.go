@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+
+	"github.com/rs/zerolog"
+)
+
+// handleProfilePatch is kept for devices which still report their progress, the reported
+// level and xp are ignored because progress is derived from the events ledger.
+func handleProfilePatch(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
+	req := api.MustGetBody[applingoapi.RequestPatchProfileV1](ctx)
+	if handleErr := authorizeLegacyProfile(ctx, req.Id); handleErr != nil {
+		return nil, handleErr
+	}
+
+	profile, handleErr := getProfile(ctx, req.Id)
+	if handleErr != nil {
+		return nil, handleErr
+	}
+	data, err := profileData(profile)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	return openapi.DataResponseProfile(data), nil
+}
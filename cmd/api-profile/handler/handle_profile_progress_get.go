@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprogress"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/rs/zerolog"
+)
+
+// handleProfileProgressGet returns the due cards of the profile, the most overdue first.
+func handleProfileProgressGet(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
+	params := applingoapi.GetProfileProgressV1Params{
+		Id:           baseParams.GetStringDefault("id", ""),
+		DictionaryId: baseParams.GetStringPtr("dictionary_id"),
+		Limit:        baseParams.GetIntPtr("limit"),
+	}
+	if err := validate.ValidateStruct(&params); err != nil {
+		return nil, api.NewValidationError(err)
+	}
+	if handleErr := authorizeProfile(ctx, params.Id); handleErr != nil {
+		return nil, handleErr
+	}
+	limit := defaultDueLimit
+	if params.Limit != nil && *params.Limit > 0 {
+		limit = *params.Limit
+	}
+
+	due := expression.Key(applingoprogress.ColumnDue).LessThanEqual(expression.Value(time.Now().Unix()))
+	input := cloud.QueryInput{
+		IndexName:    applingoprogress.IndexProfileByDueIndex,
+		KeyCondition: expression.Key(applingoprogress.ColumnProfileId).Equal(expression.Value(params.Id)).And(due),
+		Limit:        int32(limit),
+		ScanForward:  true,
+	}
+	if params.DictionaryId != nil {
+		input.IndexName = applingoprogress.IndexDictionaryByDueIndex
+		input.KeyCondition = expression.Key(applingoprogress.ColumnProfileIdDictionaryId).
+			Equal(expression.Value(applingoprogress.ComposeProfileIdDictionaryId(params.Id, *params.DictionaryId))).
+			And(due)
+	}
+	queryInput, err := dbDynamo.BuildQueryInput(input)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	result, err := dbDynamo.Query(ctx, applingoprogress.TableSchema.TableName, queryInput)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	response := applingoapi.ProgressData{
+		Items: make([]applingoapi.ProgressCardItemV1, 0, len(result.Items)),
+	}
+	for _, av := range result.Items {
+		var item applingoprogress.SchemaItem
+		if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+			logger.Warn().Err(err).Msg("Failed to unmarshal DynamoDB item")
+			continue
+		}
+		response.Items = append(response.Items, progressItem(item))
+	}
+	return openapi.DataResponseProgress(response), nil
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoledger"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprofile"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprogress"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/achievement"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/ledger"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog"
+)
+
+// handleProfileProgressPost reschedules the reviewed cards of the profile on the server.
+// Reviews of a card are applied in time order on top of the stored state, reviews which
+// are not after the last counted one are skipped, so a device can safely resend a batch.
+// Dictionaries studied for the first time are counted in the stats of the profile.
+func handleProfileProgressPost(ctx context.Context, logger zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
+	req := api.MustGetBody[applingoapi.RequestPostProfileProgressV1](ctx)
+
+	if handleErr := authorizeProfile(ctx, req.Id); handleErr != nil {
+		return nil, handleErr
+	}
+	if _, handleErr := getProfile(ctx, req.Id); handleErr != nil {
+		return nil, handleErr
+	}
+
+	cards := map[string][]applingoapi.ProgressReviewItemV1{}
+	for _, review := range req.Reviews {
+		key := applingoprogress.ComposeDictionaryIdWord(review.DictionaryId, review.Word)
+		cards[key] = append(cards[key], review)
+	}
+	keys := make([]string, 0, len(cards))
+	for key, reviews := range cards {
+		sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].Reviewed < reviews[j].Reviewed })
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	now := time.Now()
+	response := applingoapi.ProgressData{
+		Items: make([]applingoapi.ProgressCardItemV1, 0, len(keys)),
+	}
+	var studied []string
+	for chunk := range slices.Chunk(keys, maxCards) {
+		items, handleErr := saveProgress(ctx, req.Id, cards, chunk, now.Unix())
+		if handleErr != nil {
+			return nil, handleErr
+		}
+		for _, item := range items {
+			response.Items = append(response.Items, progressItem(item))
+			if item.Reviewed > 0 && !slices.Contains(studied, item.DictionaryId) {
+				studied = append(studied, item.DictionaryId)
+			}
+		}
+	}
+
+	var (
+		reached []achievement.Rule
+		profile applingoprofile.SchemaItem
+	)
+	for chunk := range slices.Chunk(studied, maxStudied) {
+		rules, stored, handleErr := studyDictionaries(ctx, logger, req.Id, chunk, now)
+		if handleErr != nil {
+			return nil, handleErr
+		}
+		reached, profile = append(reached, rules...), stored
+	}
+	unlocked, err := unlockedItems(reached, profile, now)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	response.Unlocked = unlocked
+	return &api.Response{Status: http.StatusOK, Body: openapi.DataResponseProgress(response)}, nil
+}
+
+// saveProgress applies the reviews of the cards at the keys, the changed cards are written in one transaction.
+// The cards are read again if another request wrote any of them in between.
+func saveProgress(
+	ctx context.Context,
+	profileID string,
+	cards map[string][]applingoapi.ProgressReviewItemV1,
+	keys []string,
+	now int64,
+) ([]applingoprogress.SchemaItem, *api.HandleError) {
+	for range syncAttempts {
+		stored, found, err := getProgress(ctx, profileID, cards, keys)
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+
+		items := make([]applingoprogress.SchemaItem, 0, len(keys))
+		var writes []cloud.TransactWriteItem
+		for _, key := range keys {
+			item := stored[key]
+			reviewed := item.Reviewed
+
+			changed, err := reviewProgress(&item, cards[key], now)
+			if err != nil {
+				return nil, &api.HandleError{Status: http.StatusBadRequest, Message: err.Error(), Err: err}
+			}
+			items = append(items, item)
+			if !changed {
+				continue
+			}
+
+			av, err := applingoprogress.PutItem(item)
+			if err != nil {
+				return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+			}
+			writes = append(writes, cloud.TransactWriteItem{
+				Table:     applingoprogress.TableSchema.TableName,
+				Item:      av,
+				Condition: progressCondition(reviewed, found[key]),
+			})
+		}
+		if err := dbDynamo.TransactWrite(ctx, writes); err != nil {
+			var conditionErr *types.ConditionalCheckFailedException
+			if errors.As(err, &conditionErr) {
+				continue
+			}
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		return items, nil
+	}
+	return nil, &api.HandleError{Status: http.StatusConflict, Message: "card is being reviewed, retry", Err: errProgressConflict}
+}
+
+// studyDictionaries counts the dictionaries studied by the profile for the first time. Each of them is
+// recorded in the ledger, so a dictionary is counted once however often its reviews are resent.
+// The ledger and the stats of the profile are written in one transaction.
+func studyDictionaries(
+	ctx context.Context,
+	logger zerolog.Logger,
+	profileID string,
+	dictionaries []string,
+	now time.Time,
+) ([]achievement.Rule, applingoprofile.SchemaItem, *api.HandleError) {
+	for range syncAttempts {
+		profile, handleErr := getProfile(ctx, profileID)
+		if handleErr != nil {
+			return nil, profile, handleErr
+		}
+		stats, err := decodeStats(profile.Stats)
+		if err != nil {
+			return nil, profile, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+
+		var writes []cloud.TransactWriteItem
+		for _, dictionaryID := range dictionaries {
+			key := ledger.Key("", ledger.Event{Type: ledger.TypeDictionaryStudied, DictionaryID: dictionaryID})
+			_, found, err := getLedgerEvent(ctx, profileID, key)
+			if err != nil {
+				return nil, profile, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+			}
+			if found {
+				continue
+			}
+			level, subcategory, err := dictionaryMeta(ctx, dictionaryID)
+			if err != nil {
+				return nil, profile, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+			}
+			stats.AddDictionary(achievement.MetricDictionariesStudied, level, subcategory)
+
+			write, err := putLedgerEvent(applingoledger.SchemaItem{
+				ProfileId:    profileID,
+				Id:           key,
+				Kind:         ledger.TypeDictionaryStudied,
+				DictionaryId: dictionaryID,
+				Level:        level,
+				Subcategory:  subcategory,
+				Occurred:     int(now.Unix()),
+				Created:      int(now.Unix()),
+			})
+			if err != nil {
+				return nil, profile, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+			}
+			writes = append(writes, write)
+		}
+		if len(writes) == 0 {
+			return nil, profile, nil
+		}
+
+		revision := profile.Revision
+		reached, err := unlockAchievements(ctx, logger, &profile, stats, profileStreak(profile), now)
+		if err != nil {
+			return nil, profile, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		writes = append(writes, updateProgress(profile, revision))
+		if err := dbDynamo.TransactWrite(ctx, writes); err != nil {
+			var conditionErr *types.ConditionalCheckFailedException
+			if errors.As(err, &conditionErr) {
+				continue
+			}
+			return nil, profile, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		return reached, profile, nil
+	}
+	return nil, applingoprofile.SchemaItem{}, &api.HandleError{Status: http.StatusConflict, Message: "profile is being updated, retry", Err: errEventsConflict}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprofile"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog"
+)
+
+// syncAttempts bounds the merges retried when the profile is changed concurrently.
+const syncAttempts = 3
+
+var errSyncConflict = errors.New("profile was modified concurrently")
+
+// handleProfileSyncPost merges the settings the device changed since its last sync into the profile
+// and returns the merged state. The merge is repeated on a fresh profile if another device
+// wrote it in between, so concurrent syncs never lose settings. The reported level and xp are
+// ignored, progress is derived from the events ledger.
+func handleProfileSyncPost(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
+	req := api.MustGetBody[applingoapi.RequestPostProfileSyncV1](ctx)
+	if handleErr := authorizeProfile(ctx, req.Id); handleErr != nil {
+		return nil, handleErr
+	}
+
+	var changes []applingoapi.ProfileSettingItemV1
+	if req.Settings != nil {
+		changes = *req.Settings
+	}
+
+	for range syncAttempts {
+		profile, handleErr := getProfile(ctx, req.Id)
+		if handleErr != nil {
+			return nil, handleErr
+		}
+		stored, err := decodeSettings(profile.Settings)
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+
+		now := time.Now().Unix()
+		if err := stored.merge(changes, now); err != nil {
+			return nil, &api.HandleError{Status: http.StatusBadRequest, Message: err.Error(), Err: err}
+		}
+		if profile.Settings, err = stored.encode(); err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		revision := profile.Revision
+		profile.Revision++
+		profile.LastSync = int(now)
+
+		update := expression.
+			Set(expression.Name(applingoprofile.ColumnSettings), expression.Value(profile.Settings)).
+			Set(expression.Name(applingoprofile.ColumnLastSync), expression.Value(profile.LastSync)).
+			Set(expression.Name(applingoprofile.ColumnRevision), expression.Value(profile.Revision))
+		if err := dbDynamo.Update(ctx, applingoprofile.TableSchema.TableName, profileKey(req.Id), update, revisionCondition(revision)); err != nil {
+			var conditionErr *types.ConditionalCheckFailedException
+			if errors.As(err, &conditionErr) {
+				continue
+			}
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+
+		data, err := profileData(profile)
+		if err != nil {
+			return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+		}
+		return &api.Response{Status: http.StatusOK, Body: openapi.DataResponseProfile(data)}, nil
+	}
+	return nil, &api.HandleError{Status: http.StatusConflict, Message: "profile is being synced, retry", Err: errSyncConflict}
+}
@@ -0,0 +1,92 @@
+// Package handler implements the profile API routes.
+// It is served by the api-profile Lambda and by the local server.
+package handler
+
+import (
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/achievement"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+)
+
+var (
+	validate     = validator.New()
+	dbDynamo     cloud.DynamoAPI
+	achievements *achievement.Source
+)
+
+// Config holds the handler dependencies.
+type Config struct {
+	Dynamo cloud.DynamoAPI
+	// Achievements is the catalog of achievements, none are unlocked if it is nil.
+	Achievements *achievement.Source
+}
+
+// Routes sets up the handler dependencies and returns the routes map.
+func Routes(cfg Config) map[string]api.HandleFunc {
+	dbDynamo = cfg.Dynamo
+	achievements = cfg.Achievements
+
+	return map[string]api.HandleFunc{
+		// get profile data
+		"GET:/v1/profile": api.Chain(
+			handleProfileGet,
+			api.WithPermissions(auth.Device),
+		),
+
+		// create profile
+		"POST:/v1/profile": api.Chain(
+			handleProfilePost,
+			api.WithDevice(),
+			api.WithJSONBody[applingoapi.RequestPostProfileV1](validate),
+		),
+
+		// patch profile data
+		"PATCH:/v1/profile": api.Chain(
+			handleProfilePatch,
+			api.WithPermissions(auth.Device),
+			api.WithJSONBody[applingoapi.RequestPatchProfileV1](validate),
+		),
+
+		// merge device changes into profile
+		"POST:/v1/profile/sync": api.Chain(
+			handleProfileSyncPost,
+			api.WithPermissions(auth.Device),
+			api.WithJSONBody[applingoapi.RequestPostProfileSyncV1](validate),
+		),
+
+		// append learning events to the ledger
+		"POST:/v1/profile/events": api.Chain(
+			handleProfileEventsPost,
+			api.WithPermissions(auth.Device),
+			api.WithJSONBody[applingoapi.RequestPostProfileEventsV1](validate),
+		),
+
+		// get streak and achievements
+		"GET:/v1/profile/achievements": api.Chain(
+			handleProfileAchievementsGet,
+			api.WithPermissions(auth.Device),
+		),
+
+		// get weekly or all time XP leaderboard
+		"GET:/v1/profile/leaderboard": api.Chain(
+			handleProfileLeaderboardGet,
+			api.WithPermissions(auth.Device),
+		),
+
+		// get cards due for a review
+		"GET:/v1/profile/progress": api.Chain(
+			handleProfileProgressGet,
+			api.WithPermissions(auth.Device),
+		),
+
+		// apply device reviews to the cards
+		"POST:/v1/profile/progress": api.Chain(
+			handleProfileProgressPost,
+			api.WithPermissions(auth.Device),
+			api.WithJSONBody[applingoapi.RequestPostProfileProgressV1](validate),
+		),
+	}
+}
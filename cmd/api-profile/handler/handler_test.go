@@ -0,0 +1,753 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodevice"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoleaderboard"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoledger"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprofile"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprogress"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/achievement"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/leaderboard"
+	"github.com/Mad-Pixels/applingo-api/pkg/ledger"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCatalog = `{"achievements":[
+	{"id":"first_session","name":"First session","metric":"sessions_completed","target":1,"freezes":1},
+	{"id":"streak_2","name":"Two days","metric":"streak","target":2},
+	{"id":"b1_reader","name":"B1 reader","metric":"dictionaries_finished","level":"B1","target":1},
+	{"id":"polyglot","name":"Polyglot","metric":"language_pairs","target":2}
+]}`
+
+func newTestAPI(t *testing.T) *api.API {
+	t.Helper()
+
+	db := cloud.NewMemoryDynamo(
+		cloud.NewMemoryTable(applingoprofile.TableSchema),
+		cloud.NewMemoryTable(applingoprogress.TableSchema),
+		cloud.NewMemoryTable(applingoledger.TableSchema),
+		cloud.NewMemoryTable(applingodictionary.TableSchema),
+		cloud.NewMemoryTable(applingoleaderboard.TableSchema),
+		cloud.NewMemoryTable(applingodevice.TableSchema),
+	)
+	return api.NewLambda(api.Config{}, Routes(Config{
+		Dynamo:       db,
+		Achievements: achievement.NewSource(achievement.StaticCatalog(testCatalog), time.Hour),
+	}))
+}
+
+// testProfile is the profile the test requests are made for, unless asProfile changes it.
+const testProfile = "device-1"
+
+func request(method string, kind auth.Kind, role auth.Role, body string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		HTTPMethod: method,
+		Path:       "/v1/profile",
+		Body:       body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			HTTPMethod: method,
+			Authorizer: map[string]any{
+				"kind":       strconv.Itoa(int(kind)),
+				"role":       strconv.Itoa(int(role)),
+				"identifier": testProfile,
+			},
+		},
+	}
+}
+
+// asProfile makes the request on behalf of another profile, an empty id is a device signing with the shared token.
+func asProfile(req events.APIGatewayProxyRequest, id string) events.APIGatewayProxyRequest {
+	req.RequestContext.Authorizer["identifier"] = id
+	return req
+}
+
+func patchProfile(t *testing.T, a *api.API, body string) (int, applingoapi.ProfileData) {
+	t.Helper()
+
+	resp, err := a.Handle(context.Background(), request(http.MethodPatch, auth.HMAC, auth.Device, body))
+	require.NoError(t, err)
+
+	var out struct {
+		Data applingoapi.ProfileData `json:"data"`
+	}
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	}
+	return resp.StatusCode, out.Data
+}
+
+// registerDevice adds an active device to the profile.
+func registerDevice(t *testing.T, profileID string) {
+	t.Helper()
+
+	item, err := applingodevice.PutItem(applingodevice.SchemaItem{Id: uuid.New().String(), ProfileId: profileID})
+	require.NoError(t, err)
+	require.NoError(t, dbDynamo.Put(context.Background(), applingodevice.TableSchema.TableName, item, expression.ConditionBuilder{}))
+}
+
+func TestProfilePost(t *testing.T) {
+	a := newTestAPI(t)
+
+	resp, err := a.Handle(context.Background(), request(http.MethodPost, auth.HMAC, auth.Device, `{"id":"device-1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, err = a.Handle(context.Background(), request(http.MethodPost, auth.HMAC, auth.Device, `{"id":"device-1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, err = a.Handle(context.Background(), request(http.MethodPost, auth.JWT, auth.User, `{"id":"device-2"}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestProfilePatch(t *testing.T) {
+	a := newTestAPI(t)
+
+	status, _ := patchProfile(t, a, `{"id":"device-1"}`)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	resp, err := a.Handle(context.Background(), request(http.MethodPost, auth.HMAC, auth.Device, `{"id":"device-1"}`))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	status, data := patchProfile(t, a, `{"id":"device-1"}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, int64(1), data.Level)
+	assert.Zero(t, data.Xp)
+
+	// reported progress is ignored, it is earned with events.
+	for _, body := range []string{`{"id":"device-1","level":99,"xp":40}`, `{"id":"device-1","xp":0}`} {
+		status, data = patchProfile(t, a, body)
+		require.Equal(t, http.StatusOK, status, body)
+		assert.Equal(t, int64(1), data.Level, body)
+		assert.Zero(t, data.Xp, body)
+	}
+
+	resp, err = a.Handle(context.Background(), asProfile(request(http.MethodPatch, auth.HMAC, auth.Device, `{"id":"device-1"}`), "device-2"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// the shared token reaches the profile until a device of it is registered.
+	shared := asProfile(request(http.MethodPatch, auth.HMAC, auth.Device, `{"id":"device-1"}`), "")
+	resp, err = a.Handle(context.Background(), shared)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	registerDevice(t, "device-1")
+	resp, err = a.Handle(context.Background(), shared)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func syncProfile(t *testing.T, a *api.API, body string) (int, applingoapi.ProfileData) {
+	t.Helper()
+
+	req := request(http.MethodPost, auth.HMAC, auth.Device, body)
+	req.Path = "/v1/profile/sync"
+	resp, err := a.Handle(context.Background(), req)
+	require.NoError(t, err)
+
+	var out struct {
+		Data applingoapi.ProfileData `json:"data"`
+	}
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	}
+	return resp.StatusCode, out.Data
+}
+
+func getProfileData(t *testing.T, a *api.API, id string) (int, applingoapi.ProfileData) {
+	t.Helper()
+
+	req := request(http.MethodGet, auth.HMAC, auth.Device, "")
+	req.QueryStringParameters = map[string]string{"id": id}
+	resp, err := a.Handle(context.Background(), req)
+	require.NoError(t, err)
+
+	var out struct {
+		Data applingoapi.ProfileData `json:"data"`
+	}
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	}
+	return resp.StatusCode, out.Data
+}
+
+func TestProfileGet(t *testing.T) {
+	a := newTestAPI(t)
+
+	status, _ := getProfileData(t, a, "device-1")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	resp, err := a.Handle(context.Background(), request(http.MethodPost, auth.HMAC, auth.Device, `{"id":"device-1"}`))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	status, data := getProfileData(t, a, "device-1")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, int64(1), data.Level)
+	assert.Zero(t, data.Xp)
+	assert.Nil(t, data.LastSync)
+	require.NotNil(t, data.Settings)
+	assert.Empty(t, *data.Settings)
+
+	status, _ = getProfileData(t, a, "")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// the profile is read by its own devices and user only.
+	req := asProfile(request(http.MethodGet, auth.HMAC, auth.Device, ""), "device-2")
+	req.QueryStringParameters = map[string]string{"id": "device-1"}
+	resp, err = a.Handle(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	req = asProfile(request(http.MethodGet, auth.JWT, auth.User, ""), "device-1")
+	req.QueryStringParameters = map[string]string{"id": "device-1"}
+	resp, err = a.Handle(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// the shared token reaches the profile until a device of it is registered.
+	shared := asProfile(request(http.MethodGet, auth.HMAC, auth.Device, ""), "")
+	shared.QueryStringParameters = map[string]string{"id": "device-1"}
+	resp, err = a.Handle(context.Background(), shared)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	registerDevice(t, "device-1")
+	resp, err = a.Handle(context.Background(), shared)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestProfileSync(t *testing.T) {
+	a := newTestAPI(t)
+
+	status, _ := syncProfile(t, a, `{"id":"device-1","level":1,"xp":0}`)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	resp, err := a.Handle(context.Background(), request(http.MethodPost, auth.HMAC, auth.Device, `{"id":"device-1"}`))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	future := time.Now().Add(time.Hour).Unix()
+
+	// the first device changes the settings, its reported progress is ignored.
+	status, data := syncProfile(t, a, `{"id":"device-1","level":3,"xp":20,"settings":[
+		{"key":"theme","value":"dark","updated":100},
+		{"key":"sound","value":"off","updated":100}
+	]}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, int64(1), data.Level)
+	assert.Zero(t, data.Xp)
+	require.NotNil(t, data.LastSync)
+	assert.InDelta(t, time.Now().Unix(), *data.LastSync, 5)
+
+	// one of the settings of the second device is newer and one is older.
+	status, data = syncProfile(t, a, `{"id":"device-1","level":2,"xp":90,"settings":[
+		{"key":"theme","value":"light","updated":200},
+		{"key":"sound","value":"on","updated":50},
+		{"key":"language","value":"en","updated":`+strconv.FormatInt(future, 10)+`}
+	]}`)
+	require.Equal(t, http.StatusOK, status)
+	require.NotNil(t, data.Settings)
+	settings := map[string]applingoapi.ProfileSettingItemV1{}
+	for _, s := range *data.Settings {
+		settings[s.Key] = s
+	}
+	assert.Equal(t, "light", settings["theme"].Value)
+	assert.Equal(t, "off", settings["sound"].Value)
+	assert.Equal(t, "en", settings["language"].Value)
+	assert.Less(t, settings["language"].Updated, future, "changes from the future are dated now")
+
+	// an older change of the first device does not override the newer one.
+	status, data = syncProfile(t, a, `{"id":"device-1","level":3,"xp":25,"settings":[
+		{"key":"language","value":"ru","updated":300}
+	]}`)
+	require.Equal(t, http.StatusOK, status)
+
+	status, data = getProfileData(t, a, "device-1")
+	require.Equal(t, http.StatusOK, status)
+	require.NotNil(t, data.Settings)
+	require.Len(t, *data.Settings, 3)
+	assert.Equal(t, "language", (*data.Settings)[0].Key)
+	assert.Equal(t, "en", (*data.Settings)[0].Value)
+
+	items := make([]string, 0, maxSettings+1)
+	for i := range maxSettings + 1 {
+		items = append(items, `{"key":"key-`+strconv.Itoa(i)+`","value":"v","updated":1}`)
+	}
+	status, _ = syncProfile(t, a, `{"id":"device-1","level":1,"xp":0,"settings":[`+strings.Join(items, ",")+`]}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = syncProfile(t, a, `{"id":"device-1","level":1,"xp":0,"settings":[{"key":"theme","value":"dark","updated":0}]}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// a device of another profile cannot change the settings.
+	req := asProfile(request(http.MethodPost, auth.HMAC, auth.Device, `{"id":"device-1","level":1,"xp":0,"settings":[{"key":"theme","value":"light","updated":400}]}`), "device-2")
+	req.Path = "/v1/profile/sync"
+	resp, err = a.Handle(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+const (
+	dictionaryA = "0123456789abcdef0123456789abcdef"
+	dictionaryB = "fedcba9876543210fedcba9876543210"
+)
+
+func progressRequest(t *testing.T, a *api.API, method, body string, query map[string]string) (int, applingoapi.ProgressData) {
+	t.Helper()
+
+	req := request(method, auth.HMAC, auth.Device, body)
+	req.Path = "/v1/profile/progress"
+	req.QueryStringParameters = query
+	resp, err := a.Handle(context.Background(), req)
+	require.NoError(t, err)
+
+	var out struct {
+		Data applingoapi.ProgressData `json:"data"`
+	}
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	}
+	return resp.StatusCode, out.Data
+}
+
+func TestProfileProgress(t *testing.T) {
+	a := newTestAPI(t)
+	now := time.Now().Unix()
+	day := int64(24 * time.Hour / time.Second)
+
+	body, err := serializer.MarshalJSON(applingoapi.RequestPostProfileProgressV1{
+		Id: "device-1",
+		Reviews: []applingoapi.ProgressReviewItemV1{
+			{DictionaryId: dictionaryA, Word: "cat", Grade: 3, Reviewed: now - 9*day},
+			{DictionaryId: dictionaryA, Word: "dog", Grade: 3, Reviewed: now - 100},
+			{DictionaryId: dictionaryB, Word: "sol", Grade: 1, Reviewed: now - 3600},
+			{DictionaryId: dictionaryA, Word: "cat", Grade: 3, Reviewed: now - 10*day},
+		},
+	})
+	require.NoError(t, err)
+	reviews := string(body)
+
+	status, _ := progressRequest(t, a, http.MethodPost, reviews, nil)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	resp, err := a.Handle(context.Background(), request(http.MethodPost, auth.HMAC, auth.Device, `{"id":"device-1"}`))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	status, progress := progressRequest(t, a, http.MethodPost, reviews, nil)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, progress.Items, 3)
+	cat := progress.Items[0]
+	assert.Equal(t, "cat", cat.Word)
+	assert.Equal(t, 2, cat.Reps)
+	assert.Equal(t, 6, cat.Interval)
+	assert.Equal(t, now-3*day, cat.Due)
+
+	// a resent batch is not counted twice.
+	status, progress = progressRequest(t, a, http.MethodPost, reviews, nil)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, cat, progress.Items[0])
+
+	status, progress = progressRequest(t, a, http.MethodGet, "", map[string]string{"id": "device-1"})
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, progress.Items, 2)
+	assert.Equal(t, "cat", progress.Items[0].Word)
+	assert.Equal(t, "sol", progress.Items[1].Word)
+	assert.Equal(t, 0, progress.Items[1].Interval)
+
+	status, progress = progressRequest(t, a, http.MethodGet, "", map[string]string{"id": "device-1", "dictionary_id": dictionaryB})
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, progress.Items, 1)
+	assert.Equal(t, "sol", progress.Items[0].Word)
+
+	status, progress = progressRequest(t, a, http.MethodGet, "", map[string]string{"id": "device-1", "limit": "1"})
+	require.Equal(t, http.StatusOK, status)
+	assert.Len(t, progress.Items, 1)
+
+	status, _ = progressRequest(t, a, http.MethodGet, "", map[string]string{"id": "device-1", "dictionary_id": "dict"})
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = progressRequest(t, a, http.MethodPost, `{"id":"device-1","reviews":[{"dictionary_id":"`+dictionaryA+`","word":"cat","grade":5,"reviewed":1}]}`, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = progressRequest(t, a, http.MethodPost, `{"id":"device-1","reviews":[]}`, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// the progress of another profile is neither read nor written.
+	status, _ = progressRequest(t, a, http.MethodGet, "", map[string]string{"id": "device-2"})
+	assert.Equal(t, http.StatusForbidden, status)
+	status, _ = progressRequest(t, a, http.MethodPost, strings.Replace(reviews, `"device-1"`, `"device-2"`, 1), nil)
+	assert.Equal(t, http.StatusForbidden, status)
+}
+
+func TestProfileProgressBatch(t *testing.T) {
+	a := newTestAPI(t)
+	now := time.Now().Unix()
+
+	resp, err := a.Handle(context.Background(), request(http.MethodPost, auth.HMAC, auth.Device, `{"id":"device-1"}`))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	req := applingoapi.RequestPostProfileProgressV1{Id: "device-1"}
+	for i := range maxCards {
+		req.Reviews = append(req.Reviews, applingoapi.ProgressReviewItemV1{
+			DictionaryId: dictionaryA, Word: fmt.Sprintf("word-%03d", i), Grade: 3, Reviewed: now - 100,
+		})
+	}
+	body, err := serializer.MarshalJSON(req)
+	require.NoError(t, err)
+	reviews := string(body)
+
+	status, progress := progressRequest(t, a, http.MethodPost, reviews, nil)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, progress.Items, maxCards)
+	for i, item := range progress.Items {
+		assert.Equal(t, fmt.Sprintf("word-%03d", i), item.Word)
+		assert.Equal(t, 1, item.Reps)
+	}
+
+	status, progress = progressRequest(t, a, http.MethodPost, reviews, nil)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 1, progress.Items[maxCards-1].Reps)
+
+	// dictionaries studied in one request are recorded in as many transactions as they need.
+	req.Reviews = req.Reviews[:0]
+	for i := range maxStudied + 1 {
+		req.Reviews = append(req.Reviews, applingoapi.ProgressReviewItemV1{
+			DictionaryId: fmt.Sprintf("%032x", i), Word: "cat", Grade: 3, Reviewed: now - 100,
+		})
+	}
+	body, err = serializer.MarshalJSON(req)
+	require.NoError(t, err)
+	status, _ = progressRequest(t, a, http.MethodPost, string(body), nil)
+	require.Equal(t, http.StatusOK, status)
+
+	profile, handleErr := getProfile(context.Background(), "device-1")
+	require.Nil(t, handleErr)
+	stats, err := decodeStats(profile.Stats)
+	require.NoError(t, err)
+	assert.Equal(t, maxStudied+2, stats[achievement.MetricDictionariesStudied])
+}
+
+func postEvents(t *testing.T, a *api.API, id string, items ...applingoapi.ProfileEventItemV1) (int, applingoapi.ProfileEventsData) {
+	t.Helper()
+
+	body, err := serializer.MarshalJSON(applingoapi.RequestPostProfileEventsV1{Id: id, Events: items})
+	require.NoError(t, err)
+	req := asProfile(request(http.MethodPost, auth.HMAC, auth.Device, string(body)), id)
+	req.Path = "/v1/profile/events"
+	resp, err := a.Handle(context.Background(), req)
+	require.NoError(t, err)
+
+	var out struct {
+		Data applingoapi.ProfileEventsData `json:"data"`
+	}
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	}
+	return resp.StatusCode, out.Data
+}
+
+func event(kind applingoapi.ProfileEventTypeEnum, words, duration int) applingoapi.ProfileEventItemV1 {
+	return applingoapi.ProfileEventItemV1{
+		Id:       uuid.New().String(),
+		Type:     kind,
+		Occurred: time.Now().Add(-time.Minute).Unix(),
+		Words:    &words,
+		Duration: &duration,
+	}
+}
+
+func finished(dictionaryID string) applingoapi.ProfileEventItemV1 {
+	item := event("dictionary_finished", 0, 0)
+	item.DictionaryId = &dictionaryID
+	return item
+}
+
+func TestProfileEvents(t *testing.T) {
+	a := newTestAPI(t)
+
+	status, _ := postEvents(t, a, "device-1", event("session_completed", 0, 600))
+	assert.Equal(t, http.StatusNotFound, status)
+
+	resp, err := a.Handle(context.Background(), request(http.MethodPost, auth.HMAC, auth.Device, `{"id":"device-1"}`))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	session, words := event("session_completed", 0, 600), event("words_reviewed", 20, 120)
+	batch := []applingoapi.ProfileEventItemV1{session, words, event("words_reviewed", 100, 30), finished(dictionaryA), session}
+	status, data := postEvents(t, a, "device-1", batch...)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, data.Events, 5)
+	assert.Equal(t, ledger.SessionXP, data.Events[0].Xp)
+	assert.Equal(t, 20, data.Events[1].Xp)
+	assert.Zero(t, data.Events[2].Xp)
+	assert.Contains(t, *data.Events[2].Reason, "cannot be reviewed")
+	assert.Zero(t, data.Events[3].Xp)
+	assert.Equal(t, reasonNotReviewed, *data.Events[3].Reason)
+	assert.Equal(t, reasonDuplicateSent, *data.Events[4].Reason)
+	assert.Equal(t, int64(1), data.Profile.Level)
+	assert.Equal(t, int64(30), data.Profile.Xp)
+
+	// resent events are reported with the stored result and counted once.
+	status, data = postEvents(t, a, "device-1", session, words)
+	require.Equal(t, http.StatusOK, status)
+	require.NotNil(t, data.Events[0].Duplicate)
+	assert.Equal(t, ledger.SessionXP, data.Events[0].Xp)
+	assert.Equal(t, int64(30), *data.Profile.XpTotal)
+
+	// a dictionary with reviewed words is finished once.
+	status, _ = progressRequest(t, a, http.MethodPost, `{"id":"device-1","reviews":[{"dictionary_id":"`+dictionaryA+`","word":"cat","grade":3,"reviewed":1}]}`, nil)
+	require.Equal(t, http.StatusOK, status)
+	status, data = postEvents(t, a, "device-1", finished(dictionaryA))
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, ledger.DictionaryXP, data.Events[0].Xp)
+	status, data = postEvents(t, a, "device-1", finished(dictionaryA))
+	require.Equal(t, http.StatusOK, status)
+	assert.NotNil(t, data.Events[0].Duplicate)
+	assert.Equal(t, int64(80), *data.Profile.XpTotal)
+
+	// XP above the daily limit is not awarded.
+	batch = batch[:0]
+	for range 5 {
+		batch = append(batch, event("words_reviewed", ledger.MaxWords, 3600))
+	}
+	status, data = postEvents(t, a, "device-1", batch...)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, ledger.DailyLimit-80-4*ledger.MaxWords, data.Events[4].Xp)
+	assert.Equal(t, reasonDailyLimit, *data.Events[4].Reason)
+	assert.Equal(t, int64(ledger.DailyLimit), *data.Profile.XpTotal)
+	assert.Equal(t, int64(5), data.Profile.Level)
+	assert.Zero(t, data.Profile.Xp)
+
+	total, err := ledger.Sum(context.Background(), dbDynamo, "device-1")
+	require.NoError(t, err)
+	assert.Equal(t, ledger.DailyLimit, total)
+
+	// events are not posted to the profile of another caller.
+	body, err := serializer.MarshalJSON(applingoapi.RequestPostProfileEventsV1{Id: "device-1", Events: batch})
+	require.NoError(t, err)
+	req := asProfile(request(http.MethodPost, auth.HMAC, auth.Device, string(body)), "device-2")
+	req.Path = "/v1/profile/events"
+	resp, err = a.Handle(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestProfileEventsLegacy(t *testing.T) {
+	a := newTestAPI(t)
+
+	item, err := applingoprofile.PutItem(applingoprofile.SchemaItem{Id: "device-1", Level: 3, Xp: 50})
+	require.NoError(t, err)
+	require.NoError(t, dbDynamo.Put(context.Background(), applingoprofile.TableSchema.TableName, item, expression.ConditionBuilder{}))
+
+	status, data := getProfileData(t, a, "device-1")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, int64(350), *data.XpTotal)
+
+	status, events := postEvents(t, a, "device-1", event("session_completed", 0, 600))
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, int64(3), events.Profile.Level)
+	assert.Equal(t, int64(60), events.Profile.Xp)
+
+	total, err := ledger.Sum(context.Background(), dbDynamo, "device-1")
+	require.NoError(t, err)
+	assert.Equal(t, 360, total)
+}
+
+func unlockedIDs(items *[]applingoapi.AchievementItemV1) []string {
+	if items == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(*items))
+	for _, item := range *items {
+		ids = append(ids, item.Id)
+	}
+	return ids
+}
+
+func TestProfileAchievements(t *testing.T) {
+	a := newTestAPI(t)
+	ctx := context.Background()
+
+	resp, err := a.Handle(ctx, request(http.MethodPost, auth.HMAC, auth.Device, `{"id":"device-1"}`))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	for id, subcategory := range map[string]string{dictionaryA: "en-ru", dictionaryB: "de-ru"} {
+		item, err := applingodictionary.PutItem(applingodictionary.SchemaItem{Id: id, Subcategory: subcategory, Level: "B1"})
+		require.NoError(t, err)
+		require.NoError(t, dbDynamo.Put(ctx, applingodictionary.TableSchema.TableName, item, expression.ConditionBuilder{}))
+	}
+
+	// studying dictionaries of two language pairs, a resent batch is counted once.
+	reviews := `{"id":"device-1","reviews":[` +
+		`{"dictionary_id":"` + dictionaryA + `","word":"cat","grade":3,"reviewed":1},` +
+		`{"dictionary_id":"` + dictionaryA + `","word":"dog","grade":3,"reviewed":1},` +
+		`{"dictionary_id":"` + dictionaryB + `","word":"sol","grade":3,"reviewed":1}]}`
+	status, progress := progressRequest(t, a, http.MethodPost, reviews, nil)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []string{"polyglot"}, unlockedIDs(progress.Unlocked))
+	status, progress = progressRequest(t, a, http.MethodPost, reviews, nil)
+	require.Equal(t, http.StatusOK, status)
+	assert.Nil(t, progress.Unlocked)
+	profile, handleErr := getProfile(ctx, "device-1")
+	require.Nil(t, handleErr)
+	stats, err := decodeStats(profile.Stats)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats[achievement.MetricDictionariesStudied])
+
+	yesterday := event("session_completed", 0, 600)
+	yesterday.Occurred -= int64(24 * time.Hour / time.Second)
+	status, data := postEvents(t, a, "device-1", event("session_completed", 0, 600), yesterday, finished(dictionaryA))
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []string{"first_session", "streak_2", "b1_reader"}, unlockedIDs(data.Unlocked))
+
+	status, data = postEvents(t, a, "device-1", event("session_completed", 0, 600))
+	require.Equal(t, http.StatusOK, status)
+	assert.Nil(t, data.Unlocked)
+
+	req := request(http.MethodGet, auth.HMAC, auth.Device, "")
+	req.Path = "/v1/profile/achievements"
+	req.QueryStringParameters = map[string]string{"id": "device-1"}
+	resp, err = a.Handle(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode, resp.Body)
+
+	var out struct {
+		Data applingoapi.AchievementsData `json:"data"`
+	}
+	require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	assert.Equal(t, applingoapi.ProfileStreakV1{Current: 2, Best: 2, Freezes: 1}, out.Data.Streak)
+	require.Len(t, out.Data.Items, 4)
+	for _, item := range out.Data.Items {
+		assert.NotNil(t, item.Unlocked, item.Id)
+	}
+	assert.Equal(t, 2, *out.Data.Items[3].Progress)
+
+	req.QueryStringParameters = map[string]string{"id": "device-2"}
+	resp, err = a.Handle(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+// rankEvents adds the ledger events of the profile to the leaderboards as the ledger stream does.
+func rankEvents(t *testing.T, profileID string) {
+	t.Helper()
+
+	ctx := context.Background()
+	input, err := dbDynamo.BuildQueryInput(cloud.QueryInput{
+		KeyCondition: expression.Key(applingoledger.ColumnProfileId).Equal(expression.Value(profileID)),
+		ScanForward:  true,
+	})
+	require.NoError(t, err)
+	result, err := dbDynamo.Query(ctx, applingoledger.TableSchema.TableName, input)
+	require.NoError(t, err)
+	var items []applingoledger.SchemaItem
+	require.NoError(t, attributevalue.UnmarshalListOfMaps(result.Items, &items))
+	for _, item := range items {
+		_, err := leaderboard.Add(ctx, dbDynamo, item, time.Now())
+		require.NoError(t, err)
+	}
+}
+
+func getLeaderboard(t *testing.T, a *api.API, query map[string]string) (int, applingoapi.LeaderboardData) {
+	t.Helper()
+
+	req := asProfile(request(http.MethodGet, auth.HMAC, auth.Device, ""), query["id"])
+	req.Path = "/v1/profile/leaderboard"
+	req.QueryStringParameters = query
+	resp, err := a.Handle(context.Background(), req)
+	require.NoError(t, err)
+
+	var out struct {
+		Data applingoapi.LeaderboardData `json:"data"`
+	}
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	}
+	return resp.StatusCode, out.Data
+}
+
+func TestProfileLeaderboard(t *testing.T) {
+	a := newTestAPI(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"device-1", "device-2"} {
+		resp, err := a.Handle(ctx, request(http.MethodPost, auth.HMAC, auth.Device, `{"id":"`+id+`"}`))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+	item, err := applingodictionary.PutItem(applingodictionary.SchemaItem{Id: dictionaryA, Subcategory: "en-ru", Level: "B1"})
+	require.NoError(t, err)
+	require.NoError(t, dbDynamo.Put(ctx, applingodictionary.TableSchema.TableName, item, expression.ConditionBuilder{}))
+
+	// words of a dictionary count in the leaderboards of its language pair.
+	words, dictionaryID := event("words_reviewed", 30, 300), dictionaryA
+	words.DictionaryId = &dictionaryID
+	status, _ := postEvents(t, a, "device-1", words)
+	require.Equal(t, http.StatusOK, status)
+	status, _ = postEvents(t, a, "device-2", event("words_reviewed", 50, 300))
+	require.Equal(t, http.StatusOK, status)
+	rankEvents(t, "device-1")
+	rankEvents(t, "device-2")
+
+	status, data := getLeaderboard(t, a, map[string]string{"id": "device-1", "limit": "1"})
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, applingoapi.Weekly, data.Period)
+	require.NotNil(t, data.Week)
+	assert.Equal(t, leaderboard.Week(time.Now()), *data.Week)
+	assert.Equal(t, []applingoapi.LeaderboardItemV1{{Rank: 1, Player: leaderboard.Player("device-2"), Xp: 50}}, data.Items)
+	require.NotNil(t, data.Me.Rank)
+	assert.Equal(t, 2, *data.Me.Rank)
+	assert.Equal(t, 30, data.Me.Xp)
+	assert.Equal(t, leaderboard.Player("device-1"), data.Me.Player)
+
+	status, data = getLeaderboard(t, a, map[string]string{"id": "device-1", "period": "all_time", "subcategory": "en-ru"})
+	require.Equal(t, http.StatusOK, status)
+	assert.Nil(t, data.Week)
+	require.Len(t, data.Items, 1)
+	require.NotNil(t, data.Items[0].Me)
+	assert.True(t, *data.Items[0].Me)
+	require.NotNil(t, data.Me.Rank)
+	assert.Equal(t, 1, *data.Me.Rank)
+
+	// a profile without XP on the board has no rank.
+	status, data = getLeaderboard(t, a, map[string]string{"id": "device-2", "subcategory": "en-ru"})
+	require.Equal(t, http.StatusOK, status)
+	assert.Nil(t, data.Me.Rank)
+	assert.Zero(t, data.Me.Xp)
+
+	status, _ = getLeaderboard(t, a, map[string]string{"id": "device-1", "period": "monthly"})
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// the rank of another profile is not revealed.
+	req := request(http.MethodGet, auth.HMAC, auth.Device, "")
+	req.Path = "/v1/profile/leaderboard"
+	req.QueryStringParameters = map[string]string{"id": "device-2"}
+	resp, err := a.Handle(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprofile"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/device"
+	"github.com/Mad-Pixels/applingo-api/pkg/ledger"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxSettings bounds the number of settings kept in a profile.
+const maxSettings = 64
+
+// setting is a stored profile setting with the device time of its last change.
+type setting struct {
+	Value   string `json:"value"`
+	Updated int64  `json:"updated"`
+}
+
+// settings are stored in the profile as a JSON object by key.
+type settings map[string]setting
+
+func profileKey(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		applingoprofile.ColumnId: &types.AttributeValueMemberS{Value: id},
+	}
+}
+
+// authorizeProfile returns a 403 error unless the caller is a registered device of the profile
+// or its user, devices signing with the shared token have no identifier and reach no profile here.
+func authorizeProfile(ctx context.Context, id string) *api.HandleError {
+	if owner := api.MustGetMetaData(ctx).GetIdentifier(); owner == "" || owner != id {
+		return &api.HandleError{Status: http.StatusForbidden, Err: errors.New("profile of another caller")}
+	}
+	return nil
+}
+
+// authorizeLegacyProfile is authorizeProfile for the endpoints of the clients before device registration.
+// Devices signing with the shared token reach a profile until its first device is registered, then the
+// profile is reached only with the credentials of its devices.
+func authorizeLegacyProfile(ctx context.Context, id string) *api.HandleError {
+	meta := api.MustGetMetaData(ctx)
+	if !meta.IsDevice() || meta.GetIdentifier() != "" {
+		return authorizeProfile(ctx, id)
+	}
+
+	registered, err := device.Registered(ctx, dbDynamo, id)
+	if err != nil {
+		return &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	if registered {
+		return &api.HandleError{
+			Status:  http.StatusForbidden,
+			Message: "profile has a registered device, sign the request with its credentials",
+			Err:     errors.New("shared token for a profile with devices"),
+		}
+	}
+	return nil
+}
+
+// getProfile returns the profile or a 404 error if it does not exist.
+func getProfile(ctx context.Context, id string) (applingoprofile.SchemaItem, *api.HandleError) {
+	var profile applingoprofile.SchemaItem
+
+	out, err := dbDynamo.Get(ctx, applingoprofile.TableSchema.TableName, profileKey(id))
+	if err != nil {
+		return profile, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	if out == nil || len(out.Item) == 0 {
+		return profile, &api.HandleError{Status: http.StatusNotFound, Err: errors.New("item not found")}
+	}
+	if err := attributevalue.UnmarshalMap(out.Item, &profile); err != nil {
+		return profile, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+	return profile, nil
+}
+
+// profileTotal returns the XP of the profile, a profile without ledger events
+// keeps the progress its devices reported before the ledger.
+func profileTotal(profile applingoprofile.SchemaItem) int {
+	if profile.XpTotal > 0 {
+		return profile.XpTotal
+	}
+	return ledger.Total(profile.Level, profile.Xp)
+}
+
+func decodeSettings(raw string) (settings, error) {
+	stored := settings{}
+	if raw == "" {
+		return stored, nil
+	}
+	if err := serializer.UnmarshalJSON([]byte(raw), &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode profile settings: %w", err)
+	}
+	return stored, nil
+}
+
+// merge applies the device changes, the latest change of a setting wins and the stored one wins a tie.
+// Changes dated after now are taken as made now, so a device with a clock ahead cannot pin a setting.
+func (s settings) merge(changes []applingoapi.ProfileSettingItemV1, now int64) error {
+	for _, change := range changes {
+		updated := min(change.Updated, now)
+		if current, ok := s[change.Key]; ok && current.Updated >= updated {
+			continue
+		}
+		s[change.Key] = setting{Value: change.Value, Updated: updated}
+	}
+	if len(s) > maxSettings {
+		return fmt.Errorf("profile cannot keep more than %d settings", maxSettings)
+	}
+	return nil
+}
+
+func (s settings) encode() (string, error) {
+	if len(s) == 0 {
+		return "", nil
+	}
+	data, err := serializer.MarshalJSON(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode profile settings: %w", err)
+	}
+	return string(data), nil
+}
+
+func profileData(profile applingoprofile.SchemaItem) (applingoapi.ProfileData, error) {
+	stored, err := decodeSettings(profile.Settings)
+	if err != nil {
+		return applingoapi.ProfileData{}, err
+	}
+
+	items := make([]applingoapi.ProfileSettingItemV1, 0, len(stored))
+	for key, s := range stored {
+		items = append(items, applingoapi.ProfileSettingItemV1{Key: key, Value: s.Value, Updated: s.Updated})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+
+	total := int64(profileTotal(profile))
+	data := applingoapi.ProfileData{
+		Level:    applingoapi.BaseNumberOptional(profile.Level),
+		Xp:       applingoapi.BaseNumberOptional(profile.Xp),
+		XpTotal:  &total,
+		Settings: &items,
+	}
+	if profile.LastSync > 0 {
+		lastSync := int64(profile.LastSync)
+		data.LastSync = &lastSync
+	}
+	return data, nil
+}
+
+// revisionCondition matches the profile at the expected revision, profiles created before revisions are at 0.
+func revisionCondition(revision int) expression.ConditionBuilder {
+	current := expression.Name(applingoprofile.ColumnRevision).Equal(expression.Value(revision))
+	if revision == 0 {
+		current = expression.Or(expression.AttributeNotExists(expression.Name(applingoprofile.ColumnRevision)), current)
+	}
+	return expression.AttributeExists(expression.Name(applingoprofile.ColumnId)).And(current)
+}
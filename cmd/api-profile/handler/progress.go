@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprogress"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/srs"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// defaultDueLimit is the number of due cards returned when the device does not ask for a limit.
+	defaultDueLimit = 50
+	// maxStudied bounds the dictionaries recorded in one transaction, the update of the profile is its last write.
+	maxStudied = cloud.MaxTransactItems - 1
+	// maxCards bounds the cards written in one transaction, a request carries at most as many reviews.
+	maxCards = 100
+)
+
+var errProgressConflict = errors.New("card was reviewed concurrently")
+
+func progressKey(profileID, dictionaryID, word string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		applingoprogress.ColumnProfileId:        &types.AttributeValueMemberS{Value: profileID},
+		applingoprogress.ColumnDictionaryIdWord: &types.AttributeValueMemberS{Value: applingoprogress.ComposeDictionaryIdWord(dictionaryID, word)},
+	}
+}
+
+// getProgress returns the stored cards of the reviews by their key, a card of a word which was
+// never reviewed is created and reported as not found.
+func getProgress(
+	ctx context.Context,
+	profileID string,
+	cards map[string][]applingoapi.ProgressReviewItemV1,
+	keys []string,
+) (items map[string]applingoprogress.SchemaItem, found map[string]bool, err error) {
+	avKeys := make([]map[string]types.AttributeValue, 0, len(keys))
+	for _, key := range keys {
+		avKeys = append(avKeys, progressKey(profileID, cards[key][0].DictionaryId, cards[key][0].Word))
+	}
+	out, err := dbDynamo.BatchGet(ctx, applingoprogress.TableSchema.TableName, avKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items = make(map[string]applingoprogress.SchemaItem, len(keys))
+	found = make(map[string]bool, len(out))
+	for _, av := range out {
+		var item applingoprogress.SchemaItem
+		if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+			return nil, nil, err
+		}
+		items[item.DictionaryIdWord], found[item.DictionaryIdWord] = item, true
+	}
+	for _, key := range keys {
+		if found[key] {
+			continue
+		}
+		items[key] = applingoprogress.NewSchemaItem(applingoprogress.SchemaItem{
+			ProfileId:    profileID,
+			DictionaryId: cards[key][0].DictionaryId,
+			Word:         cards[key][0].Word,
+		})
+	}
+	return items, found, nil
+}
+
+// reviewProgress applies the reviews sorted by time to the card, reviews which are
+// already counted are skipped. It reports whether the card was changed.
+func reviewProgress(item *applingoprogress.SchemaItem, reviews []applingoapi.ProgressReviewItemV1, now int64) (bool, error) {
+	card := srs.Card{
+		Ease:     item.Ease,
+		Interval: item.Interval,
+		Reps:     item.Reps,
+		Lapses:   item.Lapses,
+		Due:      int64(item.Due),
+		Reviewed: int64(item.Reviewed),
+	}
+	if card.IsNew() {
+		card = srs.NewCard()
+	}
+
+	changed := false
+	for _, review := range reviews {
+		next, err := card.Review(srs.Grade(review.Grade), time.Unix(min(review.Reviewed, now), 0))
+		if errors.Is(err, srs.ErrOutOfOrder) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		card, changed = next, true
+	}
+
+	item.Ease = card.Ease
+	item.Interval = card.Interval
+	item.Reps = card.Reps
+	item.Lapses = card.Lapses
+	item.Due = int(card.Due)
+	item.Reviewed = int(card.Reviewed)
+	return changed, nil
+}
+
+// progressCondition matches the card as it was read, a new card must still be missing.
+func progressCondition(reviewed int, found bool) expression.ConditionBuilder {
+	if !found {
+		return expression.AttributeNotExists(expression.Name(applingoprogress.ColumnProfileId))
+	}
+	return expression.Name(applingoprogress.ColumnReviewed).Equal(expression.Value(reviewed))
+}
+
+func progressItem(item applingoprogress.SchemaItem) applingoapi.ProgressCardItemV1 {
+	return applingoapi.ProgressCardItemV1{
+		DictionaryId: item.DictionaryId,
+		Word:         item.Word,
+		Ease:         item.Ease,
+		Interval:     item.Interval,
+		Reps:         item.Reps,
+		Lapses:       item.Lapses,
+		Due:          int64(item.Due),
+		Reviewed:     int64(item.Reviewed),
+	}
+}
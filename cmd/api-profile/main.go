@@ -1,5 +1,7 @@
 // Package main implements the Lambda API for managing user profiles.
-// It handles creating and updating profile records in DynamoDB,
+// It handles creating and updating profile records in DynamoDB and keeps
+// the spaced-repetition state of the words the profile studies,
+// the streaks and the achievements declared in the catalog of the forge bucket,
 // with appropriate permission checks and validation.
 package main
 
@@ -8,28 +10,32 @@ import (
 	"os"
 	"runtime/debug"
 
+	"github.com/Mad-Pixels/applingo-api/cmd/api-profile/handler"
+	"github.com/Mad-Pixels/applingo-api/pkg/achievement"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
 	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
-	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
 )
 
 var (
-	awsRegion = os.Getenv("AWS_REGION")
-	validate  *validator.Validator
-	dbDynamo  *cloud.Dynamo
+	awsRegion          = os.Getenv("AWS_REGION")
+	serviceForgeBucket = os.Getenv("SERVICE_FORGE_BUCKET")
+
+	dbDynamo *cloud.Dynamo
+	s3Bucket *cloud.Bucket
 )
 
 func init() {
 	debug.SetGCPercent(500)
-	validate = validator.New()
 
 	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
 	if err != nil {
 		panic("unable to load AWS SDK config: " + err.Error())
 	}
 	dbDynamo = cloud.NewDynamo(cfg)
+	s3Bucket = cloud.NewBucket(cfg)
 }
 
 func main() {
@@ -37,14 +43,18 @@ func main() {
 		api.NewLambda(
 			api.Config{
 				EnableRequestLogging: true,
+				Middlewares: []api.Middleware{
+					api.WithRecovery(),
+					api.WithTiming(),
+				},
 			},
-			map[string]api.HandleFunc{
-				// create profile
-				"POST:/v1/profile": handleProfilePost,
-
-				// patch profile data
-				"PATCH:/v1/profile": handleProfilePatch,
-			},
+			handler.Routes(handler.Config{
+				Dynamo: dbDynamo,
+				Achievements: achievement.NewSource(
+					achievement.BucketCatalog(s3Bucket, achievement.CatalogKey, serviceForgeBucket),
+					achievement.DefaultRefresh,
+				),
+			}),
 		).Handle,
 	)
 }
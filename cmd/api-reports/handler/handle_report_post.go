@@ -1,4 +1,4 @@
-package main
+package handler
 
 import (
 	"bytes"
@@ -17,18 +17,8 @@ import (
 	"github.com/rs/zerolog"
 )
 
-func handleReportPost(ctx context.Context, _ zerolog.Logger, raw json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
-	if !api.MustGetMetaData(ctx).IsDevice() {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
-	}
-
-	var req applingoapi.RequestPostReportV1
-	if err := serializer.UnmarshalJSON(raw, &req); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
-	}
-	if err := validate.ValidateStruct(&req); err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: err}
-	}
+func handleReportPost(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
+	req := api.MustGetBody[applingoapi.RequestPostReportV1](ctx)
 
 	var (
 		key  = time.Now().UTC().Format("logs-2006-01-02.json")
@@ -47,7 +37,7 @@ func handleReportPost(ctx context.Context, _ zerolog.Logger, raw json.RawMessage
 		}
 	}
 
-	logs = append(logs, req)
+	logs = append(logs, *req)
 	data, err := serializer.MarshalJSON(logs)
 	if err != nil {
 		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
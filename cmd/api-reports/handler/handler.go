@@ -0,0 +1,38 @@
+// Package handler implements the reports API routes.
+// It is served by the api-reports Lambda and by the local server.
+package handler
+
+import (
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+)
+
+var (
+	validate = validator.New()
+
+	s3Bucket            cloud.BucketAPI
+	serviceErrorsBucket string
+)
+
+// Config holds the handler dependencies.
+type Config struct {
+	Bucket       cloud.BucketAPI
+	ErrorsBucket string
+}
+
+// Routes sets up the handler dependencies and returns the routes map.
+func Routes(cfg Config) map[string]api.HandleFunc {
+	s3Bucket = cfg.Bucket
+	serviceErrorsBucket = cfg.ErrorsBucket
+
+	return map[string]api.HandleFunc{
+		// item
+		"POST:/v1/report": api.Chain(
+			handleReportPost,
+			api.WithDevice(),
+			api.WithJSONBody[applingoapi.RequestPostReportV1](validate),
+		),
+	}
+}
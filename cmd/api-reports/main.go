@@ -7,9 +7,9 @@ import (
 	"os"
 	"runtime/debug"
 
+	"github.com/Mad-Pixels/applingo-api/cmd/api-reports/handler"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
 	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
-	"github.com/Mad-Pixels/applingo-api/pkg/validator"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -19,13 +19,11 @@ var (
 	serviceErrorsBucket = os.Getenv("SERVICE_ERRORS_BUCKET")
 	awsRegion           = os.Getenv("AWS_REGION")
 
-	validate *validator.Validator
 	s3Bucket *cloud.Bucket
 )
 
 func init() {
 	debug.SetGCPercent(500)
-	validate = validator.New()
 
 	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
 	if err != nil {
@@ -39,11 +37,15 @@ func main() {
 		api.NewLambda(
 			api.Config{
 				EnableRequestLogging: true,
+				Middlewares: []api.Middleware{
+					api.WithRecovery(),
+					api.WithTiming(),
+				},
 			},
-			map[string]api.HandleFunc{
-				// item
-				"POST:/v1/report": handleReportPost,
-			},
+			handler.Routes(handler.Config{
+				Bucket:       s3Bucket,
+				ErrorsBucket: serviceErrorsBucket,
+			}),
 		).Handle,
 	)
 }
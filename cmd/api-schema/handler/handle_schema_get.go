@@ -1,21 +1,15 @@
-package main
+package handler
 
 import (
 	"context"
 	"encoding/json"
-	"net/http"
 
 	"github.com/Mad-Pixels/applingo-api/openapi-interface"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingospec"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
-	"github.com/Mad-Pixels/applingo-api/pkg/auth"
-	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 )
 
 func handleSchemaGet(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
-	if !api.MustGetMetaData(ctx).HasPermissions(auth.Device) {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
-	}
 	return applingospec.GetRoutes(), nil
 }
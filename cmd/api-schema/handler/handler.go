@@ -0,0 +1,16 @@
+// Package handler implements the schema API routes.
+// It is served by the api-schema Lambda and by the local server.
+package handler
+
+import (
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+)
+
+// Routes returns the routes map.
+func Routes() map[string]api.HandleFunc {
+	return map[string]api.HandleFunc{
+		// list
+		"GET:/v1/schema": api.Chain(handleSchemaGet, api.WithPermissions(auth.Device)),
+	}
+}
@@ -5,7 +5,9 @@ package main
 import (
 	"runtime/debug"
 
+	"github.com/Mad-Pixels/applingo-api/cmd/api-schema/handler"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
+
 	"github.com/aws/aws-lambda-go/lambda"
 )
 
@@ -18,11 +20,12 @@ func main() {
 		api.NewLambda(
 			api.Config{
 				EnableRequestLogging: true,
+				Middlewares: []api.Middleware{
+					api.WithRecovery(),
+					api.WithTiming(),
+				},
 			},
-			map[string]api.HandleFunc{
-				// list
-				"GET:/v1/schema": handleSchemaGet,
-			},
+			handler.Routes(),
 		).Handle,
 	)
 }
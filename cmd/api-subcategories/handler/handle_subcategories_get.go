@@ -1,32 +1,25 @@
-package main
+package handler
 
 import (
 	"context"
 	"encoding/json"
-	"net/http"
 
 	"github.com/Mad-Pixels/applingo-api/lingo-interface/types"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface"
 	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
-	"github.com/Mad-Pixels/applingo-api/pkg/auth"
 
-	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 )
 
 func handleSubcategoriesGet(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, baseParams openapi.QueryParams) (any, *api.HandleError) {
-	if !api.MustGetMetaData(ctx).HasPermissions(auth.Device) {
-		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
-	}
-
 	validSideValues := map[applingoapi.BaseSideEnum]struct{}{
 		applingoapi.Front: {},
 		applingoapi.Back:  {},
 	}
 	paramSide, err := openapi.ParseEnumParam(baseParams.GetStringPtr("side"), validSideValues)
 	if err != nil {
-		return nil, &api.HandleError{Status: http.StatusBadRequest, Err: errors.Wrap(err, "invalid value for 'side' param")}
+		return nil, api.NewParamError("side", err)
 	}
 
 	items := make([]applingoapi.SubcategoryItemV1, 0, len(types.AllLanguageCodes()))
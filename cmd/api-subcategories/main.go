@@ -5,6 +5,7 @@ package main
 import (
 	"runtime/debug"
 
+	"github.com/Mad-Pixels/applingo-api/cmd/api-subcategories/handler"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
 
 	"github.com/aws/aws-lambda-go/lambda"
@@ -19,11 +20,12 @@ func main() {
 		api.NewLambda(
 			api.Config{
 				EnableRequestLogging: true,
+				Middlewares: []api.Middleware{
+					api.WithRecovery(),
+					api.WithTiming(),
+				},
 			},
-			map[string]api.HandleFunc{
-				// list
-				"GET:/v1/subcategories": handleSubcategoriesGet,
-			},
+			handler.Routes(),
 		).Handle,
 	)
 }
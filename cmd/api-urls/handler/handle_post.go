@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/dictionary"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+func handlePost(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (any, *api.HandleError) {
+	req := api.MustGetBody[applingoapi.RequestPostUrlsV1](ctx)
+
+	switch req.Operation {
+	case "upload":
+		return handleUpload(ctx, *req)
+	case "download":
+		return handleDownload(ctx, *req)
+	default:
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Message: "invalid operation", Err: fmt.Errorf("invalid operation")}
+	}
+}
+
+// handleUpload returns a URL accepting exactly the declared file, the file is kept
+// under the prefix of the caller and checked by the validator lambda once uploaded.
+func handleUpload(ctx context.Context, req applingoapi.RequestPostUrlsV1) (any, *api.HandleError) {
+	meta := api.MustGetMetaData(ctx)
+	if meta.IsDevice() || !meta.HasPermissions(auth.User) {
+		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
+	}
+	if req.Identifier == "" || req.Size == nil || req.Sha256 == nil {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Message: "missing required fields", Err: errors.New("missing required fields")}
+	}
+	if *req.Size > dictionary.MaxFileSize {
+		return nil, &api.HandleError{
+			Status:  http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("file exceeds %d bytes", dictionary.MaxFileSize),
+			Err:     errors.New("upload size exceeds the limit"),
+		}
+	}
+	key := utils.UploadFileID(meta.GetIdentifier(), req.Identifier)
+	if key == "" {
+		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("missing user identifier")}
+	}
+
+	url, err := s3Bucket.UploadURL(ctx, key, serviceProcessingBucket, cloud.UploadPolicy{
+		ContentType:    cloud.ContentTypeJSON,
+		ContentLength:  *req.Size,
+		ChecksumSHA256: *req.Sha256,
+		Expires:        uploadTTL,
+	})
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusInternalServerError, Err: err}
+	}
+
+	return openapi.DataResponseUrls(applingoapi.UrlsData{
+		Url:       url,
+		ExpiresIn: int(uploadTTL.Seconds()),
+		Key:       &key,
+	}), nil
+}
+
+func handleDownload(ctx context.Context, req applingoapi.RequestPostUrlsV1) (any, *api.HandleError) {
+	if !api.MustGetMetaData(ctx).HasPermissions(auth.Device) {
+		return nil, &api.HandleError{Status: http.StatusForbidden, Err: errors.New("insufficient permissions")}
+	}
+	if req.Identifier == "" {
+		return nil, &api.HandleError{Status: http.StatusBadRequest, Message: "missing required fields", Err: errors.New("missing required fields")}
+	}
+	url, err := s3Bucket.DownloadURL(ctx, req.Identifier, serviceDictionaryBucket)
+	if err != nil {
+		return nil, &api.HandleError{Status: http.StatusNotFound, Err: err}
+	}
+
+	return openapi.DataResponseUrls(applingoapi.UrlsData{
+		Url:       url,
+		ExpiresIn: 15,
+	}), nil
+}
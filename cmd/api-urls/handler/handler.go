@@ -0,0 +1,53 @@
+// Package handler implements the urls API routes.
+// It is served by the api-urls Lambda and by the local server.
+package handler
+
+import (
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+)
+
+// defaultUploadTTL is the upload URL lifetime used when Config.UploadTTL is not set.
+const defaultUploadTTL = 5 * time.Minute
+
+var (
+	validate = validator.New()
+
+	s3Bucket                cloud.BucketAPI
+	serviceDictionaryBucket string
+	serviceProcessingBucket string
+	uploadTTL               time.Duration
+)
+
+// Config holds the handler dependencies.
+type Config struct {
+	Bucket           cloud.BucketAPI
+	DictionaryBucket string
+	ProcessingBucket string
+	// UploadTTL is the lifetime of upload URLs.
+	UploadTTL time.Duration
+}
+
+// Routes sets up the handler dependencies and returns the routes map.
+func Routes(cfg Config) map[string]api.HandleFunc {
+	s3Bucket = cfg.Bucket
+	serviceDictionaryBucket = cfg.DictionaryBucket
+	serviceProcessingBucket = cfg.ProcessingBucket
+	uploadTTL = cfg.UploadTTL
+	if uploadTTL <= 0 {
+		uploadTTL = defaultUploadTTL
+	}
+
+	return map[string]api.HandleFunc{
+		"POST:/v1/urls": api.Chain(
+			handlePost,
+			api.WithPermissions(auth.Device),
+			api.WithJSONBody[applingoapi.RequestPostUrlsV1](validate),
+		),
+	}
+}
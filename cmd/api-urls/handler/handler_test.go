@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testID     = "0123456789abcdef0123456789abcdef.json"
+	testSHA256 = "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+)
+
+func request(kind auth.Kind, role auth.Role, identifier, body string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		HTTPMethod: http.MethodPost,
+		Path:       "/v1/urls",
+		Body:       body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			HTTPMethod: http.MethodPost,
+			Authorizer: map[string]any{
+				"kind":       strconv.Itoa(int(kind)),
+				"role":       strconv.Itoa(int(role)),
+				"identifier": identifier,
+			},
+		},
+	}
+}
+
+func TestUpload(t *testing.T) {
+	a := api.NewLambda(api.Config{}, Routes(Config{
+		Bucket:           cloud.NewMemoryBucket(),
+		DictionaryBucket: "dictionary",
+		ProcessingBucket: "processing",
+		UploadTTL:        time.Minute,
+	}))
+
+	resp, err := a.Handle(context.Background(), request(auth.JWT, auth.User, "42",
+		`{"operation":"upload","identifier":"`+testID+`","size":128,"sha256":"`+testSHA256+`"}`))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, resp.Body)
+
+	var out struct {
+		Data applingoapi.UrlsData `json:"data"`
+	}
+	require.NoError(t, serializer.UnmarshalJSON([]byte(resp.Body), &out))
+	key := utils.UploadFileID("42", testID)
+	require.NotNil(t, out.Data.Key)
+	assert.Equal(t, key, *out.Data.Key)
+	assert.True(t, strings.HasPrefix(key, "uploads/"), key)
+	assert.Equal(t, "memory://processing/"+key, out.Data.Url)
+	assert.Equal(t, 60, out.Data.ExpiresIn)
+
+	// the key is bound to the caller, another user uploads the same identifier elsewhere.
+	assert.NotEqual(t, key, utils.UploadFileID("43", testID))
+
+	tests := []struct {
+		name   string
+		kind   auth.Kind
+		role   auth.Role
+		body   string
+		status int
+	}{
+		{"device", auth.HMAC, auth.Device, `{"operation":"upload","identifier":"` + testID + `","size":128,"sha256":"` + testSHA256 + `"}`, http.StatusForbidden},
+		{"missing checksum", auth.JWT, auth.User, `{"operation":"upload","identifier":"` + testID + `","size":128}`, http.StatusBadRequest},
+		{"missing size", auth.JWT, auth.User, `{"operation":"upload","identifier":"` + testID + `","sha256":"` + testSHA256 + `"}`, http.StatusBadRequest},
+		{"invalid checksum", auth.JWT, auth.User, `{"operation":"upload","identifier":"` + testID + `","size":128,"sha256":"checksum"}`, http.StatusBadRequest},
+		{"not a dictionary id", auth.JWT, auth.User, `{"operation":"upload","identifier":"notes.txt","size":128,"sha256":"` + testSHA256 + `"}`, http.StatusBadRequest},
+		{"too large", auth.JWT, auth.User, `{"operation":"upload","identifier":"` + testID + `","size":1048577,"sha256":"` + testSHA256 + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := a.Handle(context.Background(), request(tt.kind, tt.role, "42", tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode, resp.Body)
+		})
+	}
+}
@@ -10,9 +10,10 @@ import (
 	"os"
 	"runtime/debug"
 
+	"github.com/Mad-Pixels/applingo-api/cmd/api-urls/handler"
 	"github.com/Mad-Pixels/applingo-api/pkg/api"
 	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
-	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -21,15 +22,14 @@ import (
 var (
 	serviceDictionaryBucket = os.Getenv("SERVICE_DICTIONARY_BUCKET")
 	serviceProcessingBucket = os.Getenv("SERVICE_PROCESSING_BUCKET")
+	uploadTTLSeconds        = os.Getenv("UPLOAD_URL_TTL_SECONDS")
 	awsRegion               = os.Getenv("AWS_REGION")
 
-	validate *validator.Validator
 	s3Bucket *cloud.Bucket
 )
 
 func init() {
 	debug.SetGCPercent(500)
-	validate = validator.New()
 
 	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
 	if err != nil {
@@ -43,10 +43,17 @@ func main() {
 		api.NewLambda(
 			api.Config{
 				EnableRequestLogging: true,
+				Middlewares: []api.Middleware{
+					api.WithRecovery(),
+					api.WithTiming(),
+				},
 			},
-			map[string]api.HandleFunc{
-				"POST:/v1/urls": handlePost,
-			},
+			handler.Routes(handler.Config{
+				Bucket:           s3Bucket,
+				DictionaryBucket: serviceDictionaryBucket,
+				ProcessingBucket: serviceProcessingBucket,
+				UploadTTL:        utils.GetTimeout(uploadTTLSeconds, 0),
+			}),
 		).Handle,
 	)
 }
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodevice"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+)
+
+// DynamoDeviceRegistry is an auth.DeviceRegistry backed by the device table,
+// revoked devices are kept in the table with the is_revoked flag.
+type DynamoDeviceRegistry struct {
+	dynamo cloud.DynamoAPI
+}
+
+var _ auth.DeviceRegistry = (*DynamoDeviceRegistry)(nil)
+
+// NewDynamoDeviceRegistry creates a device registry backed by the device table.
+func NewDynamoDeviceRegistry(dynamo cloud.DynamoAPI) *DynamoDeviceRegistry {
+	return &DynamoDeviceRegistry{dynamo: dynamo}
+}
+
+// Active returns the profile id of a registered device which is not revoked.
+func (r *DynamoDeviceRegistry) Active(ctx context.Context, deviceID string) (string, error) {
+	result, err := r.dynamo.Get(ctx, applingodevice.TableSchema.TableName, map[string]types.AttributeValue{
+		applingodevice.ColumnId: &types.AttributeValueMemberS{Value: deviceID},
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get device")
+	}
+	if result.Item == nil {
+		return "", auth.ErrUnknownDevice
+	}
+
+	var device applingodevice.SchemaItem
+	if err := attributevalue.UnmarshalMap(result.Item, &device); err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal device")
+	}
+	if applingodevice.IntToBool(device.IsRevoked) {
+		return "", auth.ErrDeviceRevoked
+	}
+	if device.ProfileId == "" {
+		// devices registered before the link used the profile id as the device id.
+		return device.Id, nil
+	}
+	return device.ProfileId, nil
+}
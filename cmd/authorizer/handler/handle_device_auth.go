@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func handleDeviceAuth(timestamp string, signature string, req events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+	if minVersion > auth.SignatureV1 {
+		log.Error().Err(auth.ErrUnsupportedSignatureVersion).Str("version", auth.SignatureV1.String()).Msg("Device authentication failed")
+		return generatePolicy("", "Deny", req.MethodArn, nil)
+	}
+	if !sharedTokenAllowed(req) {
+		log.Error().Err(auth.ErrUnknownDevice).Str("version", auth.SignatureV1.String()).Msg("Device authentication failed")
+		return generatePolicy("", "Deny", req.MethodArn, nil)
+	}
+	if err := authenticator.ValidateDeviceRequest(timestamp, signature); err != nil {
+		log.Error().Err(err).Msg("Device authentication failed")
+		return generatePolicy("", "Deny", req.MethodArn, nil)
+	}
+	return generatePolicy("device", "Allow", req.MethodArn, deviceContext(auth.SignatureV1, "", ""))
+}
+
+func handleDeviceAuthV2(ctx context.Context, timestamp, nonce, signature string, req events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+	signed := auth.SignedRequest{
+		Method:    req.HTTPMethod,
+		Path:      req.Path,
+		Query:     requestQuery(req),
+		BodyHash:  req.Headers[auth.HeaderContentHash],
+		Timestamp: timestamp,
+		Nonce:     nonce,
+		Signature: signature,
+	}
+	deviceID := req.Headers[auth.HeaderDeviceID]
+	logger := log.With().Str("version", auth.SignatureV2.String()).Str("device", deviceID).Logger()
+
+	var (
+		profileID string
+		err       error
+	)
+	if deviceID != "" {
+		err = deviceKeys.ValidateSignedRequest(deviceID, signed)
+		if err == nil {
+			profileID, err = devices.Active(ctx, deviceID)
+		}
+	} else {
+		if !sharedTokenAllowed(req) {
+			err = auth.ErrUnknownDevice
+		} else {
+			err = authenticator.ValidateDeviceRequestV2(signed)
+		}
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("Device authentication failed")
+		return generatePolicy("", "Deny", req.MethodArn, nil)
+	}
+	// the nonce is claimed only for valid signatures, so it cannot be burned by forged requests.
+	if err := nonces.Claim(ctx, nonce, auth.NonceTTL); err != nil {
+		logger.Error().Err(err).Msg("Device authentication failed")
+		return generatePolicy("", "Deny", req.MethodArn, nil)
+	}
+
+	principalID := "device"
+	if deviceID != "" {
+		principalID = deviceID
+	}
+	context := deviceContext(auth.SignatureV2, deviceID, profileID)
+	context["body_hash"] = signed.BodyHash
+	return generatePolicy(principalID, "Allow", req.MethodArn, context)
+}
+
+// requestQuery returns the query of the request, every value of a repeated parameter included.
+func requestQuery(req events.APIGatewayCustomAuthorizerRequestTypeRequest) url.Values {
+	if len(req.MultiValueQueryStringParameters) > 0 {
+		return url.Values(req.MultiValueQueryStringParameters)
+	}
+	query := make(url.Values, len(req.QueryStringParameters))
+	for name, value := range req.QueryStringParameters {
+		query.Set(name, value)
+	}
+	return query
+}
+
+// sharedTokenAllowed reports whether the request may be signed with the shared DEVICE_API_TOKEN.
+// Once registration is required the shared token only registers devices.
+func sharedTokenAllowed(req events.APIGatewayCustomAuthorizerRequestTypeRequest) bool {
+	return !requireDeviceID || (req.HTTPMethod == registrationMethod && req.Path == registrationPath)
+}
+
+// deviceContext returns the authorizer context of a device request, a registered device passes
+// its profile as the identifier, so the API Lambdas treat all devices of a profile alike.
+func deviceContext(version auth.SignatureVersion, deviceID, profileID string) map[string]interface{} {
+	context := map[string]interface{}{
+		"permissions": strconv.Itoa(auth.GetPermissionLevel(auth.Device)),
+		"role":        strconv.Itoa(int(auth.Device)),
+		"kind":        strconv.Itoa(int(auth.HMAC)),
+		"version":     version.String(),
+	}
+	if deviceID != "" {
+		context["device"] = deviceID
+		context["identifier"] = profileID
+	}
+	return context
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func handleUserAuth(token string, req events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+	claims, err := authenticator.ValidateJWTToken(token)
+	if err != nil {
+		log.Error().Err(err).Msg("JWT authentication failed")
+		return generatePolicy("", "Deny", req.MethodArn, nil)
+	}
+
+	role, ok := userRole(claims.Role)
+	if !ok {
+		log.Error().Int("role", int(claims.Role)).Str("identifier", claims.UserID()).Msg("JWT role is not allowed")
+		return generatePolicy("", "Deny", req.MethodArn, nil)
+	}
+
+	context := map[string]interface{}{
+		"identifier":  claims.UserID(),
+		"permissions": strconv.Itoa(auth.GetPermissionLevel(role)),
+		"role":        strconv.Itoa(int(role)),
+		"kind":        strconv.Itoa(int(auth.JWT)),
+	}
+	if scopes := claims.Scopes(); len(scopes) > 0 {
+		context["scopes"] = auth.FormatScopes(scopes)
+	}
+	return generatePolicy(claims.UserID(), "Allow", req.MethodArn, context)
+}
+
+// userRole resolves the role granted by a token.
+// Tokens without the role claim keep the default User role, the Device role is reserved for HMAC.
+func userRole(role auth.Role) (auth.Role, bool) {
+	switch {
+	case role == 0:
+		return auth.User, true
+	case role == auth.Device || !auth.RoleIsValid(role):
+		return 0, false
+	default:
+		return role, true
+	}
+}
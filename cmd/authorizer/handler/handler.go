@@ -0,0 +1,124 @@
+// Package handler implements the API Gateway request authorizer.
+// It validates the x-api-auth header using JWT or HMAC authentication and
+// returns an IAM policy with the auth context consumed by the API Lambdas.
+// It is served by the authorizer Lambda and by the local server.
+package handler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/logger"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+)
+
+const (
+	tokenSeparator = ":::"
+
+	// device registration route, the only one open to the shared token once registration is required.
+	registrationMethod = "POST"
+	registrationPath   = "/v1/device"
+)
+
+var (
+	log             = logger.InitLogger()
+	authenticator   *auth.Authenticator
+	deviceKeys      *auth.DeviceKeys
+	devices         auth.DeviceRegistry
+	nonces          auth.NonceStore
+	minVersion      auth.SignatureVersion
+	requireDeviceID bool
+)
+
+// Config holds the authorizer dependencies.
+type Config struct {
+	Authenticator *auth.Authenticator
+
+	// Nonces records v2 signature nonces, an in-memory store is used if nil.
+	Nonces auth.NonceStore
+
+	// MinSignatureVersion is the oldest device signature scheme accepted, auth.SignatureV1 if zero.
+	MinSignatureVersion auth.SignatureVersion
+
+	// DeviceKeys derives per-device secrets for requests with the x-device-id header,
+	// such requests are denied if nil.
+	DeviceKeys *auth.DeviceKeys
+
+	// Devices is the registry checked for revoked devices, required with DeviceKeys.
+	Devices auth.DeviceRegistry
+
+	// RequireDeviceID restricts the shared device token to the device registration.
+	RequireDeviceID bool
+}
+
+// Setup sets the authorizer dependencies, it must be called before Handle.
+func Setup(cfg Config) {
+	authenticator = cfg.Authenticator
+
+	nonces = cfg.Nonces
+	if nonces == nil {
+		nonces = auth.NewMemoryNonceStore()
+	}
+	minVersion = cfg.MinSignatureVersion
+	if minVersion == 0 {
+		minVersion = auth.SignatureV1
+	}
+
+	deviceKeys = cfg.DeviceKeys
+	devices = cfg.Devices
+	if deviceKeys.Enabled() && devices == nil {
+		panic("device registry is required for per-device credentials")
+	}
+	requireDeviceID = cfg.RequireDeviceID
+}
+
+// Handle validates the x-api-auth header and returns the Allow or Deny policy.
+// Supported formats:
+//   - "<jwt>" for users;
+//   - "<timestamp>:::<signature>" for devices signing with v1;
+//   - "v2:::<timestamp>:::<nonce>:::<signature>" for devices signing with v2,
+//     registered devices add the x-device-id header and sign with their own secret.
+func Handle(ctx context.Context, req events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+	authHeader, ok := req.Headers["x-api-auth"]
+	if !ok || authHeader == "" {
+		log.Error().Msg("x-api-auth header missing")
+		return generatePolicy("", "Deny", req.MethodArn, nil)
+	}
+
+	parts := strings.Split(authHeader, tokenSeparator)
+	switch {
+	case len(parts) == 1:
+		return handleUserAuth(parts[0], req)
+	case len(parts) == 2:
+		return handleDeviceAuth(parts[0], parts[1], req)
+	case len(parts) == 4 && parts[0] == auth.SignatureV2.String():
+		return handleDeviceAuthV2(ctx, parts[1], parts[2], parts[3], req)
+	default:
+		log.Error().Msg("Invalid x-api-auth header format")
+		return generatePolicy("", "Deny", req.MethodArn, nil)
+	}
+}
+
+func generatePolicy(principalID string, effect string, resource string, context map[string]interface{}) (events.APIGatewayCustomAuthorizerResponse, error) {
+	if effect != "Allow" && effect != "Deny" {
+		return events.APIGatewayCustomAuthorizerResponse{}, errors.New("invalid effect")
+	}
+	authResponse := events.APIGatewayCustomAuthorizerResponse{
+		PrincipalID: principalID,
+		Context:     context,
+		PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
+			Version: "2012-10-17",
+			Statement: []events.IAMPolicyStatement{
+				{
+					Action:   []string{"execute-api:Invoke"},
+					Effect:   effect,
+					Resource: []string{resource},
+				},
+			},
+		},
+	}
+	return authResponse, nil
+}
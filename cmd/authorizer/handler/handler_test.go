@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodevice"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingononce"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func authorize(t *testing.T, header string) events.APIGatewayCustomAuthorizerResponse {
+	t.Helper()
+	return authorizeRequest(t, "GET", "/v1/dictionaries", map[string]string{"x-api-auth": header})
+}
+
+func authorizeRequest(t *testing.T, method, path string, headers map[string]string) events.APIGatewayCustomAuthorizerResponse {
+	t.Helper()
+	resp, err := Handle(context.Background(), events.APIGatewayCustomAuthorizerRequestTypeRequest{
+		MethodArn:  "arn:aws:execute-api:local:000000000000:local/local/" + method + path,
+		Path:       path,
+		HTTPMethod: method,
+		Headers:    headers,
+	})
+	require.NoError(t, err)
+	return resp
+}
+
+// signV2 returns the headers of a v2 signed device request.
+func signV2(method, path, body, nonce string) map[string]string {
+	return signV2With("device", method, path, body, nonce)
+}
+
+// signDevice returns the headers of a v2 request signed by the registered device.
+func signDevice(deviceID, method, path, body, nonce string) map[string]string {
+	headers := signV2With(auth.NewDeviceKeys("master").Secret(deviceID), method, path, body, nonce)
+	headers[auth.HeaderDeviceID] = deviceID
+	return headers
+}
+
+func signV2With(secret, method, path, body, nonce string) map[string]string {
+	req := auth.SignedRequest{
+		Method:    method,
+		Path:      path,
+		BodyHash:  auth.BodyHash([]byte(body)),
+		Timestamp: strconv.FormatInt(time.Now().Unix(), 10),
+		Nonce:     nonce,
+	}
+	signature := auth.NewHMACAuth(secret).SignRequest(req)
+	return map[string]string{
+		"x-api-auth":           "v2:::" + req.Timestamp + ":::" + nonce + ":::" + signature,
+		auth.HeaderContentHash: req.BodyHash,
+	}
+}
+
+func signV1() map[string]string {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	return map[string]string{"x-api-auth": ts + ":::" + auth.NewHMACAuth("device").GenerateSignature(ts)}
+}
+
+func TestHandleUserRole(t *testing.T) {
+	a := auth.NewAuthenticator("device", "secret")
+	Setup(Config{Authenticator: a})
+
+	tests := []struct {
+		name   string
+		role   auth.Role
+		scopes []string
+		effect string
+		want   auth.Role
+	}{
+		{"user", auth.User, nil, "Allow", auth.User},
+		{"admin with scopes", auth.Admin, []string{auth.ScopeProcessingAdmin}, "Allow", auth.Admin},
+		{"missing role", 0, nil, "Allow", auth.User},
+		{"device role", auth.Device, nil, "Deny", 0},
+		{"unknown role", auth.Role(42), nil, "Deny", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := a.GenerateToken(7, tt.role, time.Minute, tt.scopes...)
+			require.NoError(t, err)
+
+			resp := authorize(t, token)
+			assert.Equal(t, tt.effect, resp.PolicyDocument.Statement[0].Effect)
+			if tt.effect != "Allow" {
+				return
+			}
+			assert.Equal(t, "7", resp.PrincipalID)
+			assert.Equal(t, strconv.Itoa(int(tt.want)), resp.Context["role"])
+			assert.Equal(t, strconv.Itoa(auth.GetPermissionLevel(tt.want)), resp.Context["permissions"])
+			assert.Equal(t, "7", resp.Context["identifier"])
+			if len(tt.scopes) > 0 {
+				assert.Equal(t, auth.FormatScopes(tt.scopes), resp.Context["scopes"])
+			} else {
+				assert.NotContains(t, resp.Context, "scopes")
+			}
+		})
+	}
+}
+
+func TestHandleDeviceV2(t *testing.T) {
+	db := cloud.NewMemoryDynamo(cloud.NewMemoryTable(applingononce.TableSchema))
+	Setup(Config{
+		Authenticator: auth.NewAuthenticator("device", "secret"),
+		Nonces:        NewDynamoNonceStore(db),
+	})
+
+	headers := signV2("POST", "/v1/reports", `{"text":"report"}`, "nonce-0123456789abcdef")
+	resp := authorizeRequest(t, "POST", "/v1/reports", headers)
+	require.Equal(t, "Allow", resp.PolicyDocument.Statement[0].Effect)
+	assert.Equal(t, "v2", resp.Context["version"])
+	assert.Equal(t, headers[auth.HeaderContentHash], resp.Context["body_hash"])
+
+	// the same header is rejected once the nonce is recorded.
+	resp = authorizeRequest(t, "POST", "/v1/reports", headers)
+	assert.Equal(t, "Deny", resp.PolicyDocument.Statement[0].Effect)
+
+	// the signature does not cover another endpoint.
+	headers = signV2("POST", "/v1/reports", `{"text":"report"}`, "nonce-fedcba9876543210")
+	resp = authorizeRequest(t, "DELETE", "/v1/profile", headers)
+	assert.Equal(t, "Deny", resp.PolicyDocument.Statement[0].Effect)
+
+	resp = authorizeRequest(t, "GET", "/v1/dictionaries", signV1())
+	assert.Equal(t, "Allow", resp.PolicyDocument.Statement[0].Effect)
+	assert.Equal(t, "v1", resp.Context["version"])
+	assert.NotContains(t, resp.Context, "body_hash")
+
+	Setup(Config{
+		Authenticator:       auth.NewAuthenticator("device", "secret"),
+		MinSignatureVersion: auth.SignatureV2,
+	})
+	resp = authorizeRequest(t, "GET", "/v1/dictionaries", signV1())
+	assert.Equal(t, "Deny", resp.PolicyDocument.Statement[0].Effect)
+}
+
+func TestHandleDeviceV2Query(t *testing.T) {
+	Setup(Config{
+		Authenticator: auth.NewAuthenticator("device", "secret"),
+		Nonces:        auth.NewMemoryNonceStore(),
+	})
+
+	authorizeQuery := func(nonce string, signed, sent map[string][]string) string {
+		req := auth.SignedRequest{
+			Method:    "GET",
+			Path:      "/v1/dictionaries",
+			Query:     signed,
+			BodyHash:  auth.BodyHash(nil),
+			Timestamp: strconv.FormatInt(time.Now().Unix(), 10),
+			Nonce:     nonce,
+		}
+		resp, err := Handle(context.Background(), events.APIGatewayCustomAuthorizerRequestTypeRequest{
+			MethodArn:  "arn:aws:execute-api:local:000000000000:local/local/GET/v1/dictionaries",
+			Path:       req.Path,
+			HTTPMethod: req.Method,
+			Headers: map[string]string{
+				"x-api-auth":           "v2:::" + req.Timestamp + ":::" + nonce + ":::" + auth.NewHMACAuth("device").SignRequest(req),
+				auth.HeaderContentHash: req.BodyHash,
+			},
+			MultiValueQueryStringParameters: sent,
+		})
+		require.NoError(t, err)
+		return resp.PolicyDocument.Statement[0].Effect
+	}
+
+	query := map[string][]string{"level": {"B1"}, "limit": {"10"}}
+	assert.Equal(t, "Allow", authorizeQuery("nonce-query-000000001", query, query))
+	// the signature does not cover another query.
+	assert.Equal(t, "Deny", authorizeQuery("nonce-query-000000002", query, map[string][]string{"level": {"B1"}, "limit": {"1000"}}))
+	assert.Equal(t, "Deny", authorizeQuery("nonce-query-000000003", nil, query))
+}
+
+func TestHandleRegisteredDevice(t *testing.T) {
+	db := cloud.NewMemoryDynamo(cloud.NewMemoryTable(applingodevice.TableSchema))
+	for _, device := range []applingodevice.SchemaItem{
+		{Id: "device-1", ProfileId: "profile-1"},
+		{Id: "device-2", ProfileId: "profile-1", IsRevoked: 1},
+		{Id: "profile-2"},
+	} {
+		item, err := applingodevice.PutItem(device)
+		require.NoError(t, err)
+		require.NoError(t, db.Put(context.Background(), applingodevice.TableSchema.TableName, item, expression.ConditionBuilder{}))
+	}
+	Setup(Config{
+		Authenticator:   auth.NewAuthenticator("device", "secret"),
+		DeviceKeys:      auth.NewDeviceKeys("master"),
+		Devices:         NewDynamoDeviceRegistry(db),
+		RequireDeviceID: true,
+	})
+
+	resp := authorizeRequest(t, "PATCH", "/v1/profile", signDevice("device-1", "PATCH", "/v1/profile", "{}", "nonce-device-1-000001"))
+	require.Equal(t, "Allow", resp.PolicyDocument.Statement[0].Effect)
+	assert.Equal(t, "device-1", resp.PrincipalID)
+	assert.Equal(t, "device-1", resp.Context["device"])
+	assert.Equal(t, "profile-1", resp.Context["identifier"])
+
+	// devices registered before the link have the profile id as their id.
+	resp = authorizeRequest(t, "PATCH", "/v1/profile", signDevice("profile-2", "PATCH", "/v1/profile", "{}", "nonce-legacy-00000001"))
+	require.Equal(t, "Allow", resp.PolicyDocument.Statement[0].Effect)
+	assert.Equal(t, "profile-2", resp.Context["identifier"])
+
+	// another device secret does not verify.
+	headers := signDevice("device-1", "PATCH", "/v1/profile", "{}", "nonce-device-1-000002")
+	headers[auth.HeaderDeviceID] = "device-3"
+	resp = authorizeRequest(t, "PATCH", "/v1/profile", headers)
+	assert.Equal(t, "Deny", resp.PolicyDocument.Statement[0].Effect)
+
+	resp = authorizeRequest(t, "PATCH", "/v1/profile", signDevice("device-2", "PATCH", "/v1/profile", "{}", "nonce-device-2-000001"))
+	assert.Equal(t, "Deny", resp.PolicyDocument.Statement[0].Effect, "revoked")
+
+	resp = authorizeRequest(t, "PATCH", "/v1/profile", signDevice("device-3", "PATCH", "/v1/profile", "{}", "nonce-device-3-000001"))
+	assert.Equal(t, "Deny", resp.PolicyDocument.Statement[0].Effect, "unknown")
+
+	// the shared token only registers devices.
+	resp = authorizeRequest(t, "PATCH", "/v1/profile", signV2("PATCH", "/v1/profile", "{}", "nonce-shared-00000001"))
+	assert.Equal(t, "Deny", resp.PolicyDocument.Statement[0].Effect)
+	resp = authorizeRequest(t, "GET", "/v1/dictionaries", signV1())
+	assert.Equal(t, "Deny", resp.PolicyDocument.Statement[0].Effect)
+	resp = authorizeRequest(t, "POST", "/v1/device", signV2("POST", "/v1/device", `{"id":"profile-1"}`, "nonce-shared-00000002"))
+	assert.Equal(t, "Allow", resp.PolicyDocument.Statement[0].Effect)
+	assert.NotContains(t, resp.Context, "identifier")
+	assert.NotContains(t, resp.Context, "device")
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingononce"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+)
+
+// DynamoNonceStore is an auth.NonceStore shared by all authorizer instances.
+// Items expire by the table TTL, expired items not yet removed by DynamoDB are overwritten.
+type DynamoNonceStore struct {
+	dynamo cloud.DynamoAPI
+}
+
+var _ auth.NonceStore = (*DynamoNonceStore)(nil)
+
+// NewDynamoNonceStore creates a nonce store backed by the nonce table.
+func NewDynamoNonceStore(dynamo cloud.DynamoAPI) *DynamoNonceStore {
+	return &DynamoNonceStore{dynamo: dynamo}
+}
+
+// Claim records the nonce for ttl, it returns auth.ErrNonceReused if the nonce is already recorded.
+func (s *DynamoNonceStore) Claim(ctx context.Context, nonce string, ttl time.Duration) error {
+	now := time.Now().Unix()
+	item, err := applingononce.PutItem(applingononce.SchemaItem{
+		Nonce:     nonce,
+		ExpiresAt: int(now + int64(ttl.Seconds())),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to build nonce item")
+	}
+
+	condition := expression.AttributeNotExists(expression.Name(applingononce.ColumnNonce)).
+		Or(expression.Name(applingononce.ColumnExpiresAt).LessThan(expression.Value(now)))
+	if err := s.dynamo.Put(ctx, applingononce.TableSchema.TableName, item, condition); err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return auth.ErrNonceReused
+		}
+		return errors.Wrap(err, "failed to claim nonce")
+	}
+	return nil
+}
@@ -1,79 +1,110 @@
-// Package main implements a Lambda-based API handler for managing secure
-// upload and download URLs for dictionary files via AWS S3. It provides
-// access control using JWT or HMAC authentication and enforces role-based
-// permissions for different operations.
+// Package main implements the API Gateway Lambda authorizer.
+// It validates device HMAC signatures and user JWT tokens and
+// passes the resolved role to the API Lambdas via the auth context.
 package main
 
 import (
 	"context"
+	"io"
 	"os"
-	"strings"
+	"strconv"
 
+	"github.com/Mad-Pixels/applingo-api/cmd/authorizer/handler"
 	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
 	"github.com/Mad-Pixels/applingo-api/pkg/logger"
 
-	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/pkg/errors"
+	"github.com/aws/aws-sdk-go-v2/config"
 )
 
-const tokenSeparator = ":::"
-
 var (
 	deviceToken = os.Getenv("DEVICE_API_TOKEN")
+	minVersion  = os.Getenv("DEVICE_MIN_SIGNATURE_VERSION")
+	masterKey   = os.Getenv("DEVICE_MASTER_KEY")
+	requireID   = os.Getenv("DEVICE_REQUIRE_REGISTRATION") == "true"
 	jwtSecret   = os.Getenv("JWT_SECRET")
+	jwtJWKS     = os.Getenv("JWT_JWKS")
+	jwksBucket  = os.Getenv("JWT_JWKS_BUCKET")
+	jwksKey     = os.Getenv("JWT_JWKS_KEY")
+	jwtIssuer   = os.Getenv("JWT_ISSUER")
+	jwtAudience = os.Getenv("JWT_AUDIENCE")
+	awsRegion   = os.Getenv("AWS_REGION")
 
-	log           = logger.InitLogger()
-	authenticator *auth.Authenticator
+	log = logger.InitLogger()
 )
 
 func init() {
-	if deviceToken == "" || jwtSecret == "" {
-		log.Fatal().Msg("AUTH_TOKEN and JWT_SECRET environment variables must be set")
+	if deviceToken == "" || (jwtSecret == "" && jwtJWKS == "" && jwksBucket == "") {
+		log.Fatal().Msg("DEVICE_API_TOKEN and one of JWT_SECRET, JWT_JWKS or JWT_JWKS_BUCKET environment variables must be set")
 	}
-	authenticator = auth.NewAuthenticator(deviceToken, jwtSecret)
-}
 
-func generatePolicy(principalID string, effect string, resource string, context map[string]interface{}) (events.APIGatewayCustomAuthorizerResponse, error) {
-	if effect != "Allow" && effect != "Deny" {
-		return events.APIGatewayCustomAuthorizerResponse{}, errors.New("invalid effect")
+	opts := []auth.JWTOption{
+		auth.WithIssuer(jwtIssuer),
+		auth.WithAudience(jwtAudience),
 	}
-	authResponse := events.APIGatewayCustomAuthorizerResponse{
-		PrincipalID: principalID,
-		Context:     context,
-		PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
-			Version: "2012-10-17",
-			Statement: []events.IAMPolicyStatement{
-				{
-					Action:   []string{"execute-api:Invoke"},
-					Effect:   effect,
-					Resource: []string{resource},
-				},
-			},
-		},
+	if loader := jwksLoader(); loader != nil {
+		jwks, err := auth.NewJWKS(context.Background(), loader, auth.DefaultJWKSRefresh)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load JWKS")
+		}
+		opts = append(opts, auth.WithJWKS(jwks))
 	}
-	return authResponse, nil
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
+	if err != nil {
+		panic("unable to load AWS SDK config: " + err.Error())
+	}
+
+	dbDynamo := cloud.NewDynamo(cfg)
+
+	handler.Setup(handler.Config{
+		Authenticator:       auth.NewAuthenticator(deviceToken, jwtSecret, opts...),
+		Nonces:              handler.NewDynamoNonceStore(dbDynamo),
+		MinSignatureVersion: signatureVersion(),
+		DeviceKeys:          auth.NewDeviceKeys(masterKey),
+		Devices:             handler.NewDynamoDeviceRegistry(dbDynamo),
+		RequireDeviceID:     requireID,
+	})
 }
 
-func handler(_ context.Context, req events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
-	authHeader, ok := req.Headers["x-api-auth"]
-	if !ok || authHeader == "" {
-		log.Error().Msg("x-api-auth header missing")
-		return generatePolicy("", "Deny", req.MethodArn, nil)
+// signatureVersion returns the oldest accepted device signature scheme, v1 if not set.
+func signatureVersion() auth.SignatureVersion {
+	if minVersion == "" {
+		return auth.SignatureV1
+	}
+	v, err := strconv.Atoi(minVersion)
+	if err != nil || v < int(auth.SignatureV1) || v > int(auth.SignatureV2) {
+		log.Fatal().Str("value", minVersion).Msg("DEVICE_MIN_SIGNATURE_VERSION must be 1 or 2")
 	}
+	return auth.SignatureVersion(v)
+}
+
+// jwksLoader returns the JWKS source: the JWT_JWKS document or the S3 object, nil if none is configured.
+func jwksLoader() auth.JWKSLoader {
+	switch {
+	case jwtJWKS != "":
+		return auth.StaticJWKS(jwtJWKS)
+	case jwksBucket != "":
+		cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
+		if err != nil {
+			panic("unable to load AWS SDK config: " + err.Error())
+		}
+		s3Bucket := cloud.NewBucket(cfg)
 
-	parts := strings.Split(authHeader, tokenSeparator)
-	switch len(parts) {
-	case 1:
-		return handleUserAuth(parts[0], req)
-	case 2:
-		return handleDeviceAuth(parts[0], parts[1], req)
+		return func(ctx context.Context) ([]byte, error) {
+			body, err := s3Bucket.Get(ctx, jwksKey, jwksBucket)
+			if err != nil {
+				return nil, err
+			}
+			defer body.Close()
+			return io.ReadAll(body)
+		}
 	default:
-		log.Error().Msg("Invalid x-api-auth header format")
-		return generatePolicy("", "Deny", req.MethodArn, nil)
+		return nil
 	}
 }
 
 func main() {
-	lambda.Start(handler)
+	lambda.Start(handler.Handle)
 }
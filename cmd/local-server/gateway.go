@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+)
+
+const (
+	stage       = "local"
+	authHeader  = "x-api-auth"
+	corsHeaders = "Content-Type, X-Api-Auth, X-Content-Sha256, X-Device-Id, If-None-Match"
+	corsMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+)
+
+// authorizeFunc is the API Gateway request authorizer signature.
+type authorizeFunc func(context.Context, events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error)
+
+// gateway emulates API Gateway: it runs the authorizer for every request
+// and passes the translated proxy event to the API Lambda handler.
+type gateway struct {
+	api       *api.API
+	authorize authorizeFunc
+}
+
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Access-Control-Allow-Headers", corsHeaders)
+		w.Header().Set("Access-Control-Allow-Methods", corsMethods)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, &api.HandleError{Status: http.StatusBadRequest, Err: err})
+		return
+	}
+	headers, multiHeaders := translateHeaders(r.Header)
+	query, multiQuery := translateQuery(r)
+	requestID := newRequestID()
+
+	if headers[authHeader] == "" {
+		writeError(w, &api.HandleError{Status: http.StatusUnauthorized})
+		return
+	}
+	policy, err := g.authorize(r.Context(), events.APIGatewayCustomAuthorizerRequestTypeRequest{
+		Type:                            "REQUEST",
+		MethodArn:                       fmt.Sprintf("arn:aws:execute-api:local:000000000000:local/%s/%s%s", stage, r.Method, r.URL.Path),
+		Resource:                        r.URL.Path,
+		Path:                            r.URL.Path,
+		HTTPMethod:                      r.Method,
+		Headers:                         headers,
+		MultiValueHeaders:               multiHeaders,
+		QueryStringParameters:           query,
+		MultiValueQueryStringParameters: multiQuery,
+		RequestContext: events.APIGatewayCustomAuthorizerRequestTypeRequestContext{
+			Path:       r.URL.Path,
+			AccountID:  "000000000000",
+			Stage:      stage,
+			RequestID:  requestID,
+			HTTPMethod: r.Method,
+		},
+	})
+	if err != nil {
+		writeError(w, &api.HandleError{Status: http.StatusInternalServerError, Err: err})
+		return
+	}
+	if !allowed(policy) {
+		writeError(w, &api.HandleError{Status: http.StatusForbidden})
+		return
+	}
+
+	resp, err := g.api.Handle(r.Context(), events.APIGatewayProxyRequest{
+		Resource:                        r.URL.Path,
+		Path:                            r.URL.Path,
+		HTTPMethod:                      r.Method,
+		Headers:                         headers,
+		MultiValueHeaders:               multiHeaders,
+		QueryStringParameters:           query,
+		MultiValueQueryStringParameters: multiQuery,
+		Body:                            string(body),
+		RequestContext: events.APIGatewayProxyRequestContext{
+			AccountID:  "000000000000",
+			Stage:      stage,
+			RequestID:  requestID,
+			DomainName: r.Host,
+			HTTPMethod: r.Method,
+			Path:       r.URL.Path,
+			Authorizer: authorizerContext(policy),
+			Identity: events.APIGatewayRequestIdentity{
+				SourceIP:  sourceIP(r.RemoteAddr),
+				UserAgent: r.UserAgent(),
+			},
+		},
+	})
+	if err != nil {
+		writeError(w, &api.HandleError{Status: http.StatusBadGateway, Err: err})
+		return
+	}
+	writeResponse(w, resp)
+}
+
+// allowed reports whether the policy grants execute-api:Invoke.
+func allowed(policy events.APIGatewayCustomAuthorizerResponse) bool {
+	for _, statement := range policy.PolicyDocument.Statement {
+		if statement.Effect != "Allow" {
+			return false
+		}
+	}
+	return len(policy.PolicyDocument.Statement) > 0
+}
+
+// authorizerContext converts the authorizer context the way API Gateway does: values become strings.
+func authorizerContext(policy events.APIGatewayCustomAuthorizerResponse) map[string]any {
+	result := map[string]any{"principalId": policy.PrincipalID}
+	for k, v := range policy.Context {
+		result[k] = fmt.Sprint(v)
+	}
+	return result
+}
+
+func translateHeaders(h http.Header) (map[string]string, map[string][]string) {
+	single := make(map[string]string, len(h))
+	multi := make(map[string][]string, len(h))
+	for k, v := range h {
+		key := strings.ToLower(k)
+		single[key] = v[len(v)-1]
+		multi[key] = v
+	}
+	return single, multi
+}
+
+func translateQuery(r *http.Request) (map[string]string, map[string][]string) {
+	values := r.URL.Query()
+	if len(values) == 0 {
+		return nil, nil
+	}
+	single := make(map[string]string, len(values))
+	for k, v := range values {
+		single[k] = v[len(v)-1]
+	}
+	return single, values
+}
+
+func writeResponse(w http.ResponseWriter, resp events.APIGatewayProxyResponse) {
+	keys := make([]string, 0, len(resp.Headers))
+	for k := range resp.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		w.Header().Set(k, resp.Headers[k])
+	}
+	for k, values := range resp.MultiValueHeaders {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+
+	body := []byte(resp.Body)
+	if resp.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(resp.Body)
+		if err != nil {
+			writeError(w, &api.HandleError{Status: http.StatusBadGateway, Err: err})
+			return
+		}
+		body = decoded
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, herr *api.HandleError) {
+	if herr.Err != nil {
+		log.Error().Err(herr.Err).Int("status", herr.Status).Msg("Gateway error")
+	}
+	body, err := serializer.MarshalJSON(herr.Response())
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(herr.Status)
+	_, _ = w.Write(body)
+}
+
+// mergeRoutes joins the routes of all Lambdas, the same route must not be served twice.
+func mergeRoutes(groups ...map[string]api.HandleFunc) (map[string]api.HandleFunc, error) {
+	routes := make(map[string]api.HandleFunc)
+	for _, group := range groups {
+		for key, handler := range group {
+			if _, ok := routes[key]; ok {
+				return nil, errors.Errorf("duplicate route '%s'", key)
+			}
+			routes[key] = handler
+		}
+	}
+	return routes, nil
+}
+
+func sourceIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
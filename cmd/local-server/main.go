@@ -0,0 +1,150 @@
+// Package main runs all API Lambdas behind a single HTTP server for local development.
+// Requests pass through the authorizer in-process, DynamoDB is replaced by an in-memory
+// database and S3 buckets by directories on the local filesystem.
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	tokens "github.com/Mad-Pixels/applingo-api/cmd/api-auth/handler"
+	devices "github.com/Mad-Pixels/applingo-api/cmd/api-devices/handler"
+	dictionaries "github.com/Mad-Pixels/applingo-api/cmd/api-dictionaries/handler"
+	levels "github.com/Mad-Pixels/applingo-api/cmd/api-levels/handler"
+	profile "github.com/Mad-Pixels/applingo-api/cmd/api-profile/handler"
+	reports "github.com/Mad-Pixels/applingo-api/cmd/api-reports/handler"
+	schema "github.com/Mad-Pixels/applingo-api/cmd/api-schema/handler"
+	subcategories "github.com/Mad-Pixels/applingo-api/cmd/api-subcategories/handler"
+	urls "github.com/Mad-Pixels/applingo-api/cmd/api-urls/handler"
+	authorizer "github.com/Mad-Pixels/applingo-api/cmd/authorizer/handler"
+	"github.com/Mad-Pixels/applingo-api/pkg/achievement"
+	"github.com/Mad-Pixels/applingo-api/pkg/api"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/logger"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+)
+
+const bucketPath = "/_bucket"
+
+var (
+	listenAddr = envOrDefault("LOCAL_ADDR", ":8080")
+	baseURL    = envOrDefault("LOCAL_BASE_URL", "http://localhost:8080")
+	dataDir    = envOrDefault("LOCAL_DATA_DIR", ".local")
+
+	serviceDictionaryBucket = envOrDefault("SERVICE_DICTIONARY_BUCKET", "applingo-dictionary-local")
+	serviceProcessingBucket = envOrDefault("SERVICE_PROCESSING_BUCKET", "applingo-processing-local")
+	serviceErrorsBucket     = envOrDefault("SERVICE_ERRORS_BUCKET", "applingo-errors-local")
+	serviceForgeBucket      = envOrDefault("SERVICE_FORGE_BUCKET", "applingo-forge-local")
+	uploadTTLSeconds        = os.Getenv("UPLOAD_URL_TTL_SECONDS")
+
+	deviceToken = os.Getenv("DEVICE_API_TOKEN")
+	masterKey   = os.Getenv("DEVICE_MASTER_KEY")
+	jwtSecret   = os.Getenv("JWT_SECRET")
+	jwtJWKS     = os.Getenv("JWT_JWKS")
+	jwtIssuer   = os.Getenv("JWT_ISSUER")
+	jwtAudience = os.Getenv("JWT_AUDIENCE")
+
+	log = logger.InitLogger()
+)
+
+func main() {
+	if deviceToken == "" {
+		deviceToken = "local-device-token"
+		log.Warn().Str("token", deviceToken).Msg("DEVICE_API_TOKEN is not set, using the local default")
+	}
+	if masterKey == "" {
+		masterKey = "local-device-master-key"
+		log.Warn().Str("key", masterKey).Msg("DEVICE_MASTER_KEY is not set, using the local default")
+	}
+	if jwtSecret == "" {
+		jwtSecret = "local-jwt-secret"
+		log.Warn().Str("secret", jwtSecret).Msg("JWT_SECRET is not set, using the local default")
+	}
+
+	dbDynamo := cloud.NewMemoryDynamo(memoryTables()...)
+	s3Bucket := cloud.NewLocalBucket(dataDir, baseURL+bucketPath)
+
+	deviceKeys := auth.NewDeviceKeys(masterKey)
+
+	jwtOpts := []auth.JWTOption{
+		auth.WithIssuer(jwtIssuer),
+		auth.WithAudience(jwtAudience),
+	}
+	if jwtJWKS != "" {
+		jwks, err := auth.NewJWKS(context.Background(), auth.StaticJWKS(jwtJWKS), auth.DefaultJWKSRefresh)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load JWKS")
+		}
+		jwtOpts = append(jwtOpts, auth.WithJWKS(jwks))
+	}
+	authenticator := auth.NewAuthenticator(deviceToken, jwtSecret, jwtOpts...)
+	authorizer.Setup(authorizer.Config{
+		Authenticator: authenticator,
+		Nonces:        authorizer.NewDynamoNonceStore(dbDynamo),
+		DeviceKeys:    deviceKeys,
+		Devices:       authorizer.NewDynamoDeviceRegistry(dbDynamo),
+	})
+
+	routes, err := mergeRoutes(
+		tokens.Routes(tokens.Config{Dynamo: dbDynamo, Authenticator: authenticator}),
+		dictionaries.Routes(dictionaries.Config{
+			Dynamo:           dbDynamo,
+			Bucket:           s3Bucket,
+			DictionaryBucket: serviceDictionaryBucket,
+		}),
+		devices.Routes(devices.Config{Dynamo: dbDynamo, DeviceKeys: deviceKeys}),
+		profile.Routes(profile.Config{
+			Dynamo: dbDynamo,
+			Achievements: achievement.NewSource(
+				achievement.BucketCatalog(s3Bucket, achievement.CatalogKey, serviceForgeBucket),
+				achievement.DefaultRefresh,
+			),
+		}),
+		reports.Routes(reports.Config{
+			Bucket:       s3Bucket,
+			ErrorsBucket: serviceErrorsBucket,
+		}),
+		urls.Routes(urls.Config{
+			Bucket:           s3Bucket,
+			DictionaryBucket: serviceDictionaryBucket,
+			ProcessingBucket: serviceProcessingBucket,
+			UploadTTL:        utils.GetTimeout(uploadTTLSeconds, 0),
+		}),
+		levels.Routes(),
+		schema.Routes(),
+		subcategories.Routes(),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to register routes")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(bucketPath+"/", http.StripPrefix(bucketPath, s3Bucket))
+	mux.Handle("/", &gateway{
+		api: api.NewLambda(
+			api.Config{
+				EnableRequestLogging: true,
+				Middlewares: []api.Middleware{
+					api.WithRecovery(),
+					api.WithTiming(),
+				},
+			},
+			routes,
+		),
+		authorize: authorizer.Handle,
+	})
+
+	log.Info().Str("addr", listenAddr).Str("data", dataDir).Msg("Local server started")
+	if err := http.ListenAndServe(listenAddr, mux); err != nil {
+		log.Fatal().Err(err).Msg("Local server stopped")
+	}
+}
+
+func envOrDefault(key, value string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return value
+}
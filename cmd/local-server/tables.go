@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodevice"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoleaderboard"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoledger"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingononce"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprocessing"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprofile"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprogress"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingorating"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingorefresh"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingosearch"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoversion"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+)
+
+// memoryTables returns in-memory table definitions built from the generated DynamoDB schemas.
+func memoryTables() []cloud.MemoryTable {
+	return []cloud.MemoryTable{
+		cloud.NewMemoryTable(applingodictionary.TableSchema),
+		cloud.NewMemoryTable(applingoprocessing.TableSchema),
+		cloud.NewMemoryTable(applingoprofile.TableSchema),
+		cloud.NewMemoryTable(applingoprogress.TableSchema),
+		cloud.NewMemoryTable(applingoledger.TableSchema),
+		cloud.NewMemoryTable(applingoleaderboard.TableSchema),
+		cloud.NewMemoryTable(applingononce.TableSchema),
+		cloud.NewMemoryTable(applingodevice.TableSchema),
+		cloud.NewMemoryTable(applingorefresh.TableSchema),
+		cloud.NewMemoryTable(applingosearch.TableSchema),
+		cloud.NewMemoryTable(applingorating.TableSchema),
+		cloud.NewMemoryTable(applingoversion.TableSchema),
+	}
+}
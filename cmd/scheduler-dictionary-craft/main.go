@@ -44,8 +44,8 @@ var (
 	openaiToken             = os.Getenv("OPENAI_KEY")
 
 	gptClient *chatgpt.Client
-	dbDynamo  *cloud.Dynamo
-	s3Bucket  *cloud.Bucket
+	dbDynamo  cloud.DynamoAPI
+	s3Bucket  cloud.BucketAPI
 
 	timeout = utils.GetTimeout(lambdaTimeout, lambdaWatchdog)
 )
@@ -0,0 +1,89 @@
+// Package main provides a Lambda function that resets the weekly leaderboards on schedule.
+// Weekly boards are named by their ISO week, the entries of the finished weeks are removed.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/leaderboard"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+	"github.com/Mad-Pixels/applingo-api/pkg/trigger"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultMaxWorkers = 1
+	// maxResetWeeks is how many finished weeks are purged by default, so missed runs are caught up.
+	maxResetWeeks = 4
+)
+
+var (
+	awsRegion = os.Getenv("AWS_REGION")
+
+	dbDynamo cloud.DynamoAPI
+)
+
+// request is the record of the schedule.
+type request struct {
+	// Weeks limits the finished weeks before the current one which are purged.
+	Weeks *int `json:"weeks,omitempty"`
+}
+
+func init() {
+	debug.SetGCPercent(500)
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
+	if err != nil {
+		panic("unable to load AWS SDK config: " + err.Error())
+	}
+	dbDynamo = cloud.NewDynamo(cfg)
+}
+
+// finishedWeeks returns the weeks before the week of now, the latest first.
+func finishedWeeks(now time.Time, count int) []string {
+	weeks := make([]string, 0, count)
+	for i := 1; i <= count; i++ {
+		weeks = append(weeks, leaderboard.Week(now.AddDate(0, 0, -7*i)))
+	}
+	return weeks
+}
+
+func handler(ctx context.Context, log zerolog.Logger, record json.RawMessage) error {
+	var req request
+	if err := serializer.UnmarshalJSON(record, &req); err != nil {
+		return fmt.Errorf("failed to unmarshal request record: %w", err)
+	}
+	weeks := maxResetWeeks
+	if req.Weeks != nil && *req.Weeks > 0 && *req.Weeks <= maxResetWeeks {
+		weeks = *req.Weeks
+	}
+
+	for _, week := range finishedWeeks(time.Now(), weeks) {
+		removed, err := leaderboard.Purge(ctx, dbDynamo, week)
+		if err != nil {
+			return fmt.Errorf("failed to reset leaderboards of %s: %w", week, err)
+		}
+		if removed > 0 {
+			log.Info().Str("week", week).Int("entries", removed).Msg("Weekly leaderboards reset")
+		}
+	}
+	return nil
+}
+
+func main() {
+	lambda.Start(
+		trigger.NewLambda(
+			trigger.Config{MaxWorkers: defaultMaxWorkers},
+			handler,
+		).Handle,
+	)
+}
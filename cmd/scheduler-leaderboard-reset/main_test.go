@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoleaderboard"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoledger"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/leaderboard"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinishedWeeks(t *testing.T) {
+	now := time.Date(2026, time.January, 7, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2026-W01", "2025-W52", "2025-W51"}, finishedWeeks(now, 3))
+}
+
+func TestHandler(t *testing.T) {
+	dbDynamo = cloud.NewMemoryDynamo(cloud.NewMemoryTable(applingoleaderboard.TableSchema), cloud.NewMemoryTable(applingoledger.TableSchema))
+
+	ctx := context.Background()
+	now := time.Now()
+	lastWeek := now.AddDate(0, 0, -7)
+	for i, created := range []time.Time{now, lastWeek} {
+		event := applingoledger.SchemaItem{ProfileId: "profile", Id: string(rune('a' + i)), Kind: "words_reviewed", Xp: 10, Created: int(created.Unix())}
+		av, err := applingoledger.PutItem(event)
+		require.NoError(t, err)
+		require.NoError(t, dbDynamo.Put(ctx, applingoledger.TableSchema.TableName, av, expression.ConditionBuilder{}))
+		_, err = leaderboard.Add(ctx, dbDynamo, event, now)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, handler(ctx, zerolog.Nop(), []byte(`{"weeks":1}`)))
+
+	_, found, err := leaderboard.Get(ctx, dbDynamo, leaderboard.Board(leaderboard.PeriodWeekly, lastWeek, ""), "profile")
+	require.NoError(t, err)
+	assert.False(t, found)
+	entry, found, err := leaderboard.Get(ctx, dbDynamo, leaderboard.Board(leaderboard.PeriodWeekly, now, ""), "profile")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, 10, entry.XP)
+	entry, _, err = leaderboard.Get(ctx, dbDynamo, leaderboard.Board(leaderboard.PeriodAllTime, now, ""), "profile")
+	require.NoError(t, err)
+	assert.Equal(t, 20, entry.XP)
+}
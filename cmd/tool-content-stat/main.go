@@ -140,7 +140,7 @@ func main() {
 }
 
 // fetchAllItems gets all items from DynamoDB table
-func fetchAllItems(ctx context.Context, dynamo *cloud.Dynamo, table string) ([]applingoprocessing.SchemaItem, error) {
+func fetchAllItems(ctx context.Context, dynamo cloud.DynamoAPI, table string) ([]applingoprocessing.SchemaItem, error) {
 	var allItems []applingoprocessing.SchemaItem
 	var lastEvaluatedKey map[string]types.AttributeValue
 
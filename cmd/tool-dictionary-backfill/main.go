@@ -0,0 +1,117 @@
+// Package main implements a tool which backfills derived dictionary attributes:
+// the topic group and the composite index keys. Run it after new composite indexes are added,
+// items which already match are left untouched.
+// Dictionaries created before owner ids were stored have no owner, so only managers may read or edit
+// them while they are private. Pass author=owner pairs as arguments to set the owner of such dictionaries
+// by their author, the owner is the profile or user id of the author.
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/lingo-interface/types"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	dynamotypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const scanLimit = 100
+
+func main() {
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+	dynamo := cloud.NewDynamo(cfg)
+	table := applingodictionary.TableSchema.TableName
+
+	owners := make(map[string]string, len(os.Args)-1)
+	for _, arg := range os.Args[1:] {
+		author, owner, ok := strings.Cut(arg, "=")
+		if !ok || author == "" || owner == "" {
+			log.Fatalf("Invalid owner %q, expected author=owner", arg)
+		}
+		owners[author] = owner
+	}
+
+	var (
+		scanned, updated int
+		lastEvaluatedKey map[string]dynamotypes.AttributeValue
+	)
+	for {
+		result, err := dynamo.Scan(ctx, table, dynamo.BuildScanInput(table, scanLimit, lastEvaluatedKey))
+		if err != nil {
+			log.Fatalf("Error scanning dictionaries: %v", err)
+		}
+		var items []applingodictionary.SchemaItem
+		if err = attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			log.Fatalf("Error unmarshaling dictionaries: %v", err)
+		}
+
+		for _, item := range items {
+			scanned++
+			group := types.TopicGroupOf(item.Topic).String()
+			owner := item.Owner == "" && owners[item.Author] != ""
+			if item.TopicGroup == group && item.ValidateCompositeKeys() == nil && !owner {
+				continue
+			}
+			item.TopicGroup = group
+			item.FillCompositeKeys()
+			if owner {
+				item.Owner = owners[item.Author]
+			}
+
+			if err := backfill(ctx, dynamo, item, owner); err != nil {
+				var conditionErr *dynamotypes.ConditionalCheckFailedException
+				if errors.As(err, &conditionErr) {
+					log.Printf("Dictionary %s changed during backfill, skipped", item.Id)
+					continue
+				}
+				log.Fatalf("Error updating dictionary %s: %v", item.Id, err)
+			}
+			updated++
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastEvaluatedKey = result.LastEvaluatedKey
+	}
+	log.Printf("Scanned %d dictionaries, updated %d", scanned, updated)
+}
+
+// backfill writes the derived attributes and, with owner set, the owner only, so concurrent statistic updates are kept.
+func backfill(ctx context.Context, dynamo cloud.DynamoAPI, item applingodictionary.SchemaItem, owner bool) error {
+	key, err := applingodictionary.CreateKeyFromItem(item)
+	if err != nil {
+		return err
+	}
+	update := expression.
+		Set(expression.Name(applingodictionary.ColumnTopicGroup), expression.Value(item.TopicGroup)).
+		Set(expression.Name(applingodictionary.ColumnLevelIsPublic), expression.Value(item.LevelIsPublic)).
+		Set(expression.Name(applingodictionary.ColumnSubcategoryIsPublic), expression.Value(item.SubcategoryIsPublic)).
+		Set(expression.Name(applingodictionary.ColumnLevelSubcategoryIsPublic), expression.Value(item.LevelSubcategoryIsPublic)).
+		Set(expression.Name(applingodictionary.ColumnTopicIsPublic), expression.Value(item.TopicIsPublic)).
+		Set(expression.Name(applingodictionary.ColumnTopicGroupIsPublic), expression.Value(item.TopicGroupIsPublic))
+	condition := expression.AttributeExists(expression.Name(applingodictionary.ColumnId))
+	if owner {
+		update = update.Set(expression.Name(applingodictionary.ColumnOwner), expression.Value(item.Owner))
+		condition = condition.And(expression.AttributeNotExists(expression.Name(applingodictionary.ColumnOwner)))
+	}
+
+	// metadata changes bump the revision, so a changed item is not overwritten with stale keys.
+	revision := expression.Name(applingodictionary.ColumnRevision).Equal(expression.Value(item.Revision))
+	if item.Revision == 0 {
+		revision = expression.Or(expression.AttributeNotExists(expression.Name(applingodictionary.ColumnRevision)), revision)
+	}
+	return dynamo.Update(ctx, applingodictionary.TableSchema.TableName, key, update, condition.And(revision))
+}
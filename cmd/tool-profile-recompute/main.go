@@ -0,0 +1,129 @@
+// Package main implements a tool which recomputes the level and the XP of profiles from their
+// events ledger. Pass profile ids as arguments to recompute them or nothing to recompute all profiles.
+// Profiles without ledger events keep the progress reported before the ledger and are skipped.
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprofile"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/ledger"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	dynamotypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const scanLimit = 100
+
+func main() {
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+	dynamo := cloud.NewDynamo(cfg)
+
+	var checked, updated int
+	visit := func(profile applingoprofile.SchemaItem) {
+		checked++
+		ok, err := recompute(ctx, dynamo, profile)
+		if err != nil {
+			var conditionErr *dynamotypes.ConditionalCheckFailedException
+			if errors.As(err, &conditionErr) {
+				log.Printf("Profile %s changed during recompute, skipped", profile.Id)
+				return
+			}
+			log.Fatalf("Error recomputing profile %s: %v", profile.Id, err)
+		}
+		if ok {
+			updated++
+		}
+	}
+
+	if ids := os.Args[1:]; len(ids) > 0 {
+		for _, id := range ids {
+			out, err := dynamo.Get(ctx, applingoprofile.TableSchema.TableName, map[string]dynamotypes.AttributeValue{
+				applingoprofile.ColumnId: &dynamotypes.AttributeValueMemberS{Value: id},
+			})
+			if err != nil {
+				log.Fatalf("Error getting profile %s: %v", id, err)
+			}
+			if out == nil || len(out.Item) == 0 {
+				log.Printf("Profile %s not found, skipped", id)
+				continue
+			}
+			var profile applingoprofile.SchemaItem
+			if err := attributevalue.UnmarshalMap(out.Item, &profile); err != nil {
+				log.Fatalf("Error unmarshaling profile %s: %v", id, err)
+			}
+			visit(profile)
+		}
+		log.Printf("Checked %d profiles, updated %d", checked, updated)
+		return
+	}
+
+	table := applingoprofile.TableSchema.TableName
+	var lastEvaluatedKey map[string]dynamotypes.AttributeValue
+	for {
+		result, err := dynamo.Scan(ctx, table, dynamo.BuildScanInput(table, scanLimit, lastEvaluatedKey))
+		if err != nil {
+			log.Fatalf("Error scanning profiles: %v", err)
+		}
+		var profiles []applingoprofile.SchemaItem
+		if err = attributevalue.UnmarshalListOfMaps(result.Items, &profiles); err != nil {
+			log.Fatalf("Error unmarshaling profiles: %v", err)
+		}
+		for _, profile := range profiles {
+			visit(profile)
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastEvaluatedKey = result.LastEvaluatedKey
+	}
+	log.Printf("Checked %d profiles, updated %d", checked, updated)
+}
+
+// recompute writes the progress derived from the ledger if it differs from the stored one.
+// The revision is bumped, so events recorded concurrently are not overwritten with a stale sum.
+func recompute(ctx context.Context, dynamo cloud.DynamoAPI, profile applingoprofile.SchemaItem) (bool, error) {
+	total, err := ledger.Sum(ctx, dynamo, profile.Id)
+	if err != nil {
+		return false, err
+	}
+	if total == 0 && profile.XpTotal == 0 {
+		return false, nil
+	}
+	level, xp := ledger.Level(total)
+	if profile.XpTotal == total && profile.Level == level && profile.Xp == xp {
+		return false, nil
+	}
+
+	update := expression.
+		Set(expression.Name(applingoprofile.ColumnLevel), expression.Value(level)).
+		Set(expression.Name(applingoprofile.ColumnXp), expression.Value(xp)).
+		Set(expression.Name(applingoprofile.ColumnXpTotal), expression.Value(total)).
+		Set(expression.Name(applingoprofile.ColumnRevision), expression.Value(profile.Revision+1))
+
+	revision := expression.Name(applingoprofile.ColumnRevision).Equal(expression.Value(profile.Revision))
+	if profile.Revision == 0 {
+		revision = expression.Or(expression.AttributeNotExists(expression.Name(applingoprofile.ColumnRevision)), revision)
+	}
+	key := map[string]dynamotypes.AttributeValue{
+		applingoprofile.ColumnId: &dynamotypes.AttributeValueMemberS{Value: profile.Id},
+	}
+	if err := dynamo.Update(ctx, applingoprofile.TableSchema.TableName, key, update,
+		expression.AttributeExists(expression.Name(applingoprofile.ColumnId)).And(revision),
+	); err != nil {
+		return false, err
+	}
+	log.Printf("Profile %s recomputed: level %d, xp %d, total %d", profile.Id, level, xp, total)
+	return true, nil
+}
@@ -1,5 +1,5 @@
 // Package main implements a Lambda function to handle DynamoDB REMOVE events
-// and delete associated files from the S3 dictionary bucket.
+// and delete associated files and versions from the S3 dictionary bucket.
 package main
 
 import (
@@ -27,7 +27,8 @@ var (
 	serviceDictionaryBucket = os.Getenv("SERVICE_DICTIONARY_BUCKET")
 	awsRegion               = os.Getenv("AWS_REGION")
 
-	s3Bucket *cloud.Bucket
+	dbDynamo cloud.DynamoAPI
+	s3Bucket cloud.BucketAPI
 )
 
 func init() {
@@ -37,10 +38,11 @@ func init() {
 	if err != nil {
 		panic("unable to load AWS SDK config: " + err.Error())
 	}
+	dbDynamo = cloud.NewDynamo(cfg)
 	s3Bucket = cloud.NewBucket(cfg)
 }
 
-// handler processes DynamoDB REMOVE events and deletes corresponding files from S3.
+// handler processes DynamoDB REMOVE events and deletes corresponding files and versions.
 func handler(ctx context.Context, _ zerolog.Logger, record json.RawMessage) error {
 	var dynamoDBEvent events.DynamoDBEventRecord
 	if err := serializer.UnmarshalJSON(record, &dynamoDBEvent); err != nil {
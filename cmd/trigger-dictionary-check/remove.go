@@ -2,19 +2,28 @@ package main
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
-	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+	"github.com/Mad-Pixels/applingo-api/pkg/versions"
+
 	"github.com/aws/aws-lambda-go/events"
 )
 
 func remove(ctx context.Context, e events.DynamoDBEventRecord) error {
-	var fileID string
-	if id, ok := e.Change.Keys[applingodictionary.ColumnId]; ok {
-		fileID = utils.RecordToFileID(id.String())
-	}
-	if fileID == "" {
+	id, ok := e.Change.Keys[applingodictionary.ColumnId]
+	if !ok || id.String() == "" {
 		return nil
 	}
-	return s3Bucket.Delete(ctx, fileID, serviceDictionaryBucket)
+
+	// the removed item is only available as the old image.
+	oldItem, err := applingodictionary.ExtractFromDynamoDBStreamEvent(events.DynamoDBEventRecord{
+		Change: events.DynamoDBStreamRecord{
+			NewImage: e.Change.OldImage,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed extract old data: %w", err)
+	}
+	return versions.NewStore(dbDynamo, s3Bucket, serviceDictionaryBucket).Remove(ctx, id.String(), oldItem.Version)
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingosearch"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/search"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const tokensQueryLimit = 500
+
+// indexChanged reports whether the modification touches fields kept in the search index.
+func indexChanged(oldItem, newItem *applingodictionary.SchemaItem) bool {
+	return document(oldItem) != document(newItem) || oldItem.IsPublic != newItem.IsPublic
+}
+
+func document(item *applingodictionary.SchemaItem) search.Document {
+	return search.Document{
+		Name:        item.Name,
+		Description: item.Description,
+		Topic:       item.Topic,
+		Author:      item.Author,
+	}
+}
+
+// reindex replaces the index entries of the dictionary.
+// Private dictionaries are not searchable, so their entries are dropped.
+func reindex(ctx context.Context, item *applingodictionary.SchemaItem) error {
+	var postings map[string]search.Posting
+	if applingodictionary.IntToBool(item.IsPublic) {
+		postings = search.Index(document(item))
+	}
+	return update(ctx, item.Id, item.Subcategory, postings)
+}
+
+// remove drops the index entries of a deleted dictionary.
+func remove(ctx context.Context, e events.DynamoDBEventRecord) error {
+	id, ok := e.Change.Keys[applingodictionary.ColumnId]
+	if !ok || id.String() == "" {
+		return nil
+	}
+	return update(ctx, id.String(), "", nil)
+}
+
+func update(ctx context.Context, dictionaryID, subcategory string, postings map[string]search.Posting) error {
+	tokens, err := indexedTokens(ctx, dictionaryID)
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if _, ok := postings[token]; ok {
+			continue
+		}
+		key, err := applingosearch.CreateKey(token, dictionaryID)
+		if err != nil {
+			return fmt.Errorf("failed to create index key: %w", err)
+		}
+		if err = dbDynamo.Delete(ctx, applingosearch.TableSchema.TableName, key); err != nil {
+			return fmt.Errorf("failed to delete index entry %q: %w", token, err)
+		}
+	}
+
+	items := make([]map[string]types.AttributeValue, 0, len(postings))
+	for token, posting := range postings {
+		item, err := applingosearch.PutItem(applingosearch.SchemaItem{
+			Token:        token,
+			DictionaryId: dictionaryID,
+			Subcategory:  subcategory,
+			Weight:       posting.Weight,
+			PrefixWeight: posting.PrefixWeight,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to prepare index entry: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err = dbDynamo.BatchWrite(ctx, applingosearch.TableSchema.TableName, items); err != nil {
+		return fmt.Errorf("failed to write index entries: %w", err)
+	}
+	return nil
+}
+
+// indexedTokens returns the tokens currently indexed for the dictionary.
+func indexedTokens(ctx context.Context, dictionaryID string) ([]string, error) {
+	var (
+		tokens   []string
+		startKey map[string]types.AttributeValue
+	)
+	for {
+		queryInput, err := dbDynamo.BuildQueryInput(cloud.QueryInput{
+			IndexName:         applingosearch.IndexDictionaryIndex,
+			KeyCondition:      expression.Key(applingosearch.ColumnDictionaryId).Equal(expression.Value(dictionaryID)),
+			Limit:             tokensQueryLimit,
+			ScanForward:       true,
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build index query: %w", err)
+		}
+		result, err := dbDynamo.Query(ctx, applingosearch.TableSchema.TableName, queryInput)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query index: %w", err)
+		}
+		for _, item := range result.Items {
+			if token, ok := item[applingosearch.ColumnToken].(*types.AttributeValueMemberS); ok {
+				tokens = append(tokens, token.Value)
+			}
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return tokens, nil
+		}
+		startKey = result.LastEvaluatedKey
+	}
+}
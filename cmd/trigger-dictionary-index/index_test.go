@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingosearch"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testID = "0123456789abcdef0123456789abcdef"
+
+func setupIndex(t *testing.T) {
+	t.Helper()
+
+	dbDynamo = cloud.NewMemoryDynamo(cloud.NewMemoryTable(applingosearch.TableSchema))
+}
+
+func image(name, topic string, public, rating int) map[string]events.DynamoDBAttributeValue {
+	return map[string]events.DynamoDBAttributeValue{
+		applingodictionary.ColumnId:          events.NewStringAttribute(testID),
+		applingodictionary.ColumnSubcategory: events.NewStringAttribute("en-ru"),
+		applingodictionary.ColumnName:        events.NewStringAttribute(name),
+		applingodictionary.ColumnTopic:       events.NewStringAttribute(topic),
+		applingodictionary.ColumnAuthor:      events.NewStringAttribute("author"),
+		applingodictionary.ColumnIsPublic:    events.NewNumberAttribute(strconv.Itoa(public)),
+		applingodictionary.ColumnRating:      events.NewNumberAttribute(strconv.Itoa(rating)),
+	}
+}
+
+func handle(t *testing.T, name string, oldImage, newImage map[string]events.DynamoDBAttributeValue) {
+	t.Helper()
+
+	record := events.DynamoDBEventRecord{
+		EventName: name,
+		Change: events.DynamoDBStreamRecord{
+			Keys: map[string]events.DynamoDBAttributeValue{
+				applingodictionary.ColumnId:          events.NewStringAttribute(testID),
+				applingodictionary.ColumnSubcategory: events.NewStringAttribute("en-ru"),
+			},
+			OldImage: oldImage,
+			NewImage: newImage,
+		},
+	}
+	raw, err := serializer.MarshalJSON(record)
+	require.NoError(t, err)
+	require.NoError(t, handler(context.Background(), zerolog.Nop(), raw))
+}
+
+func tokens(t *testing.T) []string {
+	t.Helper()
+
+	out, err := indexedTokens(context.Background(), testID)
+	require.NoError(t, err)
+	return out
+}
+
+func TestHandlerMaintainsIndex(t *testing.T) {
+	setupIndex(t)
+
+	inserted := image("Wild animals", "nature", 1, 0)
+	handle(t, "INSERT", nil, inserted)
+	assert.Subset(t, tokens(t), []string{"wild", "animals", "an", "nature", "author"})
+
+	entry, err := indexEntry("animals")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, "en-ru", entry.Subcategory)
+	assert.Positive(t, entry.Weight)
+
+	renamed := image("Pets", "nature", 1, 0)
+	handle(t, "MODIFY", inserted, renamed)
+	assert.NotContains(t, tokens(t), "animals")
+	assert.Contains(t, tokens(t), "pets")
+
+	private := image("Pets", "nature", 0, 0)
+	handle(t, "MODIFY", renamed, private)
+	assert.Empty(t, tokens(t))
+
+	handle(t, "MODIFY", private, renamed)
+	require.NotEmpty(t, tokens(t))
+
+	handle(t, "REMOVE", renamed, nil)
+	assert.Empty(t, tokens(t))
+}
+
+func TestHandlerSkipsStatistics(t *testing.T) {
+	setupIndex(t)
+
+	// a rating change must not recreate entries dropped out of band.
+	handle(t, "MODIFY", image("Pets", "nature", 1, 0), image("Pets", "nature", 1, 5))
+	assert.Empty(t, tokens(t))
+}
+
+func indexEntry(token string) (*applingosearch.SchemaItem, error) {
+	key, err := applingosearch.CreateKey(token, testID)
+	if err != nil {
+		return nil, err
+	}
+	out, err := dbDynamo.Get(context.Background(), applingosearch.TableSchema.TableName, key)
+	if err != nil || out.Item == nil {
+		return nil, err
+	}
+	var item applingosearch.SchemaItem
+	if err := attributevalue.UnmarshalMap(out.Item, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
@@ -0,0 +1,86 @@
+// Package main implements a Lambda function which consumes the dictionary table stream
+// and keeps the search inverted index in sync with dictionary names, topics, authors and descriptions.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime/debug"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+	"github.com/Mad-Pixels/applingo-api/pkg/trigger"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// records of a shard are applied in order, so a later change always wins.
+	defaultMaxWorkers = 1
+)
+
+var (
+	awsRegion = os.Getenv("AWS_REGION")
+
+	dbDynamo cloud.DynamoAPI
+)
+
+func init() {
+	debug.SetGCPercent(500)
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
+	if err != nil {
+		panic("unable to load AWS SDK config: " + err.Error())
+	}
+	dbDynamo = cloud.NewDynamo(cfg)
+}
+
+// handler rebuilds the index entries of the changed dictionary.
+func handler(ctx context.Context, log zerolog.Logger, record json.RawMessage) error {
+	var dynamoDBEvent events.DynamoDBEventRecord
+	if err := serializer.UnmarshalJSON(record, &dynamoDBEvent); err != nil {
+		return fmt.Errorf("failed to unmarshal request record: %w", err)
+	}
+
+	switch dynamoDBEvent.EventName {
+	case "INSERT", "MODIFY":
+		newItem, err := applingodictionary.ExtractFromDynamoDBStreamEvent(dynamoDBEvent)
+		if err != nil {
+			return fmt.Errorf("failed to extract dictionary from stream: %w", err)
+		}
+		if dynamoDBEvent.EventName == "MODIFY" {
+			oldItem, err := applingodictionary.ExtractFromDynamoDBStreamEvent(events.DynamoDBEventRecord{
+				Change: events.DynamoDBStreamRecord{NewImage: dynamoDBEvent.Change.OldImage},
+			})
+			if err == nil && !indexChanged(oldItem, newItem) {
+				return nil
+			}
+		}
+		log.Info().Str("id", newItem.Id).Msg("Reindex dictionary")
+		if err := reindex(ctx, newItem); err != nil {
+			return fmt.Errorf("failed to reindex dictionary: %w", err)
+		}
+	case "REMOVE":
+		if err := remove(ctx, dynamoDBEvent); err != nil {
+			return fmt.Errorf("failed to remove dictionary from index: %w", err)
+		}
+	}
+	return nil
+}
+
+func main() {
+	lambda.Start(
+		trigger.NewLambda(
+			trigger.Config{
+				MaxWorkers: defaultMaxWorkers,
+			},
+			handler,
+		).Handle,
+	)
+}
@@ -0,0 +1,79 @@
+// Package main implements a Lambda function which validates dictionary files uploaded to the processing bucket.
+// Invalid files are deleted from the bucket and the reason is written to their processing records,
+// files uploaded by users under their prefix have no records and are only deleted.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+	"github.com/Mad-Pixels/applingo-api/pkg/trigger"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/rs/zerolog"
+)
+
+const (
+	objectCreatedEvent = "ObjectCreated:"
+	defaultMaxWorkers  = 5
+)
+
+var (
+	serviceProcessingBucket = os.Getenv("SERVICE_PROCESSING_BUCKET")
+	awsRegion               = os.Getenv("AWS_REGION")
+
+	dbDynamo cloud.DynamoAPI
+	s3Bucket cloud.BucketAPI
+)
+
+func init() {
+	debug.SetGCPercent(500)
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
+	if err != nil {
+		panic("unable to load AWS SDK config: " + err.Error())
+	}
+	s3Bucket = cloud.NewBucket(cfg)
+	dbDynamo = cloud.NewDynamo(cfg)
+}
+
+// handler validates the uploaded file, objects which are not dictionary files are skipped.
+func handler(ctx context.Context, log zerolog.Logger, record json.RawMessage) error {
+	var s3Event events.S3EventRecord
+	if err := serializer.UnmarshalJSON(record, &s3Event); err != nil {
+		return fmt.Errorf("failed to unmarshal request record: %w", err)
+	}
+	if !strings.HasPrefix(s3Event.EventName, objectCreatedEvent) {
+		return nil
+	}
+
+	key := s3Event.S3.Object.URLDecodedKey
+	if (key == "" || !utils.IsFileID(key)) && !utils.IsUploadFileID(key) {
+		log.Info().Str("key", key).Msg("Skip object, not a dictionary file")
+		return nil
+	}
+	if err := validate(ctx, log, key); err != nil {
+		return fmt.Errorf("failed to validate %s: %w", key, err)
+	}
+	return nil
+}
+
+func main() {
+	lambda.Start(
+		trigger.NewLambda(
+			trigger.Config{
+				MaxWorkers: defaultMaxWorkers,
+			},
+			handler,
+		).Handle,
+	)
+}
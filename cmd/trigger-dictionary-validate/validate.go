@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprocessing"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/dictionary"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/rs/zerolog"
+)
+
+const recordsQueryLimit = 100
+
+// validate checks the file and rejects it from the pipeline if it is invalid.
+// The reason is written before the file is deleted, so the processing lambda
+// which finds the file missing does not overwrite it.
+func validate(ctx context.Context, log zerolog.Logger, key string) error {
+	report, err := dictionary.ValidateObject(ctx, s3Bucket, key, serviceProcessingBucket)
+	if err != nil {
+		if errors.Is(err, cloud.ErrBucketObjectNotFound) {
+			log.Info().Str("key", key).Msg("Skip object, file was removed")
+			return nil
+		}
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if report.Valid() {
+		log.Info().Str("key", key).Int("words", report.Words).Msg("Dictionary file validated")
+		return nil
+	}
+
+	log.Warn().Str("key", key).Str("reason", report.Reason()).Msg("Reject dictionary file")
+	if utils.IsFileID(key) {
+		if err := reject(ctx, strings.TrimSuffix(key, filepath.Ext(key)), report.Reason()); err != nil {
+			return fmt.Errorf("failed to reject records: %w", err)
+		}
+	}
+	if err := s3Bucket.Delete(ctx, key, serviceProcessingBucket); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// reject writes the reason to the processing records of the file, published records are left as is.
+func reject(ctx context.Context, id, reason string) error {
+	var startKey map[string]types.AttributeValue
+	for {
+		queryInput, err := dbDynamo.BuildQueryInput(cloud.QueryInput{
+			KeyCondition:      expression.Key(applingoprocessing.ColumnId).Equal(expression.Value(id)),
+			Limit:             recordsQueryLimit,
+			ScanForward:       true,
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to build records query: %w", err)
+		}
+		result, err := dbDynamo.Query(ctx, applingoprocessing.TableSchema.TableName, queryInput)
+		if err != nil {
+			return fmt.Errorf("failed to query records: %w", err)
+		}
+		for _, item := range result.Items {
+			var record applingoprocessing.SchemaItem
+			if err := attributevalue.UnmarshalMap(item, &record); err != nil {
+				return fmt.Errorf("failed to unmarshal record: %w", err)
+			}
+			if applingoprocessing.IntToBool(record.Upload) {
+				continue
+			}
+			if err := rejectRecord(ctx, record, reason); err != nil {
+				return err
+			}
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		startKey = result.LastEvaluatedKey
+	}
+}
+
+func rejectRecord(ctx context.Context, record applingoprocessing.SchemaItem, reason string) error {
+	key, err := applingoprocessing.CreateKeyFromItem(record)
+	if err != nil {
+		return fmt.Errorf("failed to create key for record: %w", err)
+	}
+	update := expression.
+		Set(
+			expression.Name(applingoprocessing.ColumnScore),
+			expression.Value(0),
+		).
+		Set(
+			expression.Name(applingoprocessing.ColumnReason),
+			expression.Value(reason),
+		)
+	condition := expression.AttributeExists(expression.Name(applingoprocessing.ColumnId))
+	return dbDynamo.Update(ctx, applingoprocessing.TableSchema.TableName, key, update, condition)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprocessing"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/dictionary"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testID = "0123456789abcdef0123456789abcdef"
+
+func setupStorage(t *testing.T) (*cloud.MemoryDynamo, *cloud.MemoryBucket) {
+	t.Helper()
+
+	db := cloud.NewMemoryDynamo(cloud.NewMemoryTable(applingoprocessing.TableSchema))
+	bucket := cloud.NewMemoryBucket()
+
+	dbDynamo, s3Bucket = db, bucket
+	serviceProcessingBucket = "processing"
+	return db, bucket
+}
+
+func putRecord(t *testing.T, db *cloud.MemoryDynamo, item applingoprocessing.SchemaItem) {
+	t.Helper()
+
+	record, err := applingoprocessing.PutItem(item)
+	require.NoError(t, err)
+	require.NoError(t, db.Put(context.Background(), applingoprocessing.TableSchema.TableName, record, expression.ConditionBuilder{}))
+}
+
+func getRecord(t *testing.T, db *cloud.MemoryDynamo, item applingoprocessing.SchemaItem) applingoprocessing.SchemaItem {
+	t.Helper()
+
+	key, err := applingoprocessing.CreateKeyFromItem(item)
+	require.NoError(t, err)
+	out, err := db.Get(context.Background(), applingoprocessing.TableSchema.TableName, key)
+	require.NoError(t, err)
+
+	var record applingoprocessing.SchemaItem
+	require.NoError(t, attributevalue.UnmarshalMap(out.Item, &record))
+	return record
+}
+
+func handle(t *testing.T, eventName, key string) {
+	t.Helper()
+
+	record, err := json.Marshal(events.S3EventRecord{
+		EventName: eventName,
+		S3: events.S3Entity{
+			Bucket: events.S3Bucket{Name: serviceProcessingBucket},
+			Object: events.S3Object{Key: key},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, handler(context.Background(), zerolog.Nop(), record))
+}
+
+func TestHandler(t *testing.T) {
+	ctx := context.Background()
+	fileID := utils.RecordToFileID(testID)
+
+	t.Run("valid file", func(t *testing.T) {
+		db, bucket := setupStorage(t)
+		item := applingoprocessing.SchemaItem{Id: testID, Created: 1, Reason: "waiting for check"}
+		putRecord(t, db, item)
+		require.NoError(t, bucket.Put(ctx, fileID, serviceProcessingBucket, strings.NewReader(`{"words":[{"word":"cat","translation":"кот"}]}`), cloud.ContentTypeJSON))
+
+		handle(t, "ObjectCreated:Put", fileID)
+
+		exists, err := bucket.Exists(ctx, fileID, serviceProcessingBucket)
+		require.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "waiting for check", getRecord(t, db, item).Reason)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		db, bucket := setupStorage(t)
+		pending := applingoprocessing.SchemaItem{Id: testID, Created: 1, Reason: "waiting for check", Score: 50}
+		published := applingoprocessing.SchemaItem{Id: testID, Created: 2, Reason: "published", Score: 95, Upload: 1}
+		putRecord(t, db, pending)
+		putRecord(t, db, published)
+		require.NoError(t, bucket.Put(ctx, fileID, serviceProcessingBucket, strings.NewReader(`{"words":[{"word":"cat","translation":""}]}`), cloud.ContentTypeJSON))
+
+		handle(t, "ObjectCreated:Put", fileID)
+
+		exists, err := bucket.Exists(ctx, fileID, serviceProcessingBucket)
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		record := getRecord(t, db, pending)
+		assert.Equal(t, dictionary.RejectedPrefix+"word 1: empty translation", record.Reason)
+		assert.Zero(t, record.Score)
+		assert.Equal(t, published, getRecord(t, db, published))
+	})
+
+	t.Run("invalid upload", func(t *testing.T) {
+		db, bucket := setupStorage(t)
+		item := applingoprocessing.SchemaItem{Id: testID, Created: 1, Reason: "waiting for check"}
+		putRecord(t, db, item)
+		uploadID := utils.UploadFileID("42", testID)
+		require.NoError(t, bucket.Put(ctx, uploadID, serviceProcessingBucket, strings.NewReader(`{"words":[]}`), cloud.ContentTypeJSON))
+
+		handle(t, "ObjectCreated:Put", uploadID)
+
+		exists, err := bucket.Exists(ctx, uploadID, serviceProcessingBucket)
+		require.NoError(t, err)
+		assert.False(t, exists)
+		assert.Equal(t, "waiting for check", getRecord(t, db, item).Reason)
+	})
+
+	t.Run("skipped objects", func(t *testing.T) {
+		_, bucket := setupStorage(t)
+		require.NoError(t, bucket.Put(ctx, "notes.txt", serviceProcessingBucket, strings.NewReader(`words`), cloud.ContentTypeJSON))
+		require.NoError(t, bucket.Put(ctx, fileID, serviceProcessingBucket, strings.NewReader(`words`), cloud.ContentTypeJSON))
+
+		handle(t, "ObjectCreated:Put", "notes.txt")
+		handle(t, "ObjectCreated:Put", "uploads/notes/"+fileID)
+		handle(t, "ObjectRemoved:Delete", fileID)
+		handle(t, "ObjectCreated:Put", utils.RecordToFileID("fedcba9876543210fedcba9876543210"))
+
+		for _, key := range []string{"notes.txt", fileID} {
+			exists, err := bucket.Exists(ctx, key, serviceProcessingBucket)
+			require.NoError(t, err)
+			assert.True(t, exists, key)
+		}
+	})
+}
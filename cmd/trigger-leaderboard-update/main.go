@@ -0,0 +1,79 @@
+// Package main implements a Lambda function which consumes the ledger table stream
+// and adds the XP of new ledger events to the weekly and all time leaderboards.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoledger"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/leaderboard"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+	"github.com/Mad-Pixels/applingo-api/pkg/trigger"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// events add XP atomically and are counted once, so records do not need to be applied in order.
+	defaultMaxWorkers = 4
+)
+
+var (
+	awsRegion = os.Getenv("AWS_REGION")
+
+	dbDynamo cloud.DynamoAPI
+)
+
+func init() {
+	debug.SetGCPercent(500)
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(awsRegion))
+	if err != nil {
+		panic("unable to load AWS SDK config: " + err.Error())
+	}
+	dbDynamo = cloud.NewDynamo(cfg)
+}
+
+// handler adds the XP of the inserted ledger event to its boards, updates of events only mark them as ranked.
+func handler(ctx context.Context, log zerolog.Logger, record json.RawMessage) error {
+	var dynamoDBEvent events.DynamoDBEventRecord
+	if err := serializer.UnmarshalJSON(record, &dynamoDBEvent); err != nil {
+		return fmt.Errorf("failed to unmarshal request record: %w", err)
+	}
+	if dynamoDBEvent.EventName != "INSERT" {
+		return nil
+	}
+
+	event, err := applingoledger.ExtractFromDynamoDBStreamEvent(dynamoDBEvent)
+	if err != nil {
+		return fmt.Errorf("failed to extract ledger event from stream: %w", err)
+	}
+	added, err := leaderboard.Add(ctx, dbDynamo, *event, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update leaderboards: %w", err)
+	}
+	if added {
+		log.Info().Str("event", event.Id).Int("xp", event.Xp).Msg("Event added to leaderboards")
+	}
+	return nil
+}
+
+func main() {
+	lambda.Start(
+		trigger.NewLambda(
+			trigger.Config{
+				MaxWorkers: defaultMaxWorkers,
+			},
+			handler,
+		).Handle,
+	)
+}
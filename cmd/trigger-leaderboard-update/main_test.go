@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoleaderboard"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoledger"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/leaderboard"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTables(t *testing.T) {
+	t.Helper()
+
+	dbDynamo = cloud.NewMemoryDynamo(cloud.NewMemoryTable(applingoleaderboard.TableSchema), cloud.NewMemoryTable(applingoledger.TableSchema))
+}
+
+func handle(t *testing.T, name string, event applingoledger.SchemaItem) {
+	t.Helper()
+
+	record := events.DynamoDBEventRecord{
+		EventName: name,
+		Change: events.DynamoDBStreamRecord{
+			Keys: map[string]events.DynamoDBAttributeValue{
+				applingoledger.ColumnProfileId: events.NewStringAttribute(event.ProfileId),
+				applingoledger.ColumnId:        events.NewStringAttribute(event.Id),
+			},
+			NewImage: map[string]events.DynamoDBAttributeValue{
+				applingoledger.ColumnProfileId:   events.NewStringAttribute(event.ProfileId),
+				applingoledger.ColumnId:          events.NewStringAttribute(event.Id),
+				applingoledger.ColumnKind:        events.NewStringAttribute(event.Kind),
+				applingoledger.ColumnXp:          events.NewNumberAttribute(strconv.Itoa(event.Xp)),
+				applingoledger.ColumnSubcategory: events.NewStringAttribute(event.Subcategory),
+				applingoledger.ColumnCreated:     events.NewNumberAttribute(strconv.Itoa(event.Created)),
+				applingoledger.ColumnRanked:      events.NewNumberAttribute("0"),
+			},
+		},
+	}
+	raw, err := serializer.MarshalJSON(record)
+	require.NoError(t, err)
+	require.NoError(t, handler(context.Background(), zerolog.Nop(), raw))
+}
+
+func TestHandler(t *testing.T) {
+	setupTables(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	event := applingoledger.SchemaItem{ProfileId: "profile", Id: "event", Kind: "words_reviewed", Xp: 20, Subcategory: "en-ru", Created: int(now.Unix())}
+	av, err := applingoledger.PutItem(event)
+	require.NoError(t, err)
+	require.NoError(t, dbDynamo.Put(ctx, applingoledger.TableSchema.TableName, av, expression.ConditionBuilder{}))
+
+	handle(t, "INSERT", event)
+	// redelivered and modified records do not add XP again.
+	handle(t, "INSERT", event)
+	handle(t, "MODIFY", event)
+
+	for _, board := range []string{
+		leaderboard.Board(leaderboard.PeriodAllTime, now, ""),
+		leaderboard.Board(leaderboard.PeriodAllTime, now, "en-ru"),
+		leaderboard.Board(leaderboard.PeriodWeekly, now, ""),
+		leaderboard.Board(leaderboard.PeriodWeekly, now, "en-ru"),
+	} {
+		entry, found, err := leaderboard.Get(ctx, dbDynamo, board, "profile")
+		require.NoError(t, err, board)
+		require.True(t, found, board)
+		assert.Equal(t, 20, entry.XP, board)
+	}
+}
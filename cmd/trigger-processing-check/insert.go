@@ -17,6 +17,11 @@ func insert(ctx context.Context, e events.DynamoDBEventRecord) error {
 	if err != nil {
 		return fmt.Errorf("failed to extract item from DynamoDB event: %w", err)
 	}
+	// invalid files are rejected before they are sent to the model.
+	valid, err := checkFile(ctx, item)
+	if err != nil || !valid {
+		return err
+	}
 
 	var (
 		req  = forge.NewRequestDictionaryCheck()
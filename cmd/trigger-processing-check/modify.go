@@ -2,17 +2,23 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
 	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprocessing"
+	"github.com/Mad-Pixels/applingo-api/lingo-interface/types"
 	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+	"github.com/Mad-Pixels/applingo-api/pkg/versions"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 )
 
+// versionActor is recorded as the publisher of dictionaries uploaded from processing.
+const versionActor = "processing"
+
 type changes struct {
 	// process
 	score  bool
@@ -60,6 +66,11 @@ func modify(ctx context.Context, e events.DynamoDBEventRecord) error {
 	c := detectChanges(oldItem, newItem)
 
 	if c.needProcess {
+		// the file could be replaced after the check, it is validated again before publishing.
+		valid, err := checkFile(ctx, c.newItem)
+		if err != nil || !valid {
+			return err
+		}
 		if err := processRecordToDictionary(ctx, &c); err != nil {
 			return fmt.Errorf("failed to process record: %w", err)
 		}
@@ -104,7 +115,7 @@ func processRecordToDictionary(ctx context.Context, c *changes) error {
 	}
 
 	// prepare dynamo item.
-	schemaItem := applingodictionary.SchemaItem{
+	schemaItem := applingodictionary.NewSchemaItem(applingodictionary.SchemaItem{
 		// identifier.
 		Id:          c.newItem.Id,
 		Subcategory: c.newItem.Subcategory,
@@ -114,6 +125,7 @@ func processRecordToDictionary(ctx context.Context, c *changes) error {
 		Author:      c.newItem.Author,
 		Name:        c.newItem.Name,
 		Topic:       c.newItem.Topic,
+		TopicGroup:  types.TopicGroupOf(c.newItem.Topic).String(),
 		Level:       c.newItem.Level,
 		Words:       c.newItem.Words,
 
@@ -121,38 +133,17 @@ func processRecordToDictionary(ctx context.Context, c *changes) error {
 		IsPublic: applingodictionary.BoolToInt(true),
 		Created:  int(time.Now().Unix()),
 		Category: "Languages",
-
-		// composite keys.
-		LevelSubcategoryIsPublic: fmt.Sprintf("%s#%s#%d", c.newItem.Level, c.newItem.Subcategory, applingodictionary.BoolToInt(true)),
-		SubcategoryIsPublic:      fmt.Sprintf("%s#%d", c.newItem.Subcategory, applingodictionary.BoolToInt(true)),
-		LevelIsPublic:            fmt.Sprintf("%s#%d", c.newItem.Level, applingodictionary.BoolToInt(true)),
-	}
-	dynamoItem, err := applingodictionary.PutItem(schemaItem)
-	if err != nil {
-		return fmt.Errorf("failed prepare dynamo item: %w", err)
-	}
+	})
 	dictionaryFileID := utils.RecordToFileID(c.newItem.Id)
 
-	// copy dictionary data.
-	if err = s3Bucket.Copy(ctx, dictionaryFileID, serviceProcessingBucket, dictionaryFileID, serviceDictionaryBucket); err != nil {
-		return fmt.Errorf("failed to copy dictionary from processing to service: %w", err)
-	}
-	if err = s3Bucket.WaitOrError(ctx, dictionaryFileID, serviceDictionaryBucket, 3, 200*time.Millisecond); err != nil {
-		return fmt.Errorf("failed to check object in bucket")
-	}
-
-	// insert data to dynamoDB.
-	if err := dbDynamo.Put(
-		ctx,
-		applingodictionary.TableSchema.TableName,
-		dynamoItem,
-		expression.AttributeNotExists(expression.Name(applingodictionary.ColumnId)),
-	); err != nil {
-		s3Err := s3Bucket.Delete(ctx, dictionaryFileID, serviceProcessingBucket)
-		if s3Err != nil {
-			return fmt.Errorf("failed add new dictionary in dynamoDB: %w, also cannot delete dictionary from bucket: %w", err, s3Err)
+	// copy dictionary data as the first version and insert data to dynamoDB.
+	store := versions.NewStore(dbDynamo, s3Bucket, serviceDictionaryBucket)
+	if err := store.Create(ctx, schemaItem, dictionaryFileID, serviceProcessingBucket, versionActor); err != nil {
+		if errors.Is(err, versions.ErrConflict) {
+			fmt.Printf("Record with ID %s was published concurrently, skipping\n", c.newItem.Id)
+			return nil
 		}
-		return fmt.Errorf("failed add new dictionary in dynamoDB: %w, dictionary was removed from bucket", err)
+		return fmt.Errorf("failed add new dictionary: %w", err)
 	}
 	return nil
 }
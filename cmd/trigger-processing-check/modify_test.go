@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprocessing"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoversion"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/dictionary"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStorage(t *testing.T) (*cloud.MemoryDynamo, *cloud.MemoryBucket) {
+	t.Helper()
+
+	db := cloud.NewMemoryDynamo(
+		cloud.NewMemoryTable(applingodictionary.TableSchema),
+		cloud.NewMemoryTable(applingoprocessing.TableSchema),
+		cloud.NewMemoryTable(applingoversion.TableSchema),
+	)
+	bucket := cloud.NewMemoryBucket()
+
+	dbDynamo, s3Bucket = db, bucket
+	serviceProcessingBucket, serviceDictionaryBucket = "processing", "dictionary"
+	return db, bucket
+}
+
+func TestDetectChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new applingoprocessing.SchemaItem
+		want     bool
+	}{
+		{"manual upload", applingoprocessing.SchemaItem{Upload: 0}, applingoprocessing.SchemaItem{Upload: 1}, true},
+		{"score above threshold", applingoprocessing.SchemaItem{Score: 10}, applingoprocessing.SchemaItem{Score: autoUploadScoreThreshold}, true},
+		{"score below threshold", applingoprocessing.SchemaItem{Score: 10}, applingoprocessing.SchemaItem{Score: 50}, false},
+		{"no changes", applingoprocessing.SchemaItem{Score: 95, Upload: 1}, applingoprocessing.SchemaItem{Score: 95, Upload: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detectChanges(&tt.old, &tt.new).needProcess)
+		})
+	}
+}
+
+func TestProcessRecordToDictionary(t *testing.T) {
+	ctx := context.Background()
+	db, bucket := setupStorage(t)
+
+	item := applingoprocessing.SchemaItem{
+		Id:          "a1b2c3",
+		Subcategory: "en-ru",
+		Name:        "travel words",
+		Author:      "author",
+		Level:       "B1",
+		Topic:       "travel",
+		Overview:    "words for the trip",
+		Words:       20,
+		Score:       95,
+	}
+	processingItem, err := applingoprocessing.PutItem(item)
+	require.NoError(t, err)
+	require.NoError(t, db.Put(ctx, applingoprocessing.TableSchema.TableName, processingItem, expression.ConditionBuilder{}))
+
+	fileID := utils.RecordToFileID(item.Id)
+	require.NoError(t, bucket.Put(ctx, fileID, serviceProcessingBucket, strings.NewReader(`{"words":[{"word":"ticket","translation":"билет"}]}`), cloud.ContentTypeJSON))
+
+	c := detectChanges(&applingoprocessing.SchemaItem{Id: item.Id}, &item)
+	require.True(t, c.needProcess)
+	require.NoError(t, processRecordToDictionary(ctx, &c))
+	require.NoError(t, updateRecordUploadStatus(ctx, &c))
+
+	for _, key := range []string{fileID, utils.VersionFileID(item.Id, 1)} {
+		exists, err := bucket.Exists(ctx, key, serviceDictionaryBucket)
+		require.NoError(t, err)
+		assert.True(t, exists, key)
+	}
+	versionKey, err := applingoversion.CreateKey(item.Id, 1)
+	require.NoError(t, err)
+	exists, err := db.Exists(ctx, applingoversion.TableSchema.TableName, versionKey)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	key, err := applingodictionary.CreateKey(item.Id, item.Subcategory)
+	require.NoError(t, err)
+	out, err := db.Get(ctx, applingodictionary.TableSchema.TableName, key)
+	require.NoError(t, err)
+	require.NotEmpty(t, out.Item)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "B1#en-ru#1"}, out.Item[applingodictionary.ColumnLevelSubcategoryIsPublic])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "en-ru#1"}, out.Item[applingodictionary.ColumnSubcategoryIsPublic])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "B1#1"}, out.Item[applingodictionary.ColumnLevelIsPublic])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: item.Overview}, out.Item[applingodictionary.ColumnDescription])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1"}, out.Item[applingodictionary.ColumnVersion])
+
+	processingKey, err := applingoprocessing.CreateKeyFromItem(item)
+	require.NoError(t, err)
+	out, err = db.Get(ctx, applingoprocessing.TableSchema.TableName, processingKey)
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1"}, out.Item[applingoprocessing.ColumnUpload])
+
+	// the record is already published, processing it again is a no-op.
+	require.NoError(t, processRecordToDictionary(ctx, &c))
+}
+
+func TestCheckFile(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		file   string
+		reason string
+		valid  bool
+	}{
+		{"valid", `{"words":[{"word":"ticket","translation":"билет"}]}`, "waiting for check", true},
+		{"invalid", `{"words":[{"word":"ticket","translation":"билет"},{"word":"Ticket","translation":"билет"}]}`, dictionary.RejectedPrefix + "word 2: duplicate of word 1", false},
+		{"missing", "", missingFileReason, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, bucket := setupStorage(t)
+
+			item := applingoprocessing.SchemaItem{Id: "a1b2c3", Created: 1, Reason: "waiting for check", Score: 95}
+			processingItem, err := applingoprocessing.PutItem(item)
+			require.NoError(t, err)
+			require.NoError(t, db.Put(ctx, applingoprocessing.TableSchema.TableName, processingItem, expression.ConditionBuilder{}))
+			if tt.file != "" {
+				require.NoError(t, bucket.Put(ctx, utils.RecordToFileID(item.Id), serviceProcessingBucket, strings.NewReader(tt.file), cloud.ContentTypeJSON))
+			}
+
+			valid, err := checkFile(ctx, &item)
+			require.NoError(t, err)
+			assert.Equal(t, tt.valid, valid)
+
+			key, err := applingoprocessing.CreateKeyFromItem(item)
+			require.NoError(t, err)
+			out, err := db.Get(ctx, applingoprocessing.TableSchema.TableName, key)
+			require.NoError(t, err)
+			assert.Equal(t, &types.AttributeValueMemberS{Value: tt.reason}, out.Item[applingoprocessing.ColumnReason])
+		})
+	}
+
+	// the reason written by the validator lambda is not replaced.
+	db, _ := setupStorage(t)
+	item := applingoprocessing.SchemaItem{Id: "a1b2c3", Created: 1, Reason: dictionary.RejectedPrefix + "empty translation"}
+	processingItem, err := applingoprocessing.PutItem(item)
+	require.NoError(t, err)
+	require.NoError(t, db.Put(ctx, applingoprocessing.TableSchema.TableName, processingItem, expression.ConditionBuilder{}))
+
+	valid, err := checkFile(ctx, &item)
+	require.NoError(t, err)
+	assert.False(t, valid)
+	key, err := applingoprocessing.CreateKeyFromItem(item)
+	require.NoError(t, err)
+	out, err := db.Get(ctx, applingoprocessing.TableSchema.TableName, key)
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: item.Reason}, out.Item[applingoprocessing.ColumnReason])
+}
+
+func TestRemove(t *testing.T) {
+	ctx := context.Background()
+	_, bucket := setupStorage(t)
+
+	fileID := utils.RecordToFileID("a1b2c3")
+	require.NoError(t, bucket.Put(ctx, fileID, serviceProcessingBucket, strings.NewReader(`{}`), cloud.ContentTypeJSON))
+
+	require.NoError(t, remove(ctx, events.DynamoDBEventRecord{
+		Change: events.DynamoDBStreamRecord{
+			Keys: map[string]events.DynamoDBAttributeValue{
+				applingoprocessing.ColumnId: events.NewStringAttribute("a1b2c3"),
+			},
+		},
+	}))
+	exists, err := bucket.Exists(ctx, fileID, serviceProcessingBucket)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoprocessing"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/dictionary"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// missingFileReason is written when the file was rejected by the validator lambda before the record was created.
+const missingFileReason = dictionary.RejectedPrefix + "file is missing"
+
+// checkFile validates the dictionary file of the record before it is checked or published.
+// A missing or invalid file rejects the record and false is returned.
+func checkFile(ctx context.Context, item *applingoprocessing.SchemaItem) (bool, error) {
+	report, err := dictionary.ValidateObject(ctx, s3Bucket, utils.RecordToFileID(item.Id), serviceProcessingBucket)
+	switch {
+	case errors.Is(err, cloud.ErrBucketObjectNotFound):
+		return false, rejectRecord(ctx, item, missingFileReason)
+	case err != nil:
+		return false, fmt.Errorf("failed to validate file: %w", err)
+	case !report.Valid():
+		return false, rejectRecord(ctx, item, report.Reason())
+	}
+	return true, nil
+}
+
+// rejectRecord writes the reason to the record and resets its score,
+// the reason already written by the validator lambda is kept.
+func rejectRecord(ctx context.Context, item *applingoprocessing.SchemaItem, reason string) error {
+	key, err := applingoprocessing.CreateKeyFromItem(*item)
+	if err != nil {
+		return fmt.Errorf("failed to create key for item: %w", err)
+	}
+	update := expression.
+		Set(
+			expression.Name(applingoprocessing.ColumnScore),
+			expression.Value(0),
+		).
+		Set(
+			expression.Name(applingoprocessing.ColumnReason),
+			expression.Value(reason),
+		)
+	condition := expression.AttributeExists(expression.Name(applingoprocessing.ColumnId)).
+		And(expression.Not(expression.Name(applingoprocessing.ColumnReason).BeginsWith(dictionary.RejectedPrefix)))
+
+	err = dbDynamo.Update(ctx, applingoprocessing.TableSchema.TableName, key, update, condition)
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return nil
+	}
+	return err
+}
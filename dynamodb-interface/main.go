@@ -30,6 +30,22 @@ type CompositeKeyPart struct {
 	Value      string
 }
 
+// CompositeAttribute describes an attribute whose value is built from other attributes, e.g. "level#is_public".
+type CompositeAttribute struct {
+	Name  string
+	Field string
+	Parts []CompositeAttributePart
+}
+
+// CompositeAttributePart is a single part of a composite attribute, either a constant or an attribute of the item.
+type CompositeAttributePart struct {
+	IsConstant bool
+	Value      string
+	Field      string
+	Param      string
+	Type       string
+}
+
 type SecondaryIndex struct {
 	Name             string `json:"name"`
 	HashKey          string `json:"hash_key"`
@@ -135,7 +151,7 @@ type SecondaryIndex struct {
 // SchemaItem represents an item in "{{.TableName}}"
 type SchemaItem struct {
     {{range .AllAttributes}}
-    {{SafeName .Name | ToCamelCase}} {{TypeGo .Type}} ` + "`dynamodbav:\"{{.Name}}\"`" + `
+    {{SafeName .Name | ToCamelCase}} {{TypeGo .Type}} ` + "`dynamodbav:\"{{DynamoTag .}}\"`" + `
     {{end}}
 }
 
@@ -630,6 +646,51 @@ func IntToBool(i int) bool {
     return i != 0
 }
 
+// CompositeSeparator joins the parts of composite attributes.
+const CompositeSeparator = "#"
+
+// NewSchemaItem returns the item with composite attributes filled from their parts.
+func NewSchemaItem(item SchemaItem) SchemaItem {
+    item.FillCompositeKeys()
+    return item
+}
+
+// FillCompositeKeys sets composite attributes from their parts.
+func (item *SchemaItem) FillCompositeKeys() {
+{{- range .CompositeAttributes}}
+    item.{{.Field}} = Compose{{.Field}}({{ComposeArgs "item" .Parts}})
+{{- end}}
+}
+
+// ValidateCompositeKeys returns an error if a composite attribute disagrees with its parts.
+func (item SchemaItem) ValidateCompositeKeys() error {
+{{- range .CompositeAttributes}}
+    if expected := Compose{{.Field}}({{ComposeArgs "item" .Parts}}); item.{{.Field}} != expected {
+        return fmt.Errorf("composite attribute {{.Name}} is %q, expected %q", item.{{.Field}}, expected)
+    }
+{{- end}}
+    return nil
+}
+{{range .CompositeAttributes}}
+// Compose{{.Field}} builds the {{.Name}} composite value.
+func Compose{{.Field}}({{ComposeParams .Parts}}) string {
+    return strings.Join([]string{ {{- ComposeValues .Parts -}} }, CompositeSeparator)
+}
+
+// Parse{{.Field}} splits the {{.Name}} composite value into its parts.
+func Parse{{.Field}}(value string) ({{ParseResults .Parts}}) {
+    parts := strings.Split(value, CompositeSeparator)
+    if len(parts) != {{len .Parts}} {
+        err = fmt.Errorf("invalid {{.Name}} value %q: expected {{len .Parts}} parts, got %d", value, len(parts))
+        return
+    }
+{{- $name := .Name}}
+{{- range $i, $part := .Parts}}
+    {{ParsePart $name $i $part}}
+{{- end}}
+    return
+}
+{{end}}
 // ExtractFromDynamoDBStreamEvent DynamoDB Stream to SchemaItem
 func ExtractFromDynamoDBStreamEvent(dbEvent events.DynamoDBEventRecord) (*SchemaItem, error) {
     if dbEvent.Change.NewImage == nil {
@@ -903,6 +964,14 @@ func processSchemaFile(jsonPath, rootDir string) {
 		"TypeGo":           typeGo,
 		"TypeZero":         typeZero,
 		"TypeGoAttr":       typeGoAttr,
+		"ComposeArgs":      composeArgs,
+		"ComposeParams":    composeParams,
+		"ComposeValues":    composeValues,
+		"ParseResults":     parseResults,
+		"ParsePart":        parsePart,
+		"DynamoTag": func(attr Attribute) string {
+			return dynamoTag(attr, schema)
+		},
 	}
 	allAttributes := append(schema.Attributes, schema.CommonAttributes...)
 
@@ -924,6 +993,8 @@ func processSchemaFile(jsonPath, rootDir string) {
 		"CommonAttributes": schema.CommonAttributes,
 		"AllAttributes":    allAttributes,
 		"SecondaryIndexes": schema.SecondaryIndexes,
+
+		"CompositeAttributes": parseCompositeAttributes(allAttributes),
 	}
 
 	tmpl, err := template.New("schema").Funcs(funcMap).Parse(codeTemplate)
@@ -963,6 +1034,117 @@ func parseCompositeKey(key string, allAttributes []Attribute) []CompositeKeyPart
 	return result
 }
 
+// dynamoTag returns the dynamodbav tag of the attribute. String keys of secondary indexes are omitted
+// when empty, DynamoDB rejects empty index keys and such items are left out of the sparse index instead.
+func dynamoTag(attr Attribute, schema DynamoSchema) string {
+	if attr.Type != "S" || attr.Name == schema.HashKey || attr.Name == schema.RangeKey {
+		return attr.Name
+	}
+	for _, idx := range schema.SecondaryIndexes {
+		if attr.Name == idx.HashKey || attr.Name == idx.RangeKey {
+			return attr.Name + ",omitempty"
+		}
+	}
+	return attr.Name
+}
+
+func parseCompositeAttributes(allAttributes []Attribute) []CompositeAttribute {
+	var result []CompositeAttribute
+	for _, attr := range allAttributes {
+		if !strings.Contains(attr.Name, "#") {
+			continue
+		}
+		composite := CompositeAttribute{
+			Name:  attr.Name,
+			Field: toCamelCase(safeName(attr.Name)),
+		}
+		hasAttribute := false
+		for _, part := range parseCompositeKey(attr.Name, allAttributes) {
+			if part.IsConstant {
+				composite.Parts = append(composite.Parts, CompositeAttributePart{IsConstant: true, Value: part.Value})
+				continue
+			}
+			hasAttribute = true
+			composite.Parts = append(composite.Parts, CompositeAttributePart{
+				Value: part.Value,
+				Field: toCamelCase(safeName(part.Value)),
+				Param: toLowerCamelCase(safeName(part.Value)),
+				Type:  typeGoAttr(part.Value, allAttributes),
+			})
+		}
+		if hasAttribute {
+			result = append(result, composite)
+		}
+	}
+	return result
+}
+
+// composeArgs returns the arguments of the Compose function taken from the item fields.
+func composeArgs(receiver string, parts []CompositeAttributePart) string {
+	var args []string
+	for _, part := range parts {
+		if !part.IsConstant {
+			args = append(args, receiver+"."+part.Field)
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+// composeParams returns the typed parameters of the Compose function.
+func composeParams(parts []CompositeAttributePart) string {
+	var params []string
+	for _, part := range parts {
+		if !part.IsConstant {
+			params = append(params, part.Param+" "+part.Type)
+		}
+	}
+	return strings.Join(params, ", ")
+}
+
+// composeValues returns the string expressions joined into the composite value.
+func composeValues(parts []CompositeAttributePart) string {
+	values := make([]string, 0, len(parts))
+	for _, part := range parts {
+		switch {
+		case part.IsConstant:
+			values = append(values, fmt.Sprintf("%q", part.Value))
+		case part.Type == "int":
+			values = append(values, "strconv.Itoa("+part.Param+")")
+		case part.Type == "bool":
+			values = append(values, "strconv.FormatBool("+part.Param+")")
+		default:
+			values = append(values, part.Param)
+		}
+	}
+	return strings.Join(values, ", ")
+}
+
+// parseResults returns the named results of the Parse function.
+func parseResults(parts []CompositeAttributePart) string {
+	results := make([]string, 0, len(parts)+1)
+	for _, part := range parts {
+		if !part.IsConstant {
+			results = append(results, part.Param+" "+part.Type)
+		}
+	}
+	return strings.Join(append(results, "err error"), ", ")
+}
+
+// parsePart returns the statement which reads a single part of the composite value.
+func parsePart(name string, index int, part CompositeAttributePart) string {
+	src := fmt.Sprintf("parts[%d]", index)
+	switch {
+	case part.IsConstant:
+		return fmt.Sprintf("if %s != %q {\n        err = fmt.Errorf(\"invalid %s value %%q: expected %q at position %d\", value)\n        return\n    }", src, part.Value, name, part.Value, index)
+	case part.Type == "int":
+		return fmt.Sprintf("if %s, err = strconv.Atoi(%s); err != nil {\n        err = fmt.Errorf(\"invalid %s value %%q: %%w\", value, err)\n        return\n    }", part.Param, src, name)
+	case part.Type == "bool":
+		return fmt.Sprintf("if %s, err = strconv.ParseBool(%s); err != nil {\n        err = fmt.Errorf(\"invalid %s value %%q: %%w\", value, err)\n        return\n    }", part.Param, src, name)
+	default:
+		return fmt.Sprintf("%s = %s", part.Param, src)
+	}
+}
+
 func isAttribute(name string, attributes []Attribute) bool {
 	for _, attr := range attributes {
 		if attr.Name == name {
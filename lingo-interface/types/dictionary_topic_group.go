@@ -0,0 +1,98 @@
+package types
+
+import (
+	"github.com/pkg/errors"
+)
+
+// DictionaryTopicGroup represents a group of related dictionary topics.
+type DictionaryTopicGroup int
+
+const (
+	// TopicGroupTravel groups travel and transportation topics.
+	TopicGroupTravel DictionaryTopicGroup = iota
+
+	// TopicGroupHealthcare groups emergency and healthcare topics.
+	TopicGroupHealthcare
+
+	// TopicGroupDailyLife groups daily life and services topics.
+	TopicGroupDailyLife
+
+	// TopicGroupWork groups work and business topics.
+	TopicGroupWork
+
+	// TopicGroupCommunication groups communication and culture topics.
+	TopicGroupCommunication
+
+	// TopicGroupHobbies groups hobbies and leisure topics.
+	TopicGroupHobbies
+
+	// TopicGroupOther groups topics which are not in the catalogue, e.g. set by authors.
+	TopicGroupOther
+)
+
+// String returns the string representation of the topic group.
+func (g DictionaryTopicGroup) String() string {
+	switch g {
+	case TopicGroupTravel:
+		return "Travel & Transportation"
+	case TopicGroupHealthcare:
+		return "Emergencies & Healthcare"
+	case TopicGroupDailyLife:
+		return "Daily Life & Services"
+	case TopicGroupWork:
+		return "Work & Business"
+	case TopicGroupCommunication:
+		return "Communication & Culture"
+	case TopicGroupHobbies:
+		return "Hobbies & Leisure"
+	default:
+		return "Other"
+	}
+}
+
+// AllDictionaryTopicGroups returns a slice of all available dictionary topic groups.
+func AllDictionaryTopicGroups() []DictionaryTopicGroup {
+	return []DictionaryTopicGroup{
+		TopicGroupTravel, TopicGroupHealthcare, TopicGroupDailyLife,
+		TopicGroupWork, TopicGroupCommunication, TopicGroupHobbies, TopicGroupOther,
+	}
+}
+
+// ParseDictionaryTopicGroup converts a string to DictionaryTopicGroup.
+func ParseDictionaryTopicGroup(s string) (DictionaryTopicGroup, error) {
+	for _, group := range AllDictionaryTopicGroups() {
+		if group.String() == s {
+			return group, nil
+		}
+	}
+	return 0, errors.New("invalid dictionary topic group")
+}
+
+// Group returns the group the topic belongs to.
+func (t DictionaryTopic) Group() DictionaryTopicGroup {
+	switch {
+	case t >= TopicAirportTravel && t <= TopicCityNavigation:
+		return TopicGroupTravel
+	case t >= TopicEmergency && t <= TopicVetVisit:
+		return TopicGroupHealthcare
+	case t >= TopicCafeCulture && t <= TopicLostAndFound:
+		return TopicGroupDailyLife
+	case t >= TopicJobInterviews && t <= TopicDigitalSecurity:
+		return TopicGroupWork
+	case t >= TopicCulturalEtiquette && t <= TopicLanguageExchange:
+		return TopicGroupCommunication
+	case t >= TopicMuseums && t <= TopicShopping:
+		return TopicGroupHobbies
+	default:
+		return TopicGroupOther
+	}
+}
+
+// TopicGroupOf returns the group of a stored topic name, TopicGroupOther for unknown topics.
+func TopicGroupOf(topic string) DictionaryTopicGroup {
+	t, err := ParseDictionaryTopic(topic)
+	if err != nil {
+		return TopicGroupOther
+	}
+	return t.Group()
+}
@@ -36,3 +36,58 @@ var DataResponseLevels = func(data applingoapi.LevelsData) applingoapi.ResponseG
 var DataResponseProfile = func(data applingoapi.ProfileData) applingoapi.ResponsePatchProfileV1 {
 	return applingoapi.ResponsePatchProfileV1{Data: data}
 }
+
+// DataResponseProfileEvents returns a response containing ProfileEventsData.
+var DataResponseProfileEvents = func(data applingoapi.ProfileEventsData) applingoapi.ResponseProfileEventsV1 {
+	return applingoapi.ResponseProfileEventsV1{Data: data}
+}
+
+// DataResponseProgress returns a response containing ProgressData.
+var DataResponseProgress = func(data applingoapi.ProgressData) applingoapi.ResponseProfileProgressV1 {
+	return applingoapi.ResponseProfileProgressV1{Data: data}
+}
+
+// DataResponseAchievements returns a response containing AchievementsData.
+var DataResponseAchievements = func(data applingoapi.AchievementsData) applingoapi.ResponseProfileAchievementsV1 {
+	return applingoapi.ResponseProfileAchievementsV1{Data: data}
+}
+
+// DataResponseLeaderboard returns a response containing LeaderboardData.
+var DataResponseLeaderboard = func(data applingoapi.LeaderboardData) applingoapi.ResponseProfileLeaderboardV1 {
+	return applingoapi.ResponseProfileLeaderboardV1{Data: data}
+}
+
+// DataResponseDevice returns a response containing DeviceData.
+var DataResponseDevice = func(data applingoapi.DeviceData) applingoapi.ResponsePostDeviceV1 {
+	return applingoapi.ResponsePostDeviceV1{Data: data}
+}
+
+// DataResponseToken returns a response containing TokenData.
+var DataResponseToken = func(data applingoapi.TokenData) applingoapi.ResponsePostAuthTokenV1 {
+	return applingoapi.ResponsePostAuthTokenV1{Data: data}
+}
+
+// DataResponseDictionary returns a response containing DictionaryData.
+var DataResponseDictionary = func(data applingoapi.DictionaryData) applingoapi.ResponseGetDictionaryV1 {
+	return applingoapi.ResponseGetDictionaryV1{Data: data}
+}
+
+// DataResponseDictionaryItem returns a response containing a single DictionaryItemV1.
+var DataResponseDictionaryItem = func(data applingoapi.DictionaryItemV1) applingoapi.ResponsePatchDictionaryV1 {
+	return applingoapi.ResponsePatchDictionaryV1{Data: data}
+}
+
+// DataResponseDictionaryVersions returns a response containing DictionaryVersionsData.
+var DataResponseDictionaryVersions = func(data applingoapi.DictionaryVersionsData) applingoapi.ResponseGetDictionaryVersionsV1 {
+	return applingoapi.ResponseGetDictionaryVersionsV1{Data: data}
+}
+
+// DataResponseDictionaryVersion returns a response containing DictionaryVersionData.
+var DataResponseDictionaryVersion = func(data applingoapi.DictionaryVersionData) applingoapi.ResponseGetDictionaryVersionV1 {
+	return applingoapi.ResponseGetDictionaryVersionV1{Data: data}
+}
+
+// DataResponseDictionaryRollback returns a response containing the published DictionaryVersionItemV1.
+var DataResponseDictionaryRollback = func(data applingoapi.DictionaryVersionItemV1) applingoapi.ResponsePostDictionaryRollbackV1 {
+	return applingoapi.ResponsePostDictionaryRollbackV1{Data: data}
+}
@@ -0,0 +1,66 @@
+package openapi
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// PathParams wraps path parameters extracted from a route template (e.g. "/v1/dictionaries/{id}")
+// and provides typed access methods.
+type PathParams struct {
+	raw map[string]string
+}
+
+// NewPathParams creates a new PathParams instance from a raw map of string key-value pairs.
+func NewPathParams(params map[string]string) PathParams {
+	if params == nil {
+		params = make(map[string]string)
+	}
+	return PathParams{raw: params}
+}
+
+// GetString returns the value for the given key as a string or an error if not found.
+func (p PathParams) GetString(key string) (string, error) {
+	v, ok := p.raw[key]
+	if !ok || v == "" {
+		return "", fmt.Errorf("path param '%s' not found", key)
+	}
+	return v, nil
+}
+
+// GetStringDefault returns the value for the key or the provided default if not found.
+func (p PathParams) GetStringDefault(key, defaultValue string) string {
+	if v, err := p.GetString(key); err == nil {
+		return v
+	}
+	return defaultValue
+}
+
+// GetInt returns the value for the key as an int or an error if not found or invalid.
+func (p PathParams) GetInt(key string) (int, error) {
+	v, err := p.GetString(key)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(v)
+}
+
+// GetIntDefault returns the int value for the key or the default if not found or invalid.
+func (p PathParams) GetIntDefault(key string, defaultValue int) int {
+	v, err := p.GetInt(key)
+	if err != nil {
+		return defaultValue
+	}
+	return v
+}
+
+// Has checks if the key exists in the path parameters.
+func (p PathParams) Has(key string) bool {
+	_, ok := p.raw[key]
+	return ok
+}
+
+// Raw returns the underlying raw map of path parameters.
+func (p PathParams) Raw() map[string]string {
+	return p.raw
+}
@@ -0,0 +1,182 @@
+// Package achievement evaluates the achievements of profiles. Achievements are declared in a catalog
+// kept as data, each of them unlocks once a metric of the profile reaches its target. Metrics are
+// counted from the learning progress of the profile, streaks of active days are kept alongside.
+package achievement
+
+import (
+	"strings"
+	"time"
+
+	lingo "github.com/Mad-Pixels/applingo-api/lingo-interface/types"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+
+	"github.com/pkg/errors"
+)
+
+// Metrics an achievement can track.
+const (
+	// MetricLevel is the level of the profile.
+	MetricLevel = "level"
+	// MetricXPTotal is the XP earned by the profile.
+	MetricXPTotal = "xp_total"
+	// MetricStreak is the current streak of active days.
+	MetricStreak = "streak"
+	// MetricBestStreak is the longest streak of active days.
+	MetricBestStreak = "streak_best"
+	// MetricSessions counts the completed sessions.
+	MetricSessions = "sessions_completed"
+	// MetricWords counts the reviewed words.
+	MetricWords = "words_reviewed"
+	// MetricDictionariesFinished counts the finished dictionaries, it can be narrowed by level or subcategory.
+	MetricDictionariesFinished = "dictionaries_finished"
+	// MetricDictionariesStudied counts the dictionaries with reviewed words, it can be narrowed by level or subcategory.
+	MetricDictionariesStudied = "dictionaries_studied"
+	// MetricLanguagePairs counts the subcategories, e.g. "en-ru", of the studied dictionaries.
+	MetricLanguagePairs = "language_pairs"
+)
+
+var metrics = map[string]bool{
+	MetricLevel:                true,
+	MetricXPTotal:              true,
+	MetricStreak:               true,
+	MetricBestStreak:           true,
+	MetricSessions:             true,
+	MetricWords:                true,
+	MetricDictionariesFinished: true,
+	MetricDictionariesStudied:  true,
+	MetricLanguagePairs:        true,
+}
+
+// Rule declares an achievement of the catalog.
+type Rule struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Metric      string `json:"metric"`
+	// Level and Subcategory narrow the dictionary metrics, at most one of them is set.
+	Level       string `json:"level,omitempty"`
+	Subcategory string `json:"subcategory,omitempty"`
+	Target      int    `json:"target"`
+	// Freezes are granted to the streak of the profile when the achievement unlocks.
+	Freezes int `json:"freezes,omitempty"`
+}
+
+// Catalog is the list of achievements, e.g.
+// {"achievements":[{"id":"b1_reader","name":"B1 reader","metric":"dictionaries_finished","level":"B1","target":10}]}.
+type Catalog struct {
+	Achievements []Rule `json:"achievements"`
+}
+
+// ParseCatalog decodes the catalog and validates its rules.
+func ParseCatalog(data []byte) (Catalog, error) {
+	var catalog Catalog
+	if err := serializer.UnmarshalJSON(data, &catalog); err != nil {
+		return Catalog{}, errors.Wrap(err, "invalid achievements catalog")
+	}
+
+	ids := make(map[string]bool, len(catalog.Achievements))
+	for _, rule := range catalog.Achievements {
+		if err := rule.validate(); err != nil {
+			return Catalog{}, errors.Wrapf(err, "invalid achievement '%s'", rule.ID)
+		}
+		if ids[rule.ID] {
+			return Catalog{}, errors.Errorf("invalid achievements catalog: achievement '%s' is declared twice", rule.ID)
+		}
+		ids[rule.ID] = true
+	}
+	return catalog, nil
+}
+
+func (r Rule) validate() error {
+	switch {
+	case r.ID == "" || r.Name == "":
+		return errors.New("id and name are required")
+	case !metrics[r.Metric]:
+		return errors.Errorf("unknown metric '%s'", r.Metric)
+	case r.Target < 1:
+		return errors.New("target must be positive")
+	case r.Freezes < 0:
+		return errors.New("freezes must not be negative")
+	}
+	if r.Level == "" && r.Subcategory == "" {
+		return nil
+	}
+	if r.Metric != MetricDictionariesFinished && r.Metric != MetricDictionariesStudied {
+		return errors.Errorf("metric '%s' cannot be narrowed", r.Metric)
+	}
+	if r.Level != "" && r.Subcategory != "" {
+		return errors.New("level and subcategory cannot be combined")
+	}
+	if r.Level != "" {
+		if _, err := lingo.ParseLanguageLevel(r.Level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the rule by id.
+func (c Catalog) Get(id string) (Rule, bool) {
+	for _, rule := range c.Achievements {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Unlock marks the rules reached by the stats as unlocked at now and returns them.
+// Unlocked achievements stay unlocked even if the stats go down or the rule changes.
+func (c Catalog) Unlock(stats Stats, unlocked Unlocked, now time.Time) []Rule {
+	var reached []Rule
+	for _, rule := range c.Achievements {
+		if _, ok := unlocked[rule.ID]; ok || stats.Value(rule) < rule.Target {
+			continue
+		}
+		unlocked[rule.ID] = now.Unix()
+		reached = append(reached, rule)
+	}
+	return reached
+}
+
+// Unlocked holds the unix time each achievement of the profile was unlocked at by id.
+type Unlocked map[string]int64
+
+// Stats holds the counters of the profile by key, see StatKey.
+type Stats map[string]int
+
+// StatKey returns the key the metric is counted under, narrowed by level or subcategory if one is given.
+func StatKey(metric, level, subcategory string) string {
+	switch {
+	case level != "":
+		return metric + "#level#" + level
+	case subcategory != "":
+		return metric + "#subcategory#" + subcategory
+	}
+	return metric
+}
+
+// AddDictionary counts the dictionary for the metric overall, by its level and by its subcategory.
+func (s Stats) AddDictionary(metric, level, subcategory string) {
+	s[metric]++
+	if level != "" {
+		s[StatKey(metric, level, "")]++
+	}
+	if subcategory != "" {
+		s[StatKey(metric, "", subcategory)]++
+	}
+}
+
+// Value returns the value of the metric tracked by the rule.
+func (s Stats) Value(rule Rule) int {
+	if rule.Metric == MetricLanguagePairs {
+		pairs := 0
+		for key, value := range s {
+			if strings.HasPrefix(key, MetricDictionariesStudied+"#subcategory#") && value > 0 {
+				pairs++
+			}
+		}
+		return pairs
+	}
+	return s[StatKey(rule.Metric, rule.Level, rule.Subcategory)]
+}
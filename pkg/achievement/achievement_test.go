@@ -0,0 +1,133 @@
+package achievement
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCatalog = `{"achievements":[
+	{"id":"first_session","name":"First session","metric":"sessions_completed","target":1,"freezes":1},
+	{"id":"b1_reader","name":"B1 reader","metric":"dictionaries_finished","level":"B1","target":2},
+	{"id":"polyglot","name":"Polyglot","metric":"language_pairs","target":2}
+]}`
+
+func TestParseCatalog(t *testing.T) {
+	catalog, err := ParseCatalog([]byte(testCatalog))
+	require.NoError(t, err)
+	require.Len(t, catalog.Achievements, 3)
+	rule, ok := catalog.Get("b1_reader")
+	require.True(t, ok)
+	assert.Equal(t, "B1", rule.Level)
+
+	tests := []struct {
+		name    string
+		catalog string
+	}{
+		{"invalid json", `{"achievements":`},
+		{"no name", `{"achievements":[{"id":"a","metric":"level","target":1}]}`},
+		{"unknown metric", `{"achievements":[{"id":"a","name":"A","metric":"likes","target":1}]}`},
+		{"no target", `{"achievements":[{"id":"a","name":"A","metric":"level"}]}`},
+		{"narrowed level", `{"achievements":[{"id":"a","name":"A","metric":"level","level":"B1","target":1}]}`},
+		{"unknown level", `{"achievements":[{"id":"a","name":"A","metric":"dictionaries_finished","level":"D1","target":1}]}`},
+		{"both filters", `{"achievements":[{"id":"a","name":"A","metric":"dictionaries_finished","level":"B1","subcategory":"en-ru","target":1}]}`},
+		{"duplicate", `{"achievements":[{"id":"a","name":"A","metric":"level","target":1},{"id":"a","name":"B","metric":"level","target":2}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCatalog([]byte(tt.catalog))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	catalog, err := ParseCatalog([]byte(testCatalog))
+	require.NoError(t, err)
+	now := time.Unix(1_700_000_000, 0)
+
+	stats := Stats{}
+	stats.AddDictionary(MetricDictionariesFinished, "B1", "en-ru")
+	stats.AddDictionary(MetricDictionariesFinished, "A2", "en-ru")
+	stats.AddDictionary(MetricDictionariesStudied, "B1", "en-ru")
+	stats.AddDictionary(MetricDictionariesStudied, "B1", "en-ru")
+	assert.Equal(t, 2, stats[MetricDictionariesFinished])
+	assert.Equal(t, 1, stats[StatKey(MetricDictionariesFinished, "B1", "")])
+	assert.Equal(t, 2, stats[StatKey(MetricDictionariesFinished, "", "en-ru")])
+
+	unlocked := Unlocked{}
+	assert.Empty(t, catalog.Unlock(stats, unlocked, now))
+
+	stats[MetricSessions] = 1
+	stats.AddDictionary(MetricDictionariesFinished, "B1", "de-ru")
+	stats.AddDictionary(MetricDictionariesStudied, "B1", "de-ru")
+	reached := catalog.Unlock(stats, unlocked, now)
+	require.Len(t, reached, 3)
+	assert.Equal(t, Unlocked{"first_session": now.Unix(), "b1_reader": now.Unix(), "polyglot": now.Unix()}, unlocked)
+
+	// unlocked achievements are kept as they are.
+	assert.Empty(t, catalog.Unlock(Stats{}, unlocked, now.Add(time.Hour)))
+	assert.Equal(t, now.Unix(), unlocked["polyglot"])
+}
+
+func TestStreak(t *testing.T) {
+	var s Streak
+	assert.Zero(t, s.At(100))
+
+	s = s.Visit(100)
+	assert.Equal(t, Streak{Current: 1, Best: 1, Day: 100}, s)
+	assert.Equal(t, s, s.Visit(100))
+	assert.Equal(t, s, s.Visit(99))
+
+	for day := 101; day <= 106; day++ {
+		s = s.Visit(day)
+	}
+	assert.Equal(t, Streak{Current: 7, Best: 7, Day: 106, Freezes: 1}, s)
+	assert.Equal(t, 7, s.At(108))
+	assert.Zero(t, s.At(109))
+
+	// a missed day is covered by the freeze.
+	s = s.Visit(108)
+	assert.Equal(t, Streak{Current: 8, Best: 8, Day: 108}, s)
+
+	// without freezes the streak starts over.
+	s = s.Visit(110)
+	assert.Equal(t, Streak{Current: 1, Best: 8, Day: 110}, s)
+
+	s.Freeze(5)
+	assert.Equal(t, MaxFreezes, s.Freezes)
+}
+
+func TestSource(t *testing.T) {
+	ctx := context.Background()
+
+	source := NewSource(StaticCatalog(testCatalog), time.Hour)
+	catalog, err := source.Catalog(ctx)
+	require.NoError(t, err)
+	assert.Len(t, catalog.Achievements, 3)
+
+	loads := 0
+	source = NewSource(func(context.Context) ([]byte, error) {
+		loads++
+		if loads > 1 {
+			return nil, errors.New("unavailable")
+		}
+		return []byte(testCatalog), nil
+	}, time.Nanosecond)
+	_, err = source.Catalog(ctx)
+	require.NoError(t, err)
+
+	// a failed reload keeps the loaded catalog.
+	time.Sleep(time.Millisecond)
+	catalog, err = source.Catalog(ctx)
+	require.NoError(t, err)
+	assert.Len(t, catalog.Achievements, 3)
+	assert.Equal(t, 2, loads)
+
+	_, err = NewSource(StaticCatalog(`{`), time.Hour).Catalog(ctx)
+	assert.Error(t, err)
+}
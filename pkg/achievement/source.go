@@ -0,0 +1,97 @@
+package achievement
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// CatalogKey is the key of the catalog in the forge bucket.
+	CatalogKey = "achievements/catalog.json"
+	// DefaultRefresh defines how often the catalog is reloaded from its source.
+	DefaultRefresh = 15 * time.Minute
+
+	loadTimeout = 3 * time.Second
+)
+
+// Loader returns the raw catalog from its source.
+type Loader func(ctx context.Context) ([]byte, error)
+
+// StaticCatalog returns a loader serving a fixed catalog.
+func StaticCatalog(document string) Loader {
+	return func(context.Context) ([]byte, error) {
+		return []byte(document), nil
+	}
+}
+
+// BucketCatalog returns a loader reading the catalog from the bucket object.
+func BucketCatalog(s3Bucket cloud.BucketAPI, key, bucket string) Loader {
+	return func(ctx context.Context) ([]byte, error) {
+		body, err := s3Bucket.Get(ctx, key, bucket)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get achievements catalog")
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+}
+
+// Source keeps the catalog loaded and reloads it periodically, so the catalog is changed without a deploy.
+type Source struct {
+	loader  Loader
+	refresh time.Duration
+
+	mu        sync.Mutex
+	catalog   Catalog
+	loaded    bool
+	checkedAt time.Time // last load attempt, failed loads keep the previous catalog
+}
+
+// NewSource returns a source which loads the catalog on first use, refresh defines the reload interval.
+func NewSource(loader Loader, refresh time.Duration) *Source {
+	if refresh <= 0 {
+		refresh = DefaultRefresh
+	}
+	return &Source{loader: loader, refresh: refresh}
+}
+
+// Catalog returns the catalog, an error is returned only if it was never loaded.
+func (s *Source) Catalog(ctx context.Context) (Catalog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.checkedAt.IsZero() && time.Since(s.checkedAt) < s.refresh {
+		if !s.loaded {
+			return Catalog{}, errors.New("achievements catalog is not loaded")
+		}
+		return s.catalog, nil
+	}
+	s.checkedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+
+	catalog, err := s.load(ctx)
+	if err != nil {
+		if s.loaded {
+			return s.catalog, nil
+		}
+		return Catalog{}, err
+	}
+	s.catalog, s.loaded = catalog, true
+	return catalog, nil
+}
+
+func (s *Source) load(ctx context.Context) (Catalog, error) {
+	data, err := s.loader(ctx)
+	if err != nil {
+		return Catalog{}, err
+	}
+	return ParseCatalog(data)
+}
@@ -0,0 +1,52 @@
+package achievement
+
+// Streak rules.
+const (
+	// FreezeEvery grants a streak freeze for every this many days in a row.
+	FreezeEvery = 7
+	// MaxFreezes caps the freezes a profile keeps.
+	MaxFreezes = 2
+)
+
+// Streak is the run of days in a row the profile was active. A missed day is covered
+// by a freeze if the profile has one, otherwise the streak starts over.
+type Streak struct {
+	Current int
+	Best    int
+	// Day is the last active day, see ledger.Day, 0 if the profile was never active.
+	Day     int
+	Freezes int
+}
+
+// Visit counts the day as active. Days before the last active one cannot change the streak anymore.
+func (s Streak) Visit(day int) Streak {
+	if day <= s.Day {
+		return s
+	}
+	missed := day - s.Day - 1
+	if s.Day > 0 && missed <= s.Freezes {
+		s.Freezes -= missed
+		s.Current++
+	} else {
+		s.Current = 1
+	}
+	s.Day = day
+	s.Best = max(s.Best, s.Current)
+	if s.Current%FreezeEvery == 0 {
+		s.Freeze(1)
+	}
+	return s
+}
+
+// Freeze grants freezes to the streak up to MaxFreezes.
+func (s *Streak) Freeze(freezes int) {
+	s.Freezes = min(s.Freezes+freezes, MaxFreezes)
+}
+
+// At returns the current streak as seen on the day, it is 0 once the missed days are more than the freezes.
+func (s Streak) At(day int) int {
+	if s.Day == 0 || day-s.Day-1 > s.Freezes {
+		return 0
+	}
+	return s.Current
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/Mad-Pixels/applingo-api/openapi-interface"
 	"github.com/Mad-Pixels/applingo-api/pkg/auth"
@@ -22,23 +23,32 @@ import (
 type HandleFunc func(context.Context, zerolog.Logger, json.RawMessage, openapi.QueryParams) (any, *HandleError)
 
 // API is the main router for handling Lambda-based HTTP API Gateway requests.
-// It maps HTTP method + path combinations to corresponding handlers.
+// It maps HTTP method + path template combinations to corresponding handlers.
 type API struct {
-	cfg      Config
-	log      zerolog.Logger
-	handlers map[string]HandleFunc
+	cfg    Config
+	log    zerolog.Logger
+	router *router
 }
 
 // NewLambda creates and returns a new API instance that routes
 // Lambda API Gateway requests using the provided configuration and handlers map.
+// Keys have the "METHOD:/path" format, path segments may be parameters, e.g. "GET:/v1/dictionaries/{id}".
+// Global middlewares from the configuration wrap every handler.
 func NewLambda(cfg Config, handlers map[string]HandleFunc) *API {
 	if handlers == nil {
 		panic("handlers map cannot be nil")
 	}
+	r, err := newRouter(handlers)
+	if err != nil {
+		panic(err.Error())
+	}
+	for i := range r.routes {
+		r.routes[i].handler = Chain(r.routes[i].handler, cfg.Middlewares...)
+	}
 	return &API{
-		cfg:      cfg,
-		handlers: handlers,
-		log:      logger.InitLogger(),
+		cfg:    cfg,
+		router: r,
+		log:    logger.InitLogger(),
 	}
 }
 
@@ -55,7 +65,7 @@ func (a *API) Handle(ctx context.Context, req events.APIGatewayProxyRequest) (ev
 		}
 		return gatewayResponse(
 			http.StatusUnauthorized,
-			openapi.DataResponseMessage(http.StatusText(http.StatusUnauthorized)),
+			(&HandleError{Status: http.StatusUnauthorized}).Response(),
 			nil,
 		)
 	}
@@ -63,20 +73,30 @@ func (a *API) Handle(ctx context.Context, req events.APIGatewayProxyRequest) (ev
 		a.logRequest(mCtx, req)
 	}
 
-	handler, ok := a.handlers[opKey]
+	match, ok := a.router.match(req.RequestContext.HTTPMethod, req.RequestContext.ResourcePath, req.PathParameters, req.Path)
 	if !ok {
 		if a.cfg.EnableRequestLogging {
 			a.logError(req, opKey, errors.New("unknown operation"))
 		}
 		return gatewayResponse(
 			http.StatusNotFound,
-			openapi.DataResponseMessage(http.StatusText(http.StatusNotFound)),
+			(&HandleError{Status: http.StatusNotFound}).Response(),
 			nil,
 		)
 	}
+	if match.route == nil {
+		if a.cfg.EnableRequestLogging {
+			a.logError(req, opKey, errors.New("method not allowed"))
+		}
+		return gatewayResponse(
+			http.StatusMethodNotAllowed,
+			(&HandleError{Status: http.StatusMethodNotAllowed}).Response(),
+			map[string]string{"Allow": strings.Join(match.allowed, ", ")},
+		)
+	}
 
-	result, handleError := handler(
-		mCtx,
+	result, handleError := match.route.handler(
+		ctxWithHeaders(ctxWithPathParams(mCtx, match.params), req.Headers),
 		a.log,
 		json.RawMessage(req.Body),
 		openapi.NewQueryParams(req.QueryStringParameters),
@@ -87,7 +107,7 @@ func (a *API) Handle(ctx context.Context, req events.APIGatewayProxyRequest) (ev
 		}
 		return gatewayResponse(
 			handleError.Status,
-			openapi.DataResponseMessage(http.StatusText(handleError.Status)),
+			handleError.Response(),
 			nil,
 		)
 	}
@@ -101,6 +121,15 @@ func (a *API) Handle(ctx context.Context, req events.APIGatewayProxyRequest) (ev
 	default:
 		status = http.StatusOK
 	}
+	if resp, ok := result.(*Response); ok {
+		if resp.Status != 0 {
+			status = resp.Status
+		}
+		if resp.Body == nil {
+			return emptyResponse(status, resp.Headers), nil
+		}
+		return gatewayResponse(status, resp.Body, resp.Headers)
+	}
 	return gatewayResponse(status, result, nil)
 }
 
@@ -116,8 +145,11 @@ func (a *API) logRequest(ctx context.Context, req events.APIGatewayProxyRequest)
 		Str("userAgent", req.RequestContext.Identity.UserAgent).
 		Str("auth_type", meta.kind.String()).
 		Str("role", auth.RoleNames[meta.level])
-	if meta.IsUser() {
+	switch {
+	case meta.IsUser():
 		event.Str("user_id", meta.identifier)
+	case meta.IsDevice() && meta.identifier != "":
+		event.Str("device_id", meta.identifier)
 	}
 	event.Msg("Received API Gateway event")
 }
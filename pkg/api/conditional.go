@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+
+	"github.com/pkg/errors"
+)
+
+const headersKey contextKey = "headers"
+
+// Conditional request headers.
+const (
+	HeaderETag         = "ETag"
+	HeaderIfNoneMatch  = "If-None-Match"
+	HeaderCacheControl = "Cache-Control"
+)
+
+// GetHeader returns the request header value from the given context.
+// Header names are case-insensitive, an empty string is returned if the header is missing.
+func GetHeader(ctx context.Context, name string) string {
+	headers, ok := ctx.Value(headersKey).(map[string]string)
+	if !ok {
+		return ""
+	}
+	return headers[strings.ToLower(name)]
+}
+
+// ctxWithHeaders returns a new context with the request headers injected under lowercase names.
+func ctxWithHeaders(ctx context.Context, headers map[string]string) context.Context {
+	lower := make(map[string]string, len(headers))
+	for k, v := range headers {
+		lower[strings.ToLower(k)] = v
+	}
+	return context.WithValue(ctx, headersKey, lower)
+}
+
+// ETag returns a strong entity tag computed from the JSON representation of v.
+func ETag(v any) (string, error) {
+	data, err := serializer.MarshalJSON(v)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal etag source")
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// NotModified reports whether the If-None-Match request header matches the entity tag,
+// so the client copy is still valid. Weak tags are compared by their opaque value.
+func NotModified(ctx context.Context, etag string) bool {
+	header := GetHeader(ctx, HeaderIfNoneMatch)
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
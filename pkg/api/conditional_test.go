@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHeader(t *testing.T) {
+	ctx := ctxWithHeaders(context.Background(), map[string]string{"If-None-Match": `"abc"`})
+
+	assert.Equal(t, `"abc"`, GetHeader(ctx, "if-none-match"))
+	assert.Equal(t, `"abc"`, GetHeader(ctx, HeaderIfNoneMatch))
+	assert.Empty(t, GetHeader(ctx, "x-missing"))
+	assert.Empty(t, GetHeader(context.Background(), HeaderIfNoneMatch))
+}
+
+func TestNotModified(t *testing.T) {
+	etag, err := ETag(map[string]int{"rating": 1})
+	require.NoError(t, err)
+
+	other, err := ETag(map[string]int{"rating": 2})
+	require.NoError(t, err)
+	require.NotEqual(t, etag, other)
+
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{"no header", "", false},
+		{"match", etag, true},
+		{"weak match", "W/" + etag, true},
+		{"list", other + ", " + etag, true},
+		{"any", "*", true},
+		{"mismatch", other, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := ctxWithHeaders(context.Background(), map[string]string{HeaderIfNoneMatch: tt.header})
+			assert.Equal(t, tt.want, NotModified(ctx, etag))
+		})
+	}
+}
@@ -4,4 +4,8 @@ package api
 type Config struct {
 	// EnableRequestLogging determines whether incoming requests should be logged.
 	EnableRequestLogging bool
+
+	// Middlewares are applied to every registered handler, the first one is the outermost.
+	// Per-route middlewares are attached with Chain and run inside the global ones.
+	Middlewares []Middleware
 }
@@ -1,7 +1,101 @@
 package api
 
+import (
+	"net/http"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface/gen/applingoapi"
+	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+)
+
+// Machine-readable error codes returned in the error response envelope.
+const (
+	CodeBadRequest       = "bad_request"
+	CodeInvalidParam     = "invalid_param"
+	CodeValidationFailed = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeConflict         = "conflict"
+	CodeInternal         = "internal_error"
+)
+
+var statusCodes = map[int]string{
+	http.StatusBadRequest:       CodeBadRequest,
+	http.StatusUnauthorized:     CodeUnauthorized,
+	http.StatusForbidden:        CodeForbidden,
+	http.StatusNotFound:         CodeNotFound,
+	http.StatusMethodNotAllowed: CodeMethodNotAllowed,
+	http.StatusConflict:         CodeConflict,
+}
+
 // HandleError wraps an error with an associated HTTP status code for standardized API responses.
+// Err is only logged, the client receives Code, Message and Fields.
 type HandleError struct {
-	Err    error // Underlying error
-	Status int   // Corresponding HTTP status code
+	Err     error                        // Underlying error, never exposed to the client
+	Status  int                          // Corresponding HTTP status code
+	Code    string                       // Machine-readable code, derived from Status if empty
+	Message string                       // Public message, http.StatusText(Status) if empty
+	Fields  []applingoapi.ErrorFieldItem // Invalid fields for validation errors
+}
+
+// NewValidationError returns a bad request error with field details extracted from validator errors.
+func NewValidationError(err error) *HandleError {
+	var fields []applingoapi.ErrorFieldItem
+	for _, f := range validator.FieldErrors(err) {
+		item := applingoapi.ErrorFieldItem{Field: f.Field, Rule: f.Rule}
+		if f.Param != "" {
+			param := f.Param
+			item.Param = &param
+		}
+		fields = append(fields, item)
+	}
+	return &HandleError{
+		Err:     err,
+		Status:  http.StatusBadRequest,
+		Code:    CodeValidationFailed,
+		Message: "request validation failed",
+		Fields:  fields,
+	}
+}
+
+// NewParamError returns a bad request error pointing to the invalid request parameter.
+func NewParamError(param string, err error) *HandleError {
+	return &HandleError{
+		Err:     err,
+		Status:  http.StatusBadRequest,
+		Code:    CodeInvalidParam,
+		Message: "invalid value for '" + param + "' param",
+		Fields:  []applingoapi.ErrorFieldItem{{Field: param, Rule: "invalid"}},
+	}
+}
+
+// Response builds the public error envelope.
+func (e *HandleError) Response() applingoapi.ResponseError {
+	code := e.Code
+	if code == "" {
+		code = statusCode(e.Status)
+	}
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.Status)
+	}
+
+	data := applingoapi.ErrorData{Code: code, Message: message}
+	if len(e.Fields) > 0 {
+		fields := e.Fields
+		data.Fields = &fields
+	}
+	return applingoapi.ResponseError{Error: data}
+}
+
+// statusCode maps an HTTP status to the default error code.
+func statusCode(status int) string {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
 }
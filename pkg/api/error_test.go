@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleErrorResponseDefaults(t *testing.T) {
+	herr := &HandleError{Status: http.StatusNotFound, Err: errors.New("table applingo-dictionary: item not found")}
+
+	resp := herr.Response()
+	assert.Equal(t, CodeNotFound, resp.Error.Code)
+	assert.Equal(t, http.StatusText(http.StatusNotFound), resp.Error.Message)
+	assert.Nil(t, resp.Error.Fields)
+
+	body, err := serializer.MarshalJSON(resp)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "applingo-dictionary")
+}
+
+func TestHandleErrorResponseInternal(t *testing.T) {
+	resp := (&HandleError{Status: http.StatusBadGateway, Err: errors.New("boom")}).Response()
+	assert.Equal(t, CodeInternal, resp.Error.Code)
+}
+
+func TestNewValidationError(t *testing.T) {
+	type request struct {
+		SortBy string `json:"sort_by" validate:"required,oneof=date rating"`
+		Name   string `json:"name,omitempty" validate:"required"`
+	}
+	err := validator.New().ValidateStruct(&request{SortBy: "size"})
+	require.Error(t, err)
+
+	resp := NewValidationError(err).Response()
+	assert.Equal(t, CodeValidationFailed, resp.Error.Code)
+	require.NotNil(t, resp.Error.Fields)
+
+	fields := *resp.Error.Fields
+	require.Len(t, fields, 2)
+	assert.Equal(t, "sort_by", fields[0].Field)
+	assert.Equal(t, "oneof", fields[0].Rule)
+	require.NotNil(t, fields[0].Param)
+	assert.Equal(t, "date rating", *fields[0].Param)
+	assert.Equal(t, "name", fields[1].Field)
+	assert.Equal(t, "required", fields[1].Rule)
+	assert.Nil(t, fields[1].Param)
+}
+
+func TestNewParamError(t *testing.T) {
+	resp := NewParamError("sort_by", errors.New("unexpected value 'size'")).Response()
+	assert.Equal(t, CodeInvalidParam, resp.Error.Code)
+	assert.Equal(t, "invalid value for 'sort_by' param", resp.Error.Message)
+	require.NotNil(t, resp.Error.Fields)
+	assert.Equal(t, "sort_by", (*resp.Error.Fields)[0].Field)
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
 	"strconv"
 
 	"github.com/Mad-Pixels/applingo-api/pkg/auth"
@@ -35,7 +36,9 @@ func MustGetMetaData(ctx context.Context) MetaData {
 type MetaData struct {
 	level      auth.Role // Role level associated with the request
 	kind       auth.Kind // Type of authentication method used (e.g., JWT, HMAC)
-	identifier string    // Unique identifier of the user or device
+	identifier string    // Unique identifier of the user or of the profile of the device
+	device     string    // Id of the registered device
+	scopes     []string  // Fine-grained scopes granted to the user token
 }
 
 // HasPermissions checks if the role level is equal to or higher than the required level.
@@ -48,6 +51,26 @@ func (m MetaData) GetRole() auth.Role {
 	return m.level
 }
 
+// HasScope checks whether the request was granted the given scope, e.g. auth.ScopeDictionaryWrite.
+func (m MetaData) HasScope(scope string) bool {
+	for _, s := range m.scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// GetIdentifier returns the user id or the profile id of the registered device, empty for unregistered devices.
+func (m MetaData) GetIdentifier() string {
+	return m.identifier
+}
+
+// GetDevice returns the id of the registered device, empty for users and unregistered devices.
+func (m MetaData) GetDevice() string {
+	return m.device
+}
+
 // IsDevice checks whether the metadata represents an authenticated device using HMAC.
 func (m MetaData) IsDevice() bool {
 	return m.kind == auth.HMAC && m.level == auth.Device
@@ -58,7 +81,7 @@ func (m MetaData) IsUser() bool {
 	return m.kind == auth.JWT && m.level != auth.Device
 }
 
-// ctxWithAuth extracts auth.Kind, auth.Role, and optional identifier from API Gateway request context
+// ctxWithAuth extracts auth.Kind, auth.Role, optional identifier and scopes from API Gateway request context
 // and returns a new context with MetaData injected.
 func ctxWithAuth(ctx context.Context, req events.APIGatewayProxyRequest) (context.Context, error) {
 	kindStr, ok := req.RequestContext.Authorizer["kind"].(string)
@@ -83,11 +106,24 @@ func ctxWithAuth(ctx context.Context, req events.APIGatewayProxyRequest) (contex
 		return ctx, errors.Wrap(err, "invalid 'role' format")
 	}
 	level := auth.Role(rawRole)
+	if !auth.RoleIsValid(level) {
+		return ctx, errors.New("invalid 'role' in context")
+	}
+
+	// user id for JWT, profile id for registered devices, empty for the shared device token.
+	identifier, _ := req.RequestContext.Authorizer["identifier"].(string)
+	var device string
 
-	identifier := "ufo"
+	var scopes []string
+	if kind == auth.HMAC {
+		if err := checkBodyHash(req); err != nil {
+			return ctx, err
+		}
+		device, _ = req.RequestContext.Authorizer["device"].(string)
+	}
 	if kind == auth.JWT {
-		if id, ok := req.RequestContext.Authorizer["identifier"].(string); ok {
-			identifier = id
+		if scope, ok := req.RequestContext.Authorizer["scopes"].(string); ok {
+			scopes = auth.ParseScopes(scope)
 		}
 	}
 
@@ -95,5 +131,29 @@ func ctxWithAuth(ctx context.Context, req events.APIGatewayProxyRequest) (contex
 		level:      level,
 		kind:       kind,
 		identifier: identifier,
+		device:     device,
+		scopes:     scopes,
 	}), nil
 }
+
+// checkBodyHash compares the body hash covered by a v2 device signature with the received body.
+// Requests signed with v1 carry no hash and are not checked.
+func checkBodyHash(req events.APIGatewayProxyRequest) error {
+	expected, ok := req.RequestContext.Authorizer["body_hash"].(string)
+	if !ok {
+		return nil
+	}
+
+	body := []byte(req.Body)
+	if req.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(req.Body)
+		if err != nil {
+			return errors.Wrap(err, "invalid base64 body")
+		}
+		body = decoded
+	}
+	if auth.BodyHash(body) != expected {
+		return auth.ErrInvalidBodyHash
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func authRequest(authorizer map[string]interface{}) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		RequestContext: events.APIGatewayProxyRequestContext{Authorizer: authorizer},
+	}
+}
+
+func TestCtxWithAuth(t *testing.T) {
+	ctx, err := ctxWithAuth(context.Background(), authRequest(map[string]interface{}{
+		"kind":       "2",
+		"role":       "6",
+		"identifier": "42",
+		"scopes":     "dictionary:write processing:admin",
+	}))
+	require.NoError(t, err)
+
+	meta := MustGetMetaData(ctx)
+	assert.True(t, meta.IsUser())
+	assert.Equal(t, auth.Admin, meta.GetRole())
+	assert.Equal(t, "42", meta.GetIdentifier())
+	assert.True(t, meta.HasScope(auth.ScopeDictionaryWrite))
+	assert.True(t, meta.HasScope(auth.ScopeProcessingAdmin))
+	assert.False(t, meta.HasScope(auth.ScopeDictionaryModerate))
+	assert.Empty(t, meta.GetDevice())
+
+	// devices never carry scopes.
+	ctx, err = ctxWithAuth(context.Background(), authRequest(map[string]interface{}{
+		"kind":   "1",
+		"role":   "2",
+		"scopes": "dictionary:write",
+	}))
+	require.NoError(t, err)
+	assert.False(t, MustGetMetaData(ctx).HasScope(auth.ScopeDictionaryWrite))
+	assert.Empty(t, MustGetMetaData(ctx).GetIdentifier())
+
+	// registered devices carry their id and the profile they are linked to.
+	ctx, err = ctxWithAuth(context.Background(), authRequest(map[string]interface{}{
+		"kind":       "1",
+		"role":       "2",
+		"identifier": "profile-1",
+		"device":     "device-1",
+	}))
+	require.NoError(t, err)
+	assert.True(t, MustGetMetaData(ctx).IsDevice())
+	assert.Equal(t, "profile-1", MustGetMetaData(ctx).GetIdentifier())
+	assert.Equal(t, "device-1", MustGetMetaData(ctx).GetDevice())
+
+	_, err = ctxWithAuth(context.Background(), authRequest(map[string]interface{}{"kind": "2", "role": "42"}))
+	assert.Error(t, err)
+}
+
+func TestCtxWithAuthBodyHash(t *testing.T) {
+	authorizer := map[string]interface{}{
+		"kind":      "1",
+		"role":      "2",
+		"body_hash": auth.BodyHash([]byte(`{"text":"report"}`)),
+	}
+
+	req := authRequest(authorizer)
+	req.Body = `{"text":"report"}`
+	_, err := ctxWithAuth(context.Background(), req)
+	require.NoError(t, err)
+
+	req.Body = base64.StdEncoding.EncodeToString([]byte(`{"text":"report"}`))
+	req.IsBase64Encoded = true
+	_, err = ctxWithAuth(context.Background(), req)
+	require.NoError(t, err)
+
+	req = authRequest(authorizer)
+	req.Body = `{"text":"replaced"}`
+	_, err = ctxWithAuth(context.Background(), req)
+	assert.ErrorIs(t, err, auth.ErrInvalidBodyHash)
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const bodyKey contextKey = "body"
+
+// ErrInsufficientPermissions is returned when the caller role does not satisfy the route requirements.
+var ErrInsufficientPermissions = errors.New("insufficient permissions")
+
+// Middleware wraps a HandleFunc with additional behavior executed before and/or after it.
+type Middleware func(HandleFunc) HandleFunc
+
+// Chain wraps the handler with the given middlewares.
+// The first middleware is the outermost one and runs first.
+func Chain(handler HandleFunc, middlewares ...Middleware) HandleFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Before returns a middleware which runs hook before the handler.
+// If hook returns an error the handler is not called.
+func Before(hook func(context.Context, zerolog.Logger, json.RawMessage, openapi.QueryParams) (context.Context, *HandleError)) Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, logger zerolog.Logger, body json.RawMessage, params openapi.QueryParams) (any, *HandleError) {
+			ctx, herr := hook(ctx, logger, body, params)
+			if herr != nil {
+				return nil, herr
+			}
+			return next(ctx, logger, body, params)
+		}
+	}
+}
+
+// After returns a middleware which runs hook after the handler with its result.
+// The hook may replace both the result and the error.
+func After(hook func(context.Context, zerolog.Logger, any, *HandleError) (any, *HandleError)) Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, logger zerolog.Logger, body json.RawMessage, params openapi.QueryParams) (any, *HandleError) {
+			result, herr := next(ctx, logger, body, params)
+			return hook(ctx, logger, result, herr)
+		}
+	}
+}
+
+// WithPermissions rejects requests whose role level is lower than the required one.
+func WithPermissions(required auth.Role) Middleware {
+	return Before(func(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (context.Context, *HandleError) {
+		if !MustGetMetaData(ctx).HasPermissions(required) {
+			return ctx, &HandleError{Status: http.StatusForbidden, Err: ErrInsufficientPermissions}
+		}
+		return ctx, nil
+	})
+}
+
+// WithUser rejects device requests and users whose role level is lower than the required one.
+func WithUser(required auth.Role) Middleware {
+	return Before(func(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (context.Context, *HandleError) {
+		meta := MustGetMetaData(ctx)
+		if meta.IsDevice() || !meta.HasPermissions(required) {
+			return ctx, &HandleError{Status: http.StatusForbidden, Err: ErrInsufficientPermissions}
+		}
+		return ctx, nil
+	})
+}
+
+// WithDevice rejects all requests which are not signed by a device.
+func WithDevice() Middleware {
+	return Before(func(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (context.Context, *HandleError) {
+		if !MustGetMetaData(ctx).IsDevice() {
+			return ctx, &HandleError{Status: http.StatusForbidden, Err: ErrInsufficientPermissions}
+		}
+		return ctx, nil
+	})
+}
+
+// WithJSONBody decodes the request body into T, validates it and stores it in the context.
+// Handlers retrieve the decoded value with GetBody or MustGetBody.
+func WithJSONBody[T any](v *validator.Validator) Middleware {
+	return Before(func(ctx context.Context, _ zerolog.Logger, body json.RawMessage, _ openapi.QueryParams) (context.Context, *HandleError) {
+		var req T
+		if err := serializer.UnmarshalJSON(body, &req); err != nil {
+			return ctx, &HandleError{Status: http.StatusBadRequest, Message: "invalid request body", Err: err}
+		}
+		if err := v.ValidateStruct(&req); err != nil {
+			return ctx, NewValidationError(err)
+		}
+		return context.WithValue(ctx, bodyKey, &req), nil
+	})
+}
+
+// GetBody retrieves the request body decoded by WithJSONBody from the context.
+func GetBody[T any](ctx context.Context) (*T, bool) {
+	body, ok := ctx.Value(bodyKey).(*T)
+	return body, ok
+}
+
+// MustGetBody retrieves the request body decoded by WithJSONBody from the context.
+// Panics if the body is not found or has another type.
+func MustGetBody[T any](ctx context.Context) *T {
+	body, ok := GetBody[T](ctx)
+	if !ok {
+		panic("request body not found in context")
+	}
+	return body
+}
+
+// WithRecovery converts a panic inside the handler chain into an internal server error.
+func WithRecovery() Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, logger zerolog.Logger, body json.RawMessage, params openapi.QueryParams) (result any, herr *HandleError) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					logger.Error().
+						Str("stack", string(debug.Stack())).
+						Msgf("Recovered from panic: %v", rec)
+
+					result = nil
+					herr = &HandleError{Status: http.StatusInternalServerError, Err: fmt.Errorf("panic: %v", rec)}
+				}
+			}()
+			return next(ctx, logger, body, params)
+		}
+	}
+}
+
+// WithTiming logs the handler execution time.
+func WithTiming() Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, logger zerolog.Logger, body json.RawMessage, params openapi.QueryParams) (any, *HandleError) {
+			start := time.Now()
+			result, herr := next(ctx, logger, body, params)
+
+			event := logger.Debug().Dur("duration", time.Since(start))
+			if herr != nil {
+				event.Int("status", herr.Status)
+			}
+			event.Msg("Handler finished")
+			return result, herr
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+	"github.com/Mad-Pixels/applingo-api/pkg/auth"
+	"github.com/Mad-Pixels/applingo-api/pkg/validator"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ctxWithMeta(kind auth.Kind, level auth.Role) context.Context {
+	return context.WithValue(context.Background(), metaDataKey, MetaData{kind: kind, level: level})
+}
+
+func TestChainOrder(t *testing.T) {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next HandleFunc) HandleFunc {
+			return func(ctx context.Context, logger zerolog.Logger, body json.RawMessage, params openapi.QueryParams) (any, *HandleError) {
+				calls = append(calls, name+":before")
+				result, herr := next(ctx, logger, body, params)
+				calls = append(calls, name+":after")
+				return result, herr
+			}
+		}
+	}
+
+	_, herr := Chain(noopHandler, trace("a"), trace("b"))(context.Background(), zerolog.Nop(), nil, openapi.NewQueryParams(nil))
+	require.Nil(t, herr)
+	assert.Equal(t, []string{"a:before", "b:before", "b:after", "a:after"}, calls)
+}
+
+func TestPermissionMiddlewares(t *testing.T) {
+	tests := []struct {
+		name       string
+		middleware Middleware
+		ctx        context.Context
+		allowed    bool
+	}{
+		{"permissions device ok", WithPermissions(auth.Device), ctxWithMeta(auth.HMAC, auth.Device), true},
+		{"permissions guest denied", WithPermissions(auth.Device), ctxWithMeta(auth.HMAC, auth.Guest), false},
+		{"user ok", WithUser(auth.User), ctxWithMeta(auth.JWT, auth.User), true},
+		{"user device denied", WithUser(auth.User), ctxWithMeta(auth.HMAC, auth.Device), false},
+		{"device ok", WithDevice(), ctxWithMeta(auth.HMAC, auth.Device), true},
+		{"device user denied", WithDevice(), ctxWithMeta(auth.JWT, auth.Admin), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, herr := Chain(noopHandler, tt.middleware)(tt.ctx, zerolog.Nop(), nil, openapi.NewQueryParams(nil))
+			if tt.allowed {
+				assert.Nil(t, herr)
+				return
+			}
+			require.NotNil(t, herr)
+			assert.Equal(t, http.StatusForbidden, herr.Status)
+		})
+	}
+}
+
+func TestWithJSONBody(t *testing.T) {
+	type request struct {
+		Name string `json:"name" validate:"required"`
+	}
+	handler := Chain(
+		func(ctx context.Context, _ zerolog.Logger, _ json.RawMessage, _ openapi.QueryParams) (any, *HandleError) {
+			return MustGetBody[request](ctx).Name, nil
+		},
+		WithJSONBody[request](validator.New()),
+	)
+
+	result, herr := handler(context.Background(), zerolog.Nop(), json.RawMessage(`{"name":"test"}`), openapi.NewQueryParams(nil))
+	require.Nil(t, herr)
+	assert.Equal(t, "test", result)
+
+	for _, body := range []string{`{"name":""}`, `{invalid`} {
+		_, herr = handler(context.Background(), zerolog.Nop(), json.RawMessage(body), openapi.NewQueryParams(nil))
+		require.NotNil(t, herr, "Expected error for body: %s", body)
+		assert.Equal(t, http.StatusBadRequest, herr.Status)
+	}
+}
+
+func TestWithRecovery(t *testing.T) {
+	handler := Chain(
+		func(context.Context, zerolog.Logger, json.RawMessage, openapi.QueryParams) (any, *HandleError) {
+			panic("boom")
+		},
+		WithRecovery(),
+	)
+
+	result, herr := handler(context.Background(), zerolog.Nop(), nil, openapi.NewQueryParams(nil))
+	assert.Nil(t, result)
+	require.NotNil(t, herr)
+	assert.Equal(t, http.StatusInternalServerError, herr.Status)
+}
@@ -7,6 +7,14 @@ import (
 	"github.com/aws/aws-lambda-go/events"
 )
 
+// Response is returned by handlers which set the status code or headers of a successful response.
+// A zero Status keeps the default status of the method, a nil Body sends an empty body.
+type Response struct {
+	Status  int
+	Headers map[string]string
+	Body    any
+}
+
 func gatewayResponse(statusCode int, body any, headers map[string]string) (events.APIGatewayProxyResponse, error) {
 	if headers == nil {
 		headers = make(map[string]string)
@@ -31,3 +39,14 @@ func gatewayResponse(statusCode int, body any, headers map[string]string) (event
 		Body:       string(jsonBody),
 	}, nil
 }
+
+// emptyResponse returns a response without a body, e.g. 304 Not Modified.
+func emptyResponse(statusCode int, headers map[string]string) events.APIGatewayProxyResponse {
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    headers,
+	}
+}
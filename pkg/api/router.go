@@ -0,0 +1,213 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+)
+
+const pathParamsKey contextKey = "path_params"
+
+// segment weights used to pick the most specific pattern when several routes match one path.
+const (
+	weightGreedy = iota + 1
+	weightParam
+	weightStatic
+)
+
+// GetPathParams retrieves path parameters resolved by the router from the given context.
+// Returns empty PathParams if the route has no parameters.
+func GetPathParams(ctx context.Context) openapi.PathParams {
+	params, ok := ctx.Value(pathParamsKey).(openapi.PathParams)
+	if !ok {
+		return openapi.NewPathParams(nil)
+	}
+	return params
+}
+
+// ctxWithPathParams returns a new context with resolved path parameters injected.
+func ctxWithPathParams(ctx context.Context, params map[string]string) context.Context {
+	return context.WithValue(ctx, pathParamsKey, openapi.NewPathParams(params))
+}
+
+// route is a single registered "METHOD:/resource/{param}" handler.
+type route struct {
+	method   string
+	pattern  string
+	segments []string
+	handler  HandleFunc
+}
+
+// router resolves incoming requests to registered routes using path templates.
+type router struct {
+	routes []route
+}
+
+// routeMatch is the result of resolving a request against the router.
+type routeMatch struct {
+	route   *route
+	params  map[string]string
+	allowed []string
+}
+
+// newRouter parses the handlers map into routes.
+// Keys must have the "METHOD:/path" format where path segments may be
+// parameters ("{id}") or a trailing greedy parameter ("{proxy+}").
+func newRouter(handlers map[string]HandleFunc) (*router, error) {
+	r := &router{routes: make([]route, 0, len(handlers))}
+
+	for key, handler := range handlers {
+		if handler == nil {
+			return nil, fmt.Errorf("handler for '%s' cannot be nil", key)
+		}
+		method, pattern, ok := strings.Cut(key, ":")
+		if !ok || method == "" || !strings.HasPrefix(pattern, "/") {
+			return nil, fmt.Errorf("invalid route '%s', expected 'METHOD:/path'", key)
+		}
+		segments := splitPath(pattern)
+		for i, s := range segments {
+			if !isParam(s) {
+				continue
+			}
+			if paramName(s) == "" {
+				return nil, fmt.Errorf("invalid route '%s', empty path parameter", key)
+			}
+			if isGreedy(s) && i != len(segments)-1 {
+				return nil, fmt.Errorf("invalid route '%s', greedy parameter must be the last segment", key)
+			}
+		}
+		r.routes = append(r.routes, route{
+			method:   strings.ToUpper(method),
+			pattern:  "/" + strings.Join(segments, "/"),
+			segments: segments,
+			handler:  handler,
+		})
+	}
+	return r, nil
+}
+
+// match resolves the request method and path to a route.
+// resourcePath is the API Gateway resource template (e.g. "/v1/dictionaries/{id}") with its
+// already extracted pathParameters, path is the raw request path used as a fallback.
+// If the path matches but the method does not, routeMatch.route is nil and allowed lists
+// the methods registered for that path.
+func (r *router) match(method, resourcePath string, pathParameters map[string]string, path string) (routeMatch, bool) {
+	method = strings.ToUpper(method)
+
+	if resourcePath != "" {
+		normalized := "/" + strings.Join(splitPath(resourcePath), "/")
+		var candidates []*route
+		for i := range r.routes {
+			if r.routes[i].pattern == normalized {
+				candidates = append(candidates, &r.routes[i])
+			}
+		}
+		if len(candidates) > 0 {
+			return resolve(candidates, method, pathParameters), true
+		}
+	}
+
+	var (
+		segments   = splitPath(path)
+		best       []int
+		bestParams map[string]string
+		candidates []*route
+	)
+	for i := range r.routes {
+		weights, params, ok := r.routes[i].matchSegments(segments)
+		if !ok {
+			continue
+		}
+		switch cmp := compareWeights(weights, best); {
+		case best == nil || cmp > 0:
+			best, bestParams = weights, params
+			candidates = []*route{&r.routes[i]}
+		case cmp == 0:
+			candidates = append(candidates, &r.routes[i])
+		}
+	}
+	if len(candidates) == 0 {
+		return routeMatch{}, false
+	}
+	return resolve(candidates, method, bestParams), true
+}
+
+// matchSegments checks the request path segments against the route template
+// and returns the per-segment weights and extracted parameters.
+func (rt *route) matchSegments(segments []string) ([]int, map[string]string, bool) {
+	var (
+		weights = make([]int, 0, len(rt.segments))
+		params  = make(map[string]string)
+	)
+	for i, s := range rt.segments {
+		if isGreedy(s) {
+			if i >= len(segments) {
+				return nil, nil, false
+			}
+			params[paramName(s)] = strings.Join(segments[i:], "/")
+			return append(weights, weightGreedy), params, true
+		}
+		if i >= len(segments) {
+			return nil, nil, false
+		}
+		if isParam(s) {
+			params[paramName(s)] = segments[i]
+			weights = append(weights, weightParam)
+			continue
+		}
+		if s != segments[i] {
+			return nil, nil, false
+		}
+		weights = append(weights, weightStatic)
+	}
+	if len(rt.segments) != len(segments) {
+		return nil, nil, false
+	}
+	return weights, params, true
+}
+
+// resolve picks the route for the method among routes sharing the same path template.
+func resolve(candidates []*route, method string, params map[string]string) routeMatch {
+	allowed := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if c.method == method {
+			return routeMatch{route: c, params: params}
+		}
+		allowed = append(allowed, c.method)
+	}
+	sort.Strings(allowed)
+	return routeMatch{allowed: allowed}
+}
+
+// compareWeights compares segment weights left to right, static segments win over parameters.
+func compareWeights(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return len(a) - len(b)
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func isGreedy(segment string) bool {
+	return isParam(segment) && strings.HasSuffix(segment, "+}")
+}
+
+func paramName(segment string) string {
+	return strings.TrimSuffix(strings.Trim(segment, "{}"), "+")
+}
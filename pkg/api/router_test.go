@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Mad-Pixels/applingo-api/openapi-interface"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noopHandler(context.Context, zerolog.Logger, json.RawMessage, openapi.QueryParams) (any, *HandleError) {
+	return nil, nil
+}
+
+func TestRouterMatch(t *testing.T) {
+	r, err := newRouter(map[string]HandleFunc{
+		"GET:/v1/dictionaries":              noopHandler,
+		"GET:/v1/dictionaries/{id}":         noopHandler,
+		"DELETE:/v1/dictionaries/{id}":      noopHandler,
+		"GET:/v1/dictionaries/search":       noopHandler,
+		"PATCH:/v1/dictionary/statistic":    noopHandler,
+		"GET:/v1/files/{proxy+}":            noopHandler,
+		"GET:/v1/dictionaries/{id}/version": noopHandler,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		pattern string
+		params  map[string]string
+		allowed []string
+		found   bool
+	}{
+		{"static", "GET", "/v1/dictionaries", "/v1/dictionaries", map[string]string{}, nil, true},
+		{"param", "GET", "/v1/dictionaries/abc", "/v1/dictionaries/{id}", map[string]string{"id": "abc"}, nil, true},
+		{"static wins over param", "GET", "/v1/dictionaries/search", "/v1/dictionaries/search", map[string]string{}, nil, true},
+		{"nested param", "GET", "/v1/dictionaries/abc/version", "/v1/dictionaries/{id}/version", map[string]string{"id": "abc"}, nil, true},
+		{"greedy", "GET", "/v1/files/a/b/c.json", "/v1/files/{proxy+}", map[string]string{"proxy": "a/b/c.json"}, nil, true},
+		{"trailing slash", "GET", "/v1/dictionaries/", "/v1/dictionaries", map[string]string{}, nil, true},
+		{"method not allowed", "POST", "/v1/dictionaries/abc", "", nil, []string{"DELETE", "GET"}, true},
+		{"unknown", "GET", "/v1/unknown", "", nil, nil, false},
+		{"greedy requires segment", "GET", "/v1/files", "", nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, ok := r.match(tt.method, "", nil, tt.path)
+			assert.Equal(t, tt.found, ok)
+			if !ok {
+				return
+			}
+			if tt.pattern == "" {
+				assert.Nil(t, m.route)
+				assert.Equal(t, tt.allowed, m.allowed)
+				return
+			}
+			require.NotNil(t, m.route)
+			assert.Equal(t, tt.pattern, m.route.pattern)
+			assert.Equal(t, tt.params, m.params)
+		})
+	}
+}
+
+func TestRouterMatchResourcePath(t *testing.T) {
+	r, err := newRouter(map[string]HandleFunc{
+		"GET:/v1/dictionaries/{id}": noopHandler,
+	})
+	require.NoError(t, err)
+
+	m, ok := r.match("GET", "/v1/dictionaries/{id}", map[string]string{"id": "abc"}, "/prod/v1/dictionaries/abc")
+	require.True(t, ok)
+	require.NotNil(t, m.route)
+	assert.Equal(t, map[string]string{"id": "abc"}, m.params)
+}
+
+func TestNewRouterInvalid(t *testing.T) {
+	for _, key := range []string{
+		"/v1/dictionaries",
+		"GET:v1/dictionaries",
+		"GET:/v1/{}/items",
+		"GET:/v1/{proxy+}/items",
+	} {
+		_, err := newRouter(map[string]HandleFunc{key: noopHandler})
+		assert.Error(t, err, "Expected invalid route: %s", key)
+	}
+}
@@ -13,6 +13,9 @@ const (
 	// HeaderSignature is the HTTP header name used to send the HMAC signature.
 	HeaderSignature = "x-signature"
 
+	// HeaderContentHash is the HTTP header name used to send the hex SHA-256 of the body signed by v2 signatures.
+	HeaderContentHash = "x-content-sha256"
+
 	// HeaderAuth is the HTTP header name used to send the JWT token.
 	HeaderAuth = "Authorization"
 )
@@ -27,13 +30,14 @@ type Authenticator struct {
 }
 
 // NewAuthenticator creates a new instance of Authenticator.
-func NewAuthenticator(deviceToken string, jwtSecret string) *Authenticator {
+// JWT options configure asymmetric keys and issuer/audience checks.
+func NewAuthenticator(deviceToken string, jwtSecret string, opts ...JWTOption) *Authenticator {
 	auth := &Authenticator{
 		deviceToken: deviceToken,
 		jwtSecret:   []byte(jwtSecret),
 	}
 	auth.hmac = NewHMACAuth(deviceToken)
-	auth.jwt = NewJWTAuth(jwtSecret)
+	auth.jwt = NewJWTAuth(jwtSecret, opts...)
 	return auth
 }
 
@@ -42,12 +46,23 @@ func (a *Authenticator) ValidateDeviceRequest(timestamp, signature string) error
 	return a.hmac.ValidateRequest(timestamp, signature)
 }
 
+// ValidateDeviceRequestV2 validates a v2 device signature bound to the request.
+// The nonce is not recorded, callers must claim it in a NonceStore.
+func (a *Authenticator) ValidateDeviceRequestV2(req SignedRequest) error {
+	return a.hmac.ValidateSignedRequest(req)
+}
+
 // ValidateJWTToken validates a JWT token and returns the parsed claims.
 func (a *Authenticator) ValidateJWTToken(tokenString string) (*Claims, error) {
 	return a.jwt.ValidateToken(tokenString)
 }
 
-// GenerateToken generates a new JWT token with the given user ID, role, and expiration time.
-func (a *Authenticator) GenerateToken(userID int, role Role, expiresIn time.Duration) (string, error) {
-	return a.jwt.GenerateToken(userID, role, expiresIn)
+// GenerateToken generates a new JWT token with the given user ID, role, expiration time and optional scopes.
+func (a *Authenticator) GenerateToken(userID int, role Role, expiresIn time.Duration, scopes ...string) (string, error) {
+	return a.jwt.GenerateToken(userID, role, expiresIn, scopes...)
+}
+
+// IssueToken signs the claims as a JWT token which expires in expiresIn.
+func (a *Authenticator) IssueToken(claims Claims, expiresIn time.Duration) (string, error) {
+	return a.jwt.IssueToken(claims, expiresIn)
 }
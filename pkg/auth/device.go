@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/hex"
+
+	sha256 "github.com/minio/sha256-simd"
+)
+
+// HeaderDeviceID is the HTTP header name used to send the registered device id.
+const HeaderDeviceID = "x-device-id"
+
+// DeviceKeys derives per-device secrets from the master key.
+// Secrets are never stored, a device is disabled by revoking it in the DeviceRegistry.
+type DeviceKeys struct {
+	master []byte
+}
+
+// NewDeviceKeys creates a device secret deriver, an empty master key disables per-device credentials.
+func NewDeviceKeys(master string) *DeviceKeys {
+	return &DeviceKeys{master: []byte(master)}
+}
+
+// Enabled reports whether the master key is configured.
+func (k *DeviceKeys) Enabled() bool {
+	return k != nil && len(k.master) > 0
+}
+
+// Secret returns the signing secret of the device.
+func (k *DeviceKeys) Secret(deviceID string) string {
+	mac := hmac.New(sha256.New, k.master)
+	mac.Write([]byte("device:" + deviceID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidateSignedRequest validates v2 signature of the request made with the device secret.
+func (k *DeviceKeys) ValidateSignedRequest(deviceID string, req SignedRequest) error {
+	if !k.Enabled() {
+		return ErrNoDeviceToken
+	}
+	if deviceID == "" {
+		return ErrMissingHeaders
+	}
+	return NewHMACAuth(k.Secret(deviceID)).ValidateSignedRequest(req)
+}
+
+// DeviceRegistry holds registered devices, the profiles they are linked to and their revocation state.
+type DeviceRegistry interface {
+	// Active returns the profile id of a registered device which is not revoked,
+	// ErrUnknownDevice or ErrDeviceRevoked otherwise.
+	Active(ctx context.Context, deviceID string) (string, error)
+}
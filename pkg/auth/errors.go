@@ -18,9 +18,33 @@ var (
 	// ErrInvalidSignature means the HMAC signature validation failed.
 	ErrInvalidSignature = errors.New("invalid signature")
 
+	// ErrInvalidNonce means the request nonce is missing or malformed.
+	ErrInvalidNonce = errors.New("invalid nonce")
+
+	// ErrNonceReused means the request nonce was already seen, i.e. the request is replayed.
+	ErrNonceReused = errors.New("nonce already used")
+
+	// ErrInvalidBodyHash means the body hash header is missing, malformed or does not match the body.
+	ErrInvalidBodyHash = errors.New("invalid body hash")
+
+	// ErrUnsupportedSignatureVersion means the signature scheme version is unknown or no longer accepted.
+	ErrUnsupportedSignatureVersion = errors.New("unsupported signature version")
+
+	// ErrUnknownDevice means the device id is not registered.
+	ErrUnknownDevice = errors.New("unknown device")
+
+	// ErrDeviceRevoked means the device credentials were revoked.
+	ErrDeviceRevoked = errors.New("device revoked")
+
 	// ErrUnexpectedSigningMethod means the JWT signing method does not match the expected one.
 	ErrUnexpectedSigningMethod = errors.New("unexpected signing method")
 
 	// ErrInvalidTokenClaims means the JWT token contains invalid claims.
 	ErrInvalidTokenClaims = errors.New("invalid token claims")
+
+	// ErrUnknownKey means the JWT token is signed with a key missing from the key set.
+	ErrUnknownKey = errors.New("unknown signing key")
+
+	// ErrUnsupportedKey means the key type or curve is not supported.
+	ErrUnsupportedKey = errors.New("unsupported key")
 )
@@ -3,7 +3,10 @@ package auth
 import (
 	"crypto/hmac"
 	"encoding/hex"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	sha256 "github.com/minio/sha256-simd"
@@ -29,7 +32,7 @@ func (h *HMACAuth) GenerateSignature(data string) string {
 	return hex.EncodeToString(mac.Sum(nil))
 }
 
-// ValidateRequest validates HMAC-based request
+// ValidateRequest validates v1 HMAC-based request, the signature covers the timestamp only.
 func (h *HMACAuth) ValidateRequest(timestamp, signature string) error {
 	if timestamp == "" || signature == "" {
 		return ErrMissingHeaders
@@ -38,6 +41,19 @@ func (h *HMACAuth) ValidateRequest(timestamp, signature string) error {
 		return ErrNoDeviceToken
 	}
 
+	if err := h.checkTimestamp(timestamp); err != nil {
+		return err
+	}
+
+	expectedSignature := h.GenerateSignature(timestamp)
+	if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// checkTimestamp validates that the unix timestamp is within TimestampDelay of the current time.
+func (h *HMACAuth) checkTimestamp(timestamp string) error {
 	ts, err := strconv.ParseInt(timestamp, 10, 64)
 	if err != nil {
 		return errors.Wrap(ErrTimestampParse, err.Error())
@@ -46,10 +62,127 @@ func (h *HMACAuth) ValidateRequest(timestamp, signature string) error {
 	if currentTime-ts > TimestampDelay || ts > currentTime+TimestampDelay {
 		return ErrTimestampExpired
 	}
+	return nil
+}
 
-	expectedSignature := h.GenerateSignature(timestamp)
-	if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
+// SignatureVersion identifies the device signature scheme.
+type SignatureVersion int
+
+const (
+	// SignatureV1 signs the timestamp only: "<timestamp>:::<signature>".
+	SignatureV1 SignatureVersion = iota + 1
+	// SignatureV2 signs the timestamp, nonce, method, path, query and body hash:
+	// "v2:::<timestamp>:::<nonce>:::<signature>" with the body hash in HeaderContentHash.
+	SignatureV2
+)
+
+// NonceTTL defines how long a nonce is remembered, it covers the whole timestamp window.
+const NonceTTL = 2 * TimestampDelay * time.Second
+
+const (
+	nonceMinLength = 16
+	nonceMaxLength = 64
+)
+
+// String returns the header prefix of the SignatureVersion, e.g. "v2".
+func (v SignatureVersion) String() string {
+	return "v" + strconv.Itoa(int(v))
+}
+
+// ParseSignatureVersion converts a header prefix such as "v2" into a SignatureVersion.
+func ParseSignatureVersion(s string) (SignatureVersion, bool) {
+	for _, v := range []SignatureVersion{SignatureV1, SignatureV2} {
+		if v.String() == s {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// SignedRequest holds the request parts covered by a v2 signature.
+type SignedRequest struct {
+	Method    string
+	Path      string
+	Query     url.Values
+	BodyHash  string
+	Timestamp string
+	Nonce     string
+	Signature string
+}
+
+// StringToSign returns the canonical string signed by v2 signatures.
+func (r SignedRequest) StringToSign() string {
+	return strings.Join([]string{
+		SignatureV2.String(),
+		r.Timestamp,
+		r.Nonce,
+		strings.ToUpper(r.Method),
+		r.Path,
+		CanonicalQuery(r.Query),
+		r.BodyHash,
+	}, "\n")
+}
+
+// CanonicalQuery returns the query string covered by v2 signatures: parameters sorted by name
+// and the values of a parameter sorted, each escaped as in url.Values.Encode. No query gives "".
+func CanonicalQuery(query url.Values) string {
+	sorted := make(url.Values, len(query))
+	for name, values := range query {
+		sorted[name] = slices.Sorted(slices.Values(values))
+	}
+	return sorted.Encode()
+}
+
+// BodyHash returns the hex encoded SHA-256 of the request body.
+func BodyHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// SignRequest returns the v2 signature of the request.
+func (h *HMACAuth) SignRequest(r SignedRequest) string {
+	return h.GenerateSignature(r.StringToSign())
+}
+
+// ValidateSignedRequest validates v2 signature of the request.
+// The nonce format is checked here, its uniqueness is up to the NonceStore.
+func (h *HMACAuth) ValidateSignedRequest(r SignedRequest) error {
+	if r.Timestamp == "" || r.Signature == "" || r.Method == "" || r.Path == "" {
+		return ErrMissingHeaders
+	}
+	if len(h.secret) == 0 {
+		return ErrNoDeviceToken
+	}
+	if !validNonce(r.Nonce) {
+		return ErrInvalidNonce
+	}
+	if len(r.BodyHash) != sha256.Size*2 || r.BodyHash != strings.ToLower(r.BodyHash) {
+		return ErrInvalidBodyHash
+	}
+	if _, err := hex.DecodeString(r.BodyHash); err != nil {
+		return ErrInvalidBodyHash
+	}
+	if err := h.checkTimestamp(r.Timestamp); err != nil {
+		return err
+	}
+
+	if !hmac.Equal([]byte(r.Signature), []byte(h.SignRequest(r))) {
 		return ErrInvalidSignature
 	}
 	return nil
 }
+
+// validNonce accepts URL-safe nonces long enough to be random.
+func validNonce(nonce string) bool {
+	if len(nonce) < nonceMinLength || len(nonce) > nonceMaxLength {
+		return false
+	}
+	for _, c := range nonce {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
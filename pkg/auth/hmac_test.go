@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedRequest(h *HMACAuth, body string) SignedRequest {
+	req := SignedRequest{
+		Method:    "POST",
+		Path:      "/v1/reports",
+		Query:     url.Values{"b": {"2", "1"}, "a": {"x y"}},
+		BodyHash:  BodyHash([]byte(body)),
+		Timestamp: strconv.FormatInt(time.Now().Unix(), 10),
+		Nonce:     "0123456789abcdef-nonce",
+	}
+	req.Signature = h.SignRequest(req)
+	return req
+}
+
+func TestValidateSignedRequest(t *testing.T) {
+	h := NewHMACAuth("device-token")
+
+	req := signedRequest(h, `{"text":"report"}`)
+	require.NoError(t, h.ValidateSignedRequest(req))
+
+	// the signature is bound to every request part.
+	for name, mutate := range map[string]func(*SignedRequest){
+		"method":    func(r *SignedRequest) { r.Method = "DELETE" },
+		"path":      func(r *SignedRequest) { r.Path = "/v1/profile" },
+		"query":     func(r *SignedRequest) { r.Query = url.Values{"b": {"2", "1"}, "a": {"x y"}, "limit": {"1000"}} },
+		"no query":  func(r *SignedRequest) { r.Query = nil },
+		"body":      func(r *SignedRequest) { r.BodyHash = BodyHash([]byte(`{}`)) },
+		"nonce":     func(r *SignedRequest) { r.Nonce = "fedcba9876543210-nonce" },
+		"timestamp": func(r *SignedRequest) { r.Timestamp = strconv.FormatInt(time.Now().Unix()-1, 10) },
+	} {
+		changed := req
+		mutate(&changed)
+		assert.ErrorIs(t, h.ValidateSignedRequest(changed), ErrInvalidSignature, name)
+	}
+
+	// the order of the parameters and of their values is not signed.
+	reordered := req
+	reordered.Query = url.Values{"a": {"x y"}, "b": {"1", "2"}}
+	assert.NoError(t, h.ValidateSignedRequest(reordered))
+
+	bad := req
+	bad.Nonce = "short"
+	assert.ErrorIs(t, h.ValidateSignedRequest(bad), ErrInvalidNonce)
+
+	bad = req
+	bad.BodyHash = "not-a-hash"
+	assert.ErrorIs(t, h.ValidateSignedRequest(bad), ErrInvalidBodyHash)
+
+	bad = req
+	bad.Timestamp = strconv.FormatInt(time.Now().Unix()-TimestampDelay-5, 10)
+	bad.Signature = h.SignRequest(bad)
+	assert.ErrorIs(t, h.ValidateSignedRequest(bad), ErrTimestampExpired)
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	assert.Equal(t, "", CanonicalQuery(nil))
+	assert.Equal(t, "a=x+y&b=1&b=2&c=%26", CanonicalQuery(url.Values{"c": {"&"}, "b": {"2", "1"}, "a": {"x y"}}))
+}
+
+func TestParseSignatureVersion(t *testing.T) {
+	v, ok := ParseSignatureVersion("v2")
+	assert.True(t, ok)
+	assert.Equal(t, SignatureV2, v)
+
+	_, ok = ParseSignatureVersion("v3")
+	assert.False(t, ok)
+}
+
+func TestMemoryNonceStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryNonceStore()
+
+	require.NoError(t, s.Claim(ctx, "nonce", time.Minute))
+	assert.ErrorIs(t, s.Claim(ctx, "nonce", time.Minute), ErrNonceReused)
+
+	require.NoError(t, s.Claim(ctx, "expired", -time.Second))
+	assert.NoError(t, s.Claim(ctx, "expired", time.Minute))
+}
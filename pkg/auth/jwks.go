@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultJWKSRefresh defines how often the key set is reloaded from its source.
+	DefaultJWKSRefresh = 15 * time.Minute
+
+	jwksMinRefresh  = 30 * time.Second
+	jwksLoadTimeout = 3 * time.Second
+)
+
+// JWK represents a single JSON Web Key (RFC 7517) used for signature verification.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// RSA public key
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC public key
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// VerificationKey is a public key of the set together with the only algorithm it verifies.
+type VerificationKey struct {
+	Key crypto.PublicKey
+	Alg string
+}
+
+// JWKSDocument represents a JSON Web Key Set document.
+type JWKSDocument struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK encodes an RSA or ECDSA public key as a JWK with the given key id.
+func NewJWK(kid string, key crypto.PublicKey) (JWK, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		algs := map[int]string{256: "ES256", 384: "ES384", 521: "ES512"}
+		return JWK{
+			Kty: "EC",
+			Kid: kid,
+			Use: "sig",
+			Alg: algs[k.Curve.Params().BitSize],
+			Crv: k.Curve.Params().Name,
+			X:   base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, size))),
+		}, nil
+	default:
+		return JWK{}, ErrUnsupportedKey
+	}
+}
+
+// PublicKey decodes the JWK into *rsa.PublicKey or *ecdsa.PublicKey.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid RSA modulus")
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid RSA exponent")
+		}
+		if len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Wrapf(ErrUnsupportedKey, "curve '%s'", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid EC x coordinate")
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid EC y coordinate")
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("invalid EC key: point is not on curve")
+		}
+		return key, nil
+	default:
+		return nil, errors.Wrapf(ErrUnsupportedKey, "key type '%s'", k.Kty)
+	}
+}
+
+// ParseJWKS parses a JWKS document and returns the signature verification keys by kid.
+// Keys intended for encryption are skipped, keys without "alg" are bound to the default algorithm of their type.
+func ParseJWKS(data []byte) (map[string]VerificationKey, error) {
+	var doc JWKSDocument
+	if err := serializer.UnmarshalJSON(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "invalid JWKS document")
+	}
+
+	keys := make(map[string]VerificationKey, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if jwk.Kid == "" {
+			return nil, errors.New("invalid JWKS document: key without kid")
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid JWKS key '%s'", jwk.Kid)
+		}
+		alg := jwk.Alg
+		if alg == "" {
+			alg = defaultAlg(key)
+		}
+		keys[jwk.Kid] = VerificationKey{Key: key, Alg: alg}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("invalid JWKS document: no signing keys")
+	}
+	return keys, nil
+}
+
+// JWKSLoader returns the raw JWKS document from its source.
+type JWKSLoader func(ctx context.Context) ([]byte, error)
+
+// StaticJWKS returns a loader serving a fixed document, e.g. taken from an environment variable.
+func StaticJWKS(document string) JWKSLoader {
+	return func(context.Context) ([]byte, error) {
+		return []byte(document), nil
+	}
+}
+
+// JWKS is a set of verification keys identified by kid.
+// It is reloaded periodically and on unknown kid, so several keys can be active during rotation.
+type JWKS struct {
+	loader  JWKSLoader
+	refresh time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]VerificationKey
+	checkedAt time.Time // last load attempt, failed loads keep the previous keys
+}
+
+// NewJWKS loads the key set from the loader, refresh defines the reload interval.
+func NewJWKS(ctx context.Context, loader JWKSLoader, refresh time.Duration) (*JWKS, error) {
+	if refresh <= 0 {
+		refresh = DefaultJWKSRefresh
+	}
+	s := &JWKS{loader: loader, refresh: refresh}
+	if err := s.load(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Key returns the verification key by kid.
+func (s *JWKS) Key(ctx context.Context, kid string) (VerificationKey, error) {
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	age := time.Since(s.checkedAt)
+	s.mu.RUnlock()
+
+	if (!ok && age > jwksMinRefresh) || age > s.refresh {
+		if err := s.load(ctx); err == nil {
+			s.mu.RLock()
+			key, ok = s.keys[kid]
+			s.mu.RUnlock()
+		}
+	}
+	if !ok {
+		return VerificationKey{}, errors.Wrapf(ErrUnknownKey, "kid '%s'", kid)
+	}
+	return key, nil
+}
+
+func (s *JWKS) load(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, jwksLoadTimeout)
+	defer cancel()
+
+	s.mu.Lock()
+	s.checkedAt = time.Now()
+	s.mu.Unlock()
+
+	data, err := s.loader(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to load JWKS")
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+	return nil
+}
+
+func defaultAlg(key crypto.PublicKey) string {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return "RS256"
+	case *ecdsa.PublicKey:
+		return map[string]string{"P-256": "ES256", "P-384": "ES384", "P-521": "ES512"}[k.Curve.Params().Name]
+	default:
+		return ""
+	}
+}
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"context"
+	"strconv"
 	"strings"
 	"time"
 
@@ -8,33 +10,79 @@ import (
 	"github.com/pkg/errors"
 )
 
-// Claims represents JWT claims structure
+// validMethods lists the accepted JWT signing algorithms, any other "alg" header is rejected before verification.
+var validMethods = []string{"RS256", "ES256", "HS256"}
+
+// Claims represents JWT claims structure.
+// Users identified by a string id, e.g. a profile id, carry it in the "sub" claim.
 type Claims struct {
-	Identifier int  `json:"identifier"`
-	Role       Role `json:"role"`
+	Identifier int    `json:"identifier"`
+	Role       Role   `json:"role"`
+	Scope      string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// JWTAuth handles JWT-specific authentication
+// Scopes returns the scopes granted by the space-delimited "scope" claim.
+func (c *Claims) Scopes() []string {
+	return ParseScopes(c.Scope)
+}
+
+// JWTAuth handles JWT-specific authentication.
+// HMAC tokens are verified with the shared secret, RSA and ECDSA tokens with the key set by kid.
 type JWTAuth struct {
-	secret []byte
+	secret   []byte
+	jwks     *JWKS
+	issuer   string
+	audience string
+}
+
+// JWTOption defines a functional option for configuring JWTAuth.
+type JWTOption func(*JWTAuth)
+
+// WithJWKS returns a JWTOption that enables RS256/ES256 tokens verified against the key set.
+func WithJWKS(jwks *JWKS) JWTOption {
+	return func(j *JWTAuth) {
+		j.jwks = jwks
+	}
+}
+
+// WithIssuer returns a JWTOption that requires the "iss" claim to match the issuer.
+func WithIssuer(issuer string) JWTOption {
+	return func(j *JWTAuth) {
+		j.issuer = issuer
+	}
 }
 
-// NewJWTAuth creates new JWT authenticator instance
-func NewJWTAuth(secret string) *JWTAuth {
-	return &JWTAuth{
+// WithAudience returns a JWTOption that requires the "aud" claim to contain the audience.
+func WithAudience(audience string) JWTOption {
+	return func(j *JWTAuth) {
+		j.audience = audience
+	}
+}
+
+// NewJWTAuth creates new JWT authenticator instance.
+// An empty secret disables HMAC tokens.
+func NewJWTAuth(secret string, opts ...JWTOption) *JWTAuth {
+	j := &JWTAuth{
 		secret: []byte(secret),
 	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
 }
 
 // ValidateToken validates JWT token and returns claims
 func (j *JWTAuth) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(strings.TrimPrefix(tokenString, "Bearer "), &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrUnexpectedSigningMethod
-		}
-		return j.secret, nil
-	})
+	parserOpts := []jwt.ParserOption{jwt.WithValidMethods(validMethods)}
+	if j.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(j.issuer))
+	}
+	if j.audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(j.audience))
+	}
+
+	token, err := jwt.ParseWithClaims(strings.TrimPrefix(tokenString, "Bearer "), &Claims{}, j.keyFunc, parserOpts...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse token")
 	}
@@ -45,17 +93,70 @@ func (j *JWTAuth) ValidateToken(tokenString string) (*Claims, error) {
 	return nil, ErrInvalidTokenClaims
 }
 
-// GenerateToken creates new JWT token with provided claims
-func (j *JWTAuth) GenerateToken(identifier int, role Role, expiresIn time.Duration) (string, error) {
-	claims := Claims{
+// keyFunc resolves the verification key by the token signing method and kid.
+// A key of the set verifies only tokens signed with the algorithm of its JWK.
+func (j *JWTAuth) keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if len(j.secret) == 0 {
+			return nil, ErrUnexpectedSigningMethod
+		}
+		return j.secret, nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		if j.jwks == nil {
+			return nil, ErrUnexpectedSigningMethod
+		}
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.Wrap(ErrUnknownKey, "missing kid header")
+		}
+		key, err := j.jwks.Key(context.Background(), kid)
+		if err != nil {
+			return nil, err
+		}
+		if key.Alg != token.Method.Alg() {
+			return nil, errors.Wrapf(ErrUnexpectedSigningMethod, "kid '%s' is not used with '%s'", kid, token.Method.Alg())
+		}
+		return key.Key, nil
+	default:
+		return nil, ErrUnexpectedSigningMethod
+	}
+}
+
+// GenerateToken creates new JWT token with provided claims and optional scopes
+func (j *JWTAuth) GenerateToken(identifier int, role Role, expiresIn time.Duration, scopes ...string) (string, error) {
+	return j.IssueToken(Claims{
 		Identifier: identifier,
 		Role:       role,
+		Scope:      FormatScopes(scopes),
+	}, expiresIn)
+}
 
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+// IssueToken signs the claims with the HMAC secret.
+// Expiration and issue time are set from expiresIn, issuer and audience from the configured checks.
+func (j *JWTAuth) IssueToken(claims Claims, expiresIn time.Duration) (string, error) {
+	if len(j.secret) == 0 {
+		return "", ErrUnexpectedSigningMethod
+	}
+
+	now := time.Now()
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(expiresIn))
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	if j.issuer != "" {
+		claims.Issuer = j.issuer
+	}
+	if j.audience != "" {
+		claims.Audience = jwt.ClaimStrings{j.audience}
 	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(j.secret)
 }
+
+// UserID returns the user identifier: the "sub" claim if set, the numeric identifier otherwise.
+func (c *Claims) UserID() string {
+	if c.Subject != "" {
+		return c.Subject
+	}
+	return strconv.Itoa(c.Identifier)
+}
@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/serializer"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testKey struct {
+	kid    string
+	method jwt.SigningMethod
+	signer crypto.Signer
+}
+
+func newRSAKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return testKey{kid: kid, method: jwt.SigningMethodRS256, signer: key}
+}
+
+func newECKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return testKey{kid: kid, method: jwt.SigningMethodES256, signer: key}
+}
+
+func jwksDocument(t *testing.T, keys ...testKey) string {
+	t.Helper()
+	var doc JWKSDocument
+	for _, k := range keys {
+		jwk, err := NewJWK(k.kid, k.signer.Public())
+		require.NoError(t, err)
+		doc.Keys = append(doc.Keys, jwk)
+	}
+	data, err := serializer.MarshalJSON(doc)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func signToken(t *testing.T, k testKey, claims Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.kid
+	signed, err := token.SignedString(k.signer)
+	require.NoError(t, err)
+	return signed
+}
+
+func testClaims(issuer, audience string) Claims {
+	claims := Claims{
+		Identifier: 42,
+		Role:       User,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			Issuer:    issuer,
+		},
+	}
+	if audience != "" {
+		claims.Audience = jwt.ClaimStrings{audience}
+	}
+	return claims
+}
+
+func TestJWTAuthAsymmetric(t *testing.T) {
+	rsaKey, ecKey := newRSAKey(t, "rsa-1"), newECKey(t, "ec-1")
+	jwks, err := NewJWKS(context.Background(), StaticJWKS(jwksDocument(t, rsaKey, ecKey)), 0)
+	require.NoError(t, err)
+
+	j := NewJWTAuth("secret", WithJWKS(jwks), WithIssuer("applingo"), WithAudience("api"))
+
+	for _, k := range []testKey{rsaKey, ecKey} {
+		claims, err := j.ValidateToken("Bearer " + signToken(t, k, testClaims("applingo", "api")))
+		require.NoError(t, err, k.kid)
+		assert.Equal(t, 42, claims.Identifier)
+	}
+
+	_, err = j.ValidateToken(signToken(t, rsaKey, testClaims("other", "api")))
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+
+	_, err = j.ValidateToken(signToken(t, rsaKey, testClaims("applingo", "web")))
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+
+	unknown := newRSAKey(t, "rsa-2")
+	_, err = j.ValidateToken(signToken(t, unknown, testClaims("applingo", "api")))
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// the kid of the EC key used with an RSA signature must not verify.
+	mismatched := rsaKey
+	mismatched.kid = ecKey.kid
+	_, err = j.ValidateToken(signToken(t, mismatched, testClaims("applingo", "api")))
+	assert.ErrorIs(t, err, ErrUnexpectedSigningMethod)
+
+	// algorithms outside of the accepted ones are rejected even with a matching key.
+	strong := rsaKey
+	strong.method = jwt.SigningMethodRS512
+	_, err = j.ValidateToken(signToken(t, strong, testClaims("applingo", "api")))
+	assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+}
+
+func TestJWTAuthKeyAlgorithm(t *testing.T) {
+	rsaKey := newRSAKey(t, "rsa-1")
+	jwk, err := NewJWK(rsaKey.kid, rsaKey.signer.Public())
+	require.NoError(t, err)
+	jwk.Alg = "PS256"
+	data, err := serializer.MarshalJSON(JWKSDocument{Keys: []JWK{jwk}})
+	require.NoError(t, err)
+	jwks, err := NewJWKS(context.Background(), StaticJWKS(string(data)), 0)
+	require.NoError(t, err)
+
+	// the key is published for PS256 only, an RS256 signature of it is not accepted.
+	_, err = NewJWTAuth("", WithJWKS(jwks)).ValidateToken(signToken(t, rsaKey, testClaims("", "")))
+	assert.ErrorIs(t, err, ErrUnexpectedSigningMethod)
+}
+
+func TestJWTAuthHMAC(t *testing.T) {
+	j := NewJWTAuth("secret")
+
+	token, err := j.GenerateToken(7, User, time.Hour)
+	require.NoError(t, err)
+	claims, err := j.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, 7, claims.Identifier)
+
+	_, err = j.ValidateToken(signToken(t, newRSAKey(t, "rsa-1"), testClaims("", "")))
+	assert.ErrorIs(t, err, ErrUnexpectedSigningMethod)
+
+	_, err = NewJWTAuth("").ValidateToken(token)
+	assert.ErrorIs(t, err, ErrUnexpectedSigningMethod)
+
+	token, err = jwt.NewWithClaims(jwt.SigningMethodHS512, testClaims("", "")).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = j.ValidateToken(token)
+	assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+}
+
+func TestJWKSRotation(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		oldKey  = newRSAKey(t, "2025-01")
+		newKey  = newECKey(t, "2025-02")
+		current = jwksDocument(t, oldKey)
+	)
+	loader := func(context.Context) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return []byte(current), nil
+	}
+	jwks, err := NewJWKS(context.Background(), loader, time.Hour)
+	require.NoError(t, err)
+	j := NewJWTAuth("", WithJWKS(jwks))
+
+	mu.Lock()
+	current = jwksDocument(t, oldKey, newKey)
+	mu.Unlock()
+
+	// unknown kids are not reloaded more often than jwksMinRefresh.
+	_, err = j.ValidateToken(signToken(t, newKey, testClaims("", "")))
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	jwks.checkedAt = time.Now().Add(-jwksMinRefresh - time.Second)
+	for _, k := range []testKey{oldKey, newKey} {
+		_, err = j.ValidateToken(signToken(t, k, testClaims("", "")))
+		assert.NoError(t, err, k.kid)
+	}
+
+	// a failed reload keeps the previous keys.
+	jwks.loader = func(context.Context) ([]byte, error) { return nil, errors.New("unavailable") }
+	jwks.checkedAt = time.Now().Add(-2 * time.Hour)
+	_, err = j.ValidateToken(signToken(t, newKey, testClaims("", "")))
+	assert.NoError(t, err)
+}
+
+func TestParseJWKS(t *testing.T) {
+	_, err := ParseJWKS([]byte(`{"keys":[]}`))
+	assert.Error(t, err)
+
+	_, err = ParseJWKS([]byte(`{"keys":[{"kty":"oct","kid":"k","k":"c2VjcmV0"}]}`))
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+
+	keys, err := ParseJWKS([]byte(`{"keys":[{"kty":"RSA","kid":"enc","use":"enc","n":"AQ","e":"AQAB"},` +
+		`{"kty":"EC","kid":"sig","crv":"P-256","x":"` + "MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4" +
+		`","y":"` + "4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM" + `"}]}`))
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+	require.Contains(t, keys, "sig")
+	assert.Equal(t, "ES256", keys["sig"].Alg)
+}
+
+func TestClaimsRoleAndScopes(t *testing.T) {
+	var claims Claims
+	require.NoError(t, serializer.UnmarshalJSON([]byte(`{"identifier":1,"role":"admin","scope":"dictionary:write  bogus dictionary:write"}`), &claims))
+	assert.Equal(t, Admin, claims.Role)
+	assert.Equal(t, []string{ScopeDictionaryWrite}, claims.Scopes())
+
+	require.NoError(t, serializer.UnmarshalJSON([]byte(`{"role":7}`), &claims))
+	assert.Equal(t, SuperAdmin, claims.Role)
+
+	assert.Error(t, serializer.UnmarshalJSON([]byte(`{"role":"root"}`), &claims))
+}
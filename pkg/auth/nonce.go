@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NonceStore records request nonces for a limited time to reject replayed requests.
+type NonceStore interface {
+	// Claim records the nonce for ttl, it returns ErrNonceReused if the nonce is already recorded.
+	Claim(ctx context.Context, nonce string, ttl time.Duration) error
+}
+
+// MemoryNonceStore is a NonceStore kept in process memory.
+// It only sees nonces handled by the same instance and fits local runs and tests.
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+var _ NonceStore = (*MemoryNonceStore)(nil)
+
+// NewMemoryNonceStore creates an empty in-memory nonce store.
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{nonces: make(map[string]time.Time)}
+}
+
+// Claim records the nonce for ttl, expired nonces are dropped on the way.
+func (s *MemoryNonceStore) Claim(_ context.Context, nonce string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for n, expires := range s.nonces {
+		if now.After(expires) {
+			delete(s.nonces, n)
+		}
+	}
+	if _, ok := s.nonces[nonce]; ok {
+		return ErrNonceReused
+	}
+	s.nonces[nonce] = now.Add(ttl)
+	return nil
+}
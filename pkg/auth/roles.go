@@ -1,5 +1,11 @@
 package auth
 
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
 // Role represents a user role in the system.
 type Role int
 
@@ -68,3 +74,42 @@ func GetPermissionLevel(role Role) int {
 	}
 	return RolePermissions[Guest]
 }
+
+// String returns the string representation of the Role.
+// If the Role is unknown, it returns "unknown".
+func (r Role) String() string {
+	if name, ok := RoleNames[r]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// RoleIsValid checks whether the provided Role is a known role.
+func RoleIsValid(r Role) bool {
+	_, ok := RoleNames[r]
+	return ok
+}
+
+// UnmarshalJSON decodes a Role from its numeric value or its name,
+// so tokens issued by external identity providers may carry "role": "admin".
+func (r *Role) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		name, err := strconv.Unquote(string(data))
+		if err != nil {
+			return errors.Wrap(err, "invalid role")
+		}
+		role, ok := ParseRole(name)
+		if !ok {
+			return errors.Errorf("unknown role '%s'", name)
+		}
+		*r = role
+		return nil
+	}
+
+	value, err := strconv.Atoi(string(data))
+	if err != nil {
+		return errors.Wrap(err, "invalid role")
+	}
+	*r = Role(value)
+	return nil
+}
@@ -0,0 +1,41 @@
+package auth
+
+import "strings"
+
+// Fine-grained scopes carried by user tokens in addition to the role.
+// Scopes follow the "<resource>:<action>" format, endpoints check them with api.MetaData.HasScope.
+const (
+	// ScopeDictionaryWrite lets a user modify any dictionary, not only the owned ones.
+	ScopeDictionaryWrite = "dictionary:write"
+	// ScopeDictionaryModerate is reserved for moderation of dictionaries and reports, no endpoint checks it yet.
+	ScopeDictionaryModerate = "dictionary:moderate"
+	// ScopeProcessingAdmin is reserved for the processing pipeline, no endpoint checks it yet.
+	ScopeProcessingAdmin = "processing:admin"
+)
+
+// ParseScopes splits a space-delimited scope string (RFC 8693) into unique scopes.
+// Values without the "<resource>:<action>" form are dropped.
+func ParseScopes(scope string) []string {
+	var (
+		fields = strings.Fields(scope)
+		seen   = make(map[string]struct{}, len(fields))
+		scopes = make([]string, 0, len(fields))
+	)
+	for _, s := range fields {
+		resource, action, ok := strings.Cut(s, ":")
+		if !ok || resource == "" || action == "" {
+			continue
+		}
+		if _, dup := seen[s]; dup {
+			continue
+		}
+		seen[s] = struct{}{}
+		scopes = append(scopes, s)
+	}
+	return scopes
+}
+
+// FormatScopes joins scopes into a space-delimited string.
+func FormatScopes(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
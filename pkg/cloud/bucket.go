@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"io"
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
@@ -56,6 +58,49 @@ const (
 	ContentTypeImage = "image/jpeg"
 )
 
+// UploadPolicy restricts the object accepted by a pre-signed upload URL.
+type UploadPolicy struct {
+	// ContentType is the content type the client must send.
+	ContentType string
+	// ContentLength is the exact size of the object in bytes, 0 leaves the size unrestricted.
+	ContentLength int64
+	// ChecksumSHA256 is the base64-encoded SHA-256 of the object, a body with another checksum is rejected.
+	ChecksumSHA256 string
+	// Expires is the URL lifetime, uploadTimeout is used if it is not set.
+	Expires time.Duration
+}
+
+// expires returns the URL lifetime of the policy.
+func (p UploadPolicy) expires() time.Duration {
+	if p.Expires <= 0 {
+		return uploadTimeout
+	}
+	return p.Expires
+}
+
+// BucketAPI describes the S3 operations used by the Lambdas.
+// It is implemented by Bucket, by the filesystem-backed LocalBucket and by the in-memory MemoryBucket.
+type BucketAPI interface {
+	UploadURL(ctx context.Context, key, bucket string, policy UploadPolicy) (string, error)
+	DownloadURL(ctx context.Context, key, bucket string) (string, error)
+	DownloadToWriter(ctx context.Context, key, bucket string, w io.Writer) error
+	Get(ctx context.Context, key, bucket string) (io.ReadCloser, error)
+	GetObjectBody(ctx context.Context, key, bucket string) (io.ReadCloser, error)
+	Read(ctx context.Context, w io.Writer, key, bucket string) error
+	Put(ctx context.Context, key, bucket string, body io.Reader, contentType string) error
+	Copy(ctx context.Context, sourceKey, sourceBucket, destKey, destBucket string) error
+	Delete(ctx context.Context, key, bucket string) error
+	Exists(ctx context.Context, key, bucket string) (bool, error)
+	WaitOrError(ctx context.Context, key, bucket string, maxAttempts int, delay time.Duration) error
+	GetRandomKey(ctx context.Context, bucket, prefix string) (string, error)
+}
+
+var (
+	_ BucketAPI = (*Bucket)(nil)
+	_ BucketAPI = (*LocalBucket)(nil)
+	_ BucketAPI = (*MemoryBucket)(nil)
+)
+
 // Bucket represents an S3 client for object operations.
 type Bucket struct {
 	client     *s3.Client
@@ -92,16 +137,27 @@ func validateInput(key, bucket string) error {
 }
 
 // UploadURL returns a pre-signed URL for uploading an object to the bucket.
-func (b *Bucket) UploadURL(ctx context.Context, key, bucket, contentType string) (string, error) {
+// The content length and the x-amz-checksum-sha256 checksum are signed headers the client sends
+// with the body, so S3 rejects a body which does not match the policy.
+func (b *Bucket) UploadURL(ctx context.Context, key, bucket string, policy UploadPolicy) (string, error) {
 	if err := validateInput(key, bucket); err != nil {
 		return "", err
 	}
 
-	req, err := s3.NewPresignClient(b.client).PresignPutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(bucket),
-		Key:         aws.String(key),
-		ContentType: aws.String(contentType),
-	}, s3.WithPresignExpires(uploadTimeout))
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if policy.ContentType != "" {
+		input.ContentType = aws.String(policy.ContentType)
+	}
+	if policy.ContentLength > 0 {
+		input.ContentLength = aws.Int64(policy.ContentLength)
+	}
+	if policy.ChecksumSHA256 != "" {
+		input.ChecksumSHA256 = aws.String(policy.ChecksumSHA256)
+	}
+	req, err := s3.NewPresignClient(b.client, withSignedHeaders).PresignPutObject(ctx, input, s3.WithPresignExpires(policy.expires()))
 	if err != nil {
 		return "", errors.Wrap(err, "failed to generate upload URL")
 	}
@@ -234,6 +290,11 @@ func (b *Bucket) WaitOrError(ctx context.Context, key, bucket string, maxAttempt
 		return err
 	}
 
+	return waitObject(ctx, b, key, bucket, maxAttempts, delay)
+}
+
+// waitObject polls the bucket until the object exists or the attempts are exhausted.
+func waitObject(ctx context.Context, b BucketAPI, key, bucket string, maxAttempts int, delay time.Duration) error {
 	for attempt := 0; attempt < maxAttempts; attempt++ {
 		exists, err := b.Exists(ctx, key, bucket)
 		if err != nil {
@@ -252,6 +313,34 @@ func (b *Bucket) WaitOrError(ctx context.Context, key, bucket string, maxAttempt
 	return fmt.Errorf("object %s was not found in bucket %s after %d attempts", key, bucket, maxAttempts)
 }
 
+// readObject copies the object content to the writer.
+func readObject(ctx context.Context, b BucketAPI, w io.Writer, key, bucket string) error {
+	body, err := b.Get(ctx, key, bucket)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if _, err = io.Copy(w, body); err != nil {
+		return errors.Wrap(err, "failed to copy content to writer")
+	}
+	return nil
+}
+
+// pickRandomKey returns a random key with the prefix from the sorted keys.
+func pickRandomKey(keys []string, prefix string) (string, error) {
+	var matched []string
+	for _, key := range keys {
+		if strings.HasPrefix(key, prefix) {
+			matched = append(matched, key)
+		}
+	}
+	if len(matched) == 0 {
+		return "", ErrBucketObjectNotFound
+	}
+	return matched[rand.Intn(len(matched))], nil
+}
+
 // Read reads file from bucket and writes content directly to the provided writer.
 func (b *Bucket) Read(ctx context.Context, w io.Writer, key, bucket string) error {
 	if err := validateInput(key, bucket); err != nil {
@@ -371,3 +460,12 @@ func (b *Bucket) Copy(ctx context.Context, sourceKey, sourceBucket, destKey, des
 	}
 	return nil
 }
+
+// withSignedHeaders keeps the x-amz-* headers of a pre-signed request as signed headers instead of
+// hoisting them into the query, so the client has to send them and S3 checks the body against them.
+func withSignedHeaders(o *s3.PresignOptions) {
+	o.Presigner = v4.NewSigner(func(so *v4.SignerOptions) {
+		so.DisableURIPathEscaping = true
+		so.DisableHeaderHoisting = true
+	})
+}
@@ -0,0 +1,287 @@
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// query parameters of upload URLs with the upload policy, the checksum is also expected as a header.
+const (
+	localContentLengthParam = "content-length"
+	localChecksumParam      = "x-amz-checksum-sha256"
+)
+
+// LocalBucket is a filesystem-backed S3 stand-in for local development.
+// Objects are stored as files under root/<bucket>/<key>, pre-signed URLs point to
+// baseURL and are served by LocalBucket itself as an http.Handler.
+type LocalBucket struct {
+	root    string
+	baseURL string
+}
+
+// NewLocalBucket creates a bucket stand-in storing objects under root.
+// baseURL is the address where the bucket handler is mounted, e.g. "http://localhost:8080/_bucket".
+func NewLocalBucket(root, baseURL string) *LocalBucket {
+	return &LocalBucket{
+		root:    root,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// UploadURL returns a URL accepting PUT requests with the object content.
+// The size and the checksum of the policy are kept in the query and checked like S3 does.
+func (b *LocalBucket) UploadURL(_ context.Context, key, bucket string, policy UploadPolicy) (string, error) {
+	if err := validateInput(key, bucket); err != nil {
+		return "", err
+	}
+	if _, err := b.path(key, bucket); err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	if policy.ContentLength > 0 {
+		query.Set(localContentLengthParam, strconv.FormatInt(policy.ContentLength, 10))
+	}
+	if policy.ChecksumSHA256 != "" {
+		query.Set(localChecksumParam, policy.ChecksumSHA256)
+	}
+	if len(query) == 0 {
+		return b.objectURL(key, bucket), nil
+	}
+	return b.objectURL(key, bucket) + "?" + query.Encode(), nil
+}
+
+// DownloadURL returns a URL serving the object content on GET requests.
+func (b *LocalBucket) DownloadURL(_ context.Context, key, bucket string) (string, error) {
+	if err := validateInput(key, bucket); err != nil {
+		return "", err
+	}
+	if _, err := b.path(key, bucket); err != nil {
+		return "", err
+	}
+	return b.objectURL(key, bucket), nil
+}
+
+// Get opens the object for reading.
+func (b *LocalBucket) Get(_ context.Context, key, bucket string) (io.ReadCloser, error) {
+	path, err := b.path(key, bucket)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrBucketObjectNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get object")
+	}
+	return f, nil
+}
+
+// Put writes the object content, replacing an existing object.
+func (b *LocalBucket) Put(_ context.Context, key, bucket string, body io.Reader, _ string) error {
+	path, err := b.path(key, bucket)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "failed to upload object")
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, body); err != nil {
+		return errors.Wrap(err, "failed to upload object")
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return errors.Wrap(err, "failed to upload object")
+	}
+	return nil
+}
+
+// Delete removes the object.
+func (b *LocalBucket) Delete(_ context.Context, key, bucket string) error {
+	path, err := b.path(key, bucket)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrap(err, "failed to delete object")
+	}
+	return nil
+}
+
+// Exists checks if the object exists.
+func (b *LocalBucket) Exists(_ context.Context, key, bucket string) (bool, error) {
+	path, err := b.path(key, bucket)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed to check object existence")
+	}
+	return !info.IsDir(), nil
+}
+
+// GetObjectBody opens the object for reading.
+func (b *LocalBucket) GetObjectBody(ctx context.Context, key, bucket string) (io.ReadCloser, error) {
+	return b.Get(ctx, key, bucket)
+}
+
+// Read writes the object content to the provided writer.
+func (b *LocalBucket) Read(ctx context.Context, w io.Writer, key, bucket string) error {
+	return readObject(ctx, b, w, key, bucket)
+}
+
+// DownloadToWriter writes the object content to the provided writer.
+func (b *LocalBucket) DownloadToWriter(ctx context.Context, key, bucket string, w io.Writer) error {
+	return readObject(ctx, b, w, key, bucket)
+}
+
+// Copy copies the object to another key or bucket.
+func (b *LocalBucket) Copy(ctx context.Context, sourceKey, sourceBucket, destKey, destBucket string) error {
+	body, err := b.Get(ctx, sourceKey, sourceBucket)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return b.Put(ctx, destKey, destBucket, body, ContentTypeJSON)
+}
+
+// WaitOrError checks that the object exists, retrying up to maxAttempts times.
+func (b *LocalBucket) WaitOrError(ctx context.Context, key, bucket string, maxAttempts int, delay time.Duration) error {
+	if err := validateInput(key, bucket); err != nil {
+		return err
+	}
+	return waitObject(ctx, b, key, bucket, maxAttempts, delay)
+}
+
+// GetRandomKey returns a random object key with the prefix.
+func (b *LocalBucket) GetRandomKey(_ context.Context, bucket, prefix string) (string, error) {
+	if bucket == "" {
+		return "", ErrBucketEmptyBucket
+	}
+	dir := filepath.Join(b.root, filepath.Base(bucket))
+
+	var keys []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", errors.Wrap(err, "failed to list objects")
+	}
+	return pickRandomKey(keys, prefix)
+}
+
+// ServeHTTP serves URLs returned by UploadURL and DownloadURL.
+// The request path must be "/<bucket>/<key>" relative to the mount point.
+func (b *LocalBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if !ok {
+		http.Error(w, ErrBucketEmptyKey.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		body, status, err := uploadBody(r)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		if err := b.Put(r.Context(), key, bucket, body, r.Header.Get("Content-Type")); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet, http.MethodHead:
+		body, err := b.Get(r.Context(), key, bucket)
+		if err != nil {
+			if errors.Is(err, ErrBucketObjectNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer body.Close()
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		if r.Method == http.MethodGet {
+			_, _ = io.Copy(w, body)
+		}
+	case http.MethodDelete:
+		if err := b.Delete(r.Context(), key, bucket); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// uploadBody reads the body of a PUT request and checks it against the policy kept in the query.
+func uploadBody(r *http.Request) (io.Reader, int, error) {
+	if length := r.URL.Query().Get(localContentLengthParam); length != "" && length != strconv.FormatInt(r.ContentLength, 10) {
+		return nil, http.StatusForbidden, errors.New("content length does not match the signed one")
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(err, "failed to read body")
+	}
+	if checksum := r.URL.Query().Get(localChecksumParam); checksum != "" {
+		// as on S3 the checksum is a signed header, the client sends it along with the body.
+		if r.Header.Get(localChecksumParam) != checksum {
+			return nil, http.StatusForbidden, errors.New("checksum header does not match the signed one")
+		}
+		sum := sha256.Sum256(data)
+		if base64.StdEncoding.EncodeToString(sum[:]) != checksum {
+			return nil, http.StatusBadRequest, errors.New("body does not match the checksum")
+		}
+	}
+	return bytes.NewReader(data), http.StatusOK, nil
+}
+
+// path resolves the object file path and rejects keys escaping the bucket directory.
+func (b *LocalBucket) path(key, bucket string) (string, error) {
+	if err := validateInput(key, bucket); err != nil {
+		return "", err
+	}
+	dir := filepath.Join(b.root, filepath.Base(bucket))
+	path := filepath.Join(dir, filepath.FromSlash(key))
+	if rel, err := filepath.Rel(dir, path); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", errors.Errorf("invalid object key '%s'", key)
+	}
+	return path, nil
+}
+
+func (b *LocalBucket) objectURL(key, bucket string) string {
+	return b.baseURL + "/" + url.PathEscape(bucket) + "/" + (&url.URL{Path: key}).EscapedPath()
+}
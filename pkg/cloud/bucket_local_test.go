@@ -0,0 +1,61 @@
+package cloud
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalBucketUploadPolicy(t *testing.T) {
+	ctx := context.Background()
+	b := NewLocalBucket(t.TempDir(), "")
+	server := httptest.NewServer(b)
+	defer server.Close()
+	b.baseURL = server.URL
+
+	body := `{"words":[]}`
+	sum := sha256.Sum256([]byte(body))
+	uploadURL, err := b.UploadURL(ctx, "uploads/owner/file.json", "processing", UploadPolicy{
+		ContentType:    ContentTypeJSON,
+		ContentLength:  int64(len(body)),
+		ChecksumSHA256: base64.StdEncoding.EncodeToString(sum[:]),
+	})
+	require.NoError(t, err)
+
+	checksum := base64.StdEncoding.EncodeToString(sum[:])
+	tests := []struct {
+		name     string
+		body     string
+		checksum string
+		status   int
+	}{
+		{"other size", body + " ", checksum, http.StatusForbidden},
+		{"missing checksum", body, "", http.StatusForbidden},
+		{"other content", strings.Replace(body, "[]", "{}", 1), checksum, http.StatusBadRequest},
+		{"matching", body, checksum, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, strings.NewReader(tt.body))
+			require.NoError(t, err)
+			if tt.checksum != "" {
+				req.Header.Set("x-amz-checksum-sha256", tt.checksum)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tt.status, resp.StatusCode)
+
+			exists, err := b.Exists(ctx, "uploads/owner/file.json", "processing")
+			require.NoError(t, err)
+			assert.Equal(t, tt.status == http.StatusOK, exists)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MemoryBucket is an in-memory S3 stand-in for tests.
+// Pre-signed URLs use the "memory://<bucket>/<key>" form and are not served.
+type MemoryBucket struct {
+	mu      sync.RWMutex
+	objects map[string]map[string]memoryObject
+}
+
+type memoryObject struct {
+	data        []byte
+	contentType string
+}
+
+// NewMemoryBucket creates an empty in-memory object storage.
+func NewMemoryBucket() *MemoryBucket {
+	return &MemoryBucket{objects: make(map[string]map[string]memoryObject)}
+}
+
+// UploadURL returns a URL identifying the object, it cannot be used for uploading.
+func (b *MemoryBucket) UploadURL(_ context.Context, key, bucket string, _ UploadPolicy) (string, error) {
+	if err := validateInput(key, bucket); err != nil {
+		return "", err
+	}
+	return memoryObjectURL(key, bucket), nil
+}
+
+// DownloadURL returns a URL identifying the object, it cannot be used for downloading.
+func (b *MemoryBucket) DownloadURL(_ context.Context, key, bucket string) (string, error) {
+	if err := validateInput(key, bucket); err != nil {
+		return "", err
+	}
+	return memoryObjectURL(key, bucket), nil
+}
+
+// Get returns the object content.
+func (b *MemoryBucket) Get(_ context.Context, key, bucket string) (io.ReadCloser, error) {
+	obj, err := b.object(key, bucket)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(obj.data)), nil
+}
+
+// GetObjectBody returns the object content.
+func (b *MemoryBucket) GetObjectBody(ctx context.Context, key, bucket string) (io.ReadCloser, error) {
+	return b.Get(ctx, key, bucket)
+}
+
+// Read writes the object content to the provided writer.
+func (b *MemoryBucket) Read(ctx context.Context, w io.Writer, key, bucket string) error {
+	return readObject(ctx, b, w, key, bucket)
+}
+
+// DownloadToWriter writes the object content to the provided writer.
+func (b *MemoryBucket) DownloadToWriter(ctx context.Context, key, bucket string, w io.Writer) error {
+	return readObject(ctx, b, w, key, bucket)
+}
+
+// Put stores the object content, replacing an existing object.
+func (b *MemoryBucket) Put(_ context.Context, key, bucket string, body io.Reader, contentType string) error {
+	if err := validateInput(key, bucket); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return errors.Wrap(err, "failed to upload object")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.put(key, bucket, memoryObject{data: data, contentType: contentType})
+	return nil
+}
+
+// Copy copies the object to another key or bucket.
+func (b *MemoryBucket) Copy(_ context.Context, sourceKey, sourceBucket, destKey, destBucket string) error {
+	if err := validateInput(destKey, destBucket); err != nil {
+		return err
+	}
+	obj, err := b.object(sourceKey, sourceBucket)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.put(destKey, destBucket, obj)
+	return nil
+}
+
+// Delete removes the object, deleting a missing object is not an error.
+func (b *MemoryBucket) Delete(_ context.Context, key, bucket string) error {
+	if err := validateInput(key, bucket); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.objects[bucket], key)
+	return nil
+}
+
+// Exists checks if the object exists.
+func (b *MemoryBucket) Exists(_ context.Context, key, bucket string) (bool, error) {
+	if err := validateInput(key, bucket); err != nil {
+		return false, err
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.objects[bucket][key]
+	return ok, nil
+}
+
+// WaitOrError checks that the object exists, retrying up to maxAttempts times.
+func (b *MemoryBucket) WaitOrError(ctx context.Context, key, bucket string, maxAttempts int, delay time.Duration) error {
+	if err := validateInput(key, bucket); err != nil {
+		return err
+	}
+	return waitObject(ctx, b, key, bucket, maxAttempts, delay)
+}
+
+// GetRandomKey returns a random object key with the prefix.
+func (b *MemoryBucket) GetRandomKey(_ context.Context, bucket, prefix string) (string, error) {
+	if bucket == "" {
+		return "", ErrBucketEmptyBucket
+	}
+
+	b.mu.RLock()
+	keys := make([]string, 0, len(b.objects[bucket]))
+	for key := range b.objects[bucket] {
+		keys = append(keys, key)
+	}
+	b.mu.RUnlock()
+
+	sort.Strings(keys)
+	return pickRandomKey(keys, prefix)
+}
+
+// object returns a copy of the stored object.
+func (b *MemoryBucket) object(key, bucket string) (memoryObject, error) {
+	if err := validateInput(key, bucket); err != nil {
+		return memoryObject{}, err
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	obj, ok := b.objects[bucket][key]
+	if !ok {
+		return memoryObject{}, ErrBucketObjectNotFound
+	}
+	return memoryObject{data: bytes.Clone(obj.data), contentType: obj.contentType}, nil
+}
+
+// put stores the object, caller must hold the lock.
+func (b *MemoryBucket) put(key, bucket string, obj memoryObject) {
+	if b.objects[bucket] == nil {
+		b.objects[bucket] = make(map[string]memoryObject)
+	}
+	b.objects[bucket][key] = obj
+}
+
+func memoryObjectURL(key, bucket string) string {
+	return "memory://" + url.PathEscape(bucket) + "/" + (&url.URL{Path: key}).EscapedPath()
+}
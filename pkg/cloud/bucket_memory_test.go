@@ -0,0 +1,42 @@
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBucket(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemoryBucket()
+
+	require.NoError(t, b.Put(ctx, "prompts/check.txt", "forge", strings.NewReader("prompt"), ContentTypeText))
+	require.NoError(t, b.Copy(ctx, "prompts/check.txt", "forge", "copy.txt", "processing"))
+
+	var buf bytes.Buffer
+	require.NoError(t, b.Read(ctx, &buf, "copy.txt", "processing"))
+	assert.Equal(t, "prompt", buf.String())
+
+	key, err := b.GetRandomKey(ctx, "forge", "prompts/")
+	require.NoError(t, err)
+	assert.Equal(t, "prompts/check.txt", key)
+
+	_, err = b.GetRandomKey(ctx, "forge", "missing/")
+	assert.ErrorIs(t, err, ErrBucketObjectNotFound)
+
+	require.NoError(t, b.WaitOrError(ctx, "copy.txt", "processing", 1, time.Millisecond))
+	require.NoError(t, b.Delete(ctx, "copy.txt", "processing"))
+	assert.Error(t, b.WaitOrError(ctx, "copy.txt", "processing", 2, time.Millisecond))
+
+	_, err = b.Get(ctx, "copy.txt", "processing")
+	assert.ErrorIs(t, err, ErrBucketObjectNotFound)
+
+	url, err := b.UploadURL(ctx, "dir/file name.json", "processing", UploadPolicy{ContentType: ContentTypeJSON})
+	require.NoError(t, err)
+	assert.Equal(t, "memory://processing/dir/file%20name.json", url)
+}
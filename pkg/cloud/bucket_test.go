@@ -0,0 +1,44 @@
+package cloud
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketUploadURL(t *testing.T) {
+	b := NewBucket(aws.Config{
+		Region: "eu-central-1",
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "key", SecretAccessKey: "secret"}, nil
+		}),
+	})
+
+	raw, err := b.UploadURL(context.Background(), "uploads/owner/file.json", "processing", UploadPolicy{
+		ContentType:    ContentTypeJSON,
+		ContentLength:  128,
+		ChecksumSHA256: "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=",
+		Expires:        time.Minute,
+	})
+	require.NoError(t, err)
+
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	query := u.Query()
+	// the checksum is a signed header, so S3 rejects an upload without it or with another body.
+	assert.Equal(t, "content-length;content-type;host;x-amz-checksum-sha256", query.Get("X-Amz-SignedHeaders"))
+	assert.Empty(t, query.Get("X-Amz-Checksum-Sha256"))
+	assert.Equal(t, "60", query.Get("X-Amz-Expires"))
+
+	raw, err = b.UploadURL(context.Background(), "file.json", "processing", UploadPolicy{})
+	require.NoError(t, err)
+	u, err = url.Parse(raw)
+	require.NoError(t, err)
+	assert.Equal(t, "300", u.Query().Get("X-Amz-Expires"))
+	assert.Equal(t, "host", u.Query().Get("X-Amz-SignedHeaders"))
+}
@@ -12,6 +12,9 @@ import (
 	"github.com/pkg/errors"
 )
 
+// MaxTransactItems is the number of writes DynamoDB accepts in one transaction.
+const MaxTransactItems = 100
+
 // Common errors
 var (
 	ErrDynamoEmptyTable = errors.New("empty table name")
@@ -29,6 +32,42 @@ type QueryInput struct {
 	ExclusiveStartKey map[string]types.AttributeValue
 }
 
+// DynamoAPI describes the DynamoDB operations used by the Lambdas.
+// It is implemented by Dynamo and by the in-memory MemoryDynamo.
+type DynamoAPI interface {
+	BuildQueryInput(input QueryInput) (*dynamodb.QueryInput, error)
+	BuildScanInput(table string, limit int32, exclusiveStartKey map[string]types.AttributeValue) *dynamodb.ScanInput
+	Put(ctx context.Context, table string, item map[string]types.AttributeValue, condition expression.ConditionBuilder) error
+	BatchWrite(ctx context.Context, table string, items []map[string]types.AttributeValue) error
+	Get(ctx context.Context, table string, key map[string]types.AttributeValue) (*dynamodb.GetItemOutput, error)
+	BatchGet(ctx context.Context, table string, keys []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error)
+	Query(ctx context.Context, table string, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error)
+	Delete(ctx context.Context, table string, key map[string]types.AttributeValue) error
+	Update(ctx context.Context, table string, key map[string]types.AttributeValue, update expression.UpdateBuilder, condition expression.ConditionBuilder) error
+	Scan(ctx context.Context, table string, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error)
+	GetRandomItem(ctx context.Context, table string) (map[string]types.AttributeValue, error)
+	GetRandomField(ctx context.Context, table, fieldName string) (string, error)
+	Exists(ctx context.Context, table string, key map[string]types.AttributeValue) (bool, error)
+	TransactWrite(ctx context.Context, items []TransactWriteItem) error
+}
+
+// TransactWriteItem is a single write of a transaction.
+// Item is put as a whole, otherwise Update is applied to the item at Key or, with Delete set, the item is removed.
+// A write with Condition only checks the item at Key without changing it.
+type TransactWriteItem struct {
+	Table     string
+	Item      map[string]types.AttributeValue
+	Key       map[string]types.AttributeValue
+	Update    *expression.UpdateBuilder
+	Delete    bool
+	Condition expression.ConditionBuilder
+}
+
+var (
+	_ DynamoAPI = (*Dynamo)(nil)
+	_ DynamoAPI = (*MemoryDynamo)(nil)
+)
+
 // Dynamo represents a DynamoDB client for database operations.
 type Dynamo struct {
 	client *dynamodb.Client
@@ -57,8 +96,76 @@ func validateKey(key map[string]types.AttributeValue) error {
 	return nil
 }
 
+// build converts the write to the DynamoDB transaction item.
+func (w TransactWriteItem) build() (types.TransactWriteItem, error) {
+	if err := validateTable(w.Table); err != nil {
+		return types.TransactWriteItem{}, err
+	}
+	if w.Item == nil {
+		if err := validateKey(w.Key); err != nil {
+			return types.TransactWriteItem{}, err
+		}
+	}
+
+	var expr expression.Expression
+	if w.Update != nil || w.Condition.IsSet() {
+		builder := expression.NewBuilder()
+		if w.Update != nil {
+			builder = builder.WithUpdate(*w.Update)
+		}
+		if w.Condition.IsSet() {
+			builder = builder.WithCondition(w.Condition)
+		}
+		var err error
+		if expr, err = builder.Build(); err != nil {
+			return types.TransactWriteItem{}, errors.Wrap(err, "failed to build transaction expression")
+		}
+	}
+
+	switch {
+	case w.Item != nil:
+		return types.TransactWriteItem{Put: &types.Put{
+			TableName:                 aws.String(w.Table),
+			Item:                      w.Item,
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		}}, nil
+	case w.Update != nil:
+		return types.TransactWriteItem{Update: &types.Update{
+			TableName:                 aws.String(w.Table),
+			Key:                       w.Key,
+			UpdateExpression:          expr.Update(),
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		}}, nil
+	case w.Delete:
+		return types.TransactWriteItem{Delete: &types.Delete{
+			TableName:                 aws.String(w.Table),
+			Key:                       w.Key,
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		}}, nil
+	case w.Condition.IsSet():
+		return types.TransactWriteItem{ConditionCheck: &types.ConditionCheck{
+			TableName:                 aws.String(w.Table),
+			Key:                       w.Key,
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		}}, nil
+	}
+	return types.TransactWriteItem{}, errors.New("transaction write has no operation")
+}
+
 // BuildQueryInput creates a dynamodb.QueryInput based on the provided QueryInput.
 func (d *Dynamo) BuildQueryInput(input QueryInput) (*dynamodb.QueryInput, error) {
+	return buildQueryInput(input)
+}
+
+func buildQueryInput(input QueryInput) (*dynamodb.QueryInput, error) {
 	builder := expression.NewBuilder().WithKeyCondition(input.KeyCondition)
 
 	if input.FilterCondition.IsSet() {
@@ -77,7 +184,6 @@ func (d *Dynamo) BuildQueryInput(input QueryInput) (*dynamodb.QueryInput, error)
 	}
 
 	queryInput := &dynamodb.QueryInput{
-		IndexName:                 &input.IndexName,
 		KeyConditionExpression:    expr.KeyCondition(),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
@@ -85,6 +191,9 @@ func (d *Dynamo) BuildQueryInput(input QueryInput) (*dynamodb.QueryInput, error)
 		ScanIndexForward:          &input.ScanForward,
 		ExclusiveStartKey:         input.ExclusiveStartKey,
 	}
+	if input.IndexName != "" {
+		queryInput.IndexName = &input.IndexName
+	}
 	if expr.Filter() != nil {
 		queryInput.FilterExpression = expr.Filter()
 	}
@@ -96,6 +205,10 @@ func (d *Dynamo) BuildQueryInput(input QueryInput) (*dynamodb.QueryInput, error)
 
 // BuildScanInput creates a dynamodb.ScanInput based on the provided fields and conditions.
 func (d *Dynamo) BuildScanInput(table string, limit int32, exclusiveStartKey map[string]types.AttributeValue) *dynamodb.ScanInput {
+	return buildScanInput(table, limit, exclusiveStartKey)
+}
+
+func buildScanInput(table string, limit int32, exclusiveStartKey map[string]types.AttributeValue) *dynamodb.ScanInput {
 	input := &dynamodb.ScanInput{
 		TableName:         aws.String(table),
 		Limit:             &limit,
@@ -188,6 +301,44 @@ func (d *Dynamo) Get(ctx context.Context, table string, key map[string]types.Att
 	return result, nil
 }
 
+// BatchGet retrieves the items at the keys, missing items are left out of the result.
+// The items are returned in no particular order.
+func (d *Dynamo) BatchGet(ctx context.Context, table string, keys []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	if err := validateTable(table); err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if err := validateKey(key); err != nil {
+			return nil, err
+		}
+	}
+	var (
+		batchSize   = 100
+		maxAttempts = 5
+		items       = make([]map[string]types.AttributeValue, 0, len(keys))
+	)
+	for start := 0; start < len(keys); start += batchSize {
+		pending := keys[start:min(start+batchSize, len(keys))]
+
+		for attempt := 0; len(pending) > 0; attempt++ {
+			if attempt == maxAttempts {
+				return nil, errors.New("some keys were not processed in batch get")
+			}
+			resp, err := d.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: map[string]types.KeysAndAttributes{
+					table: {Keys: pending},
+				},
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to batch get items")
+			}
+			items = append(items, resp.Responses[table]...)
+			pending = resp.UnprocessedKeys[table].Keys
+		}
+	}
+	return items, nil
+}
+
 // Query executes a query operation on DynamoDB table.
 func (d *Dynamo) Query(ctx context.Context, table string, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
 	if err := validateTable(table); err != nil {
@@ -256,6 +407,45 @@ func (d *Dynamo) Update(ctx context.Context, table string, key map[string]types.
 	return nil
 }
 
+// TransactWrite applies the writes atomically, none of them is applied if any fails.
+// A failed condition of any write is returned as ConditionalCheckFailedException.
+func (d *Dynamo) TransactWrite(ctx context.Context, items []TransactWriteItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	if len(items) > MaxTransactItems {
+		return fmt.Errorf("transaction cannot have more than %d writes, got %d", MaxTransactItems, len(items))
+	}
+	writes := make([]types.TransactWriteItem, 0, len(items))
+	for _, item := range items {
+		write, err := item.build()
+		if err != nil {
+			return err
+		}
+		writes = append(writes, write)
+	}
+
+	_, err := d.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: writes})
+	if err != nil {
+		var canceled *types.TransactionCanceledException
+		if errors.As(err, &canceled) && conditionCanceled(canceled) {
+			return errors.Wrap(&types.ConditionalCheckFailedException{Message: canceled.Message}, "failed to write transaction")
+		}
+		return errors.Wrap(err, "failed to write transaction")
+	}
+	return nil
+}
+
+// conditionCanceled reports whether the transaction was canceled by a failed condition.
+func conditionCanceled(err *types.TransactionCanceledException) bool {
+	for _, reason := range err.CancellationReasons {
+		if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
+
 // Scan executes a scan operation on DynamoDB table.
 func (d *Dynamo) Scan(ctx context.Context, table string, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 	if err := validateTable(table); err != nil {
@@ -325,7 +515,11 @@ func (d *Dynamo) GetRandomField(ctx context.Context, table, fieldName string) (s
 	if err != nil {
 		return "", err
 	}
+	return stringField(item, fieldName)
+}
 
+// stringField returns the string value of the item field.
+func stringField(item map[string]types.AttributeValue, fieldName string) (string, error) {
 	if val, ok := item[fieldName]; ok {
 		if sv, ok := val.(*types.AttributeValueMemberS); ok {
 			return sv.Value, nil
@@ -0,0 +1,693 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+)
+
+// MemoryTable describes the key schema of a table served by MemoryDynamo.
+type MemoryTable struct {
+	Name     string
+	HashKey  string
+	RangeKey string
+	Indexes  []MemoryIndex
+}
+
+// MemoryIndex describes a global secondary index of a MemoryTable.
+type MemoryIndex struct {
+	Name             string
+	HashKey          string
+	RangeKey         string
+	ProjectionType   string // ALL, KEYS_ONLY or INCLUDE
+	NonKeyAttributes []string
+}
+
+// NewMemoryTable returns the in-memory table of a TableSchema generated by dynamodb-interface with all its indexes.
+// Every generated package declares its own schema types, so the fields are read by name.
+// It panics if schema is not a generated TableSchema.
+func NewMemoryTable(schema any) MemoryTable {
+	v := reflect.Indirect(reflect.ValueOf(schema))
+	table := MemoryTable{
+		Name:     v.FieldByName("TableName").String(),
+		HashKey:  v.FieldByName("HashKey").String(),
+		RangeKey: v.FieldByName("RangeKey").String(),
+	}
+	indexes := v.FieldByName("SecondaryIndexes")
+	for i := range indexes.Len() {
+		idx := indexes.Index(i)
+		table.Indexes = append(table.Indexes, MemoryIndex{
+			Name:             idx.FieldByName("Name").String(),
+			HashKey:          idx.FieldByName("HashKey").String(),
+			RangeKey:         idx.FieldByName("RangeKey").String(),
+			ProjectionType:   idx.FieldByName("ProjectionType").String(),
+			NonKeyAttributes: idx.FieldByName("NonKeyAttributes").Interface().([]string),
+		})
+	}
+	return table
+}
+
+// MemoryDynamo is an in-memory DynamoDB stand-in for local development and tests.
+// It evaluates condition, filter, key condition, projection and update expressions
+// and serves queries on secondary indexes declared in the table definitions.
+type MemoryDynamo struct {
+	mu     sync.RWMutex
+	tables map[string]*memoryTable
+}
+
+type memoryTable struct {
+	def   MemoryTable
+	items map[string]map[string]types.AttributeValue
+}
+
+// NewMemoryDynamo creates an empty in-memory database with the given tables.
+func NewMemoryDynamo(tables ...MemoryTable) *MemoryDynamo {
+	m := &MemoryDynamo{tables: make(map[string]*memoryTable, len(tables))}
+	for _, t := range tables {
+		m.tables[t.Name] = &memoryTable{
+			def:   t,
+			items: make(map[string]map[string]types.AttributeValue),
+		}
+	}
+	return m
+}
+
+// BuildQueryInput creates a dynamodb.QueryInput based on the provided QueryInput.
+func (m *MemoryDynamo) BuildQueryInput(input QueryInput) (*dynamodb.QueryInput, error) {
+	return buildQueryInput(input)
+}
+
+// Put adds or replaces an item if the condition is satisfied.
+func (m *MemoryDynamo) Put(_ context.Context, table string, item map[string]types.AttributeValue, condition expression.ConditionBuilder) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, err := m.table(table)
+	if err != nil {
+		return err
+	}
+	key, err := t.itemKey(item)
+	if err != nil {
+		return errors.Wrap(err, "failed to put item")
+	}
+	if err := checkCondition(t.items[key], condition); err != nil {
+		return errors.Wrap(err, "failed to put item")
+	}
+	t.items[key] = copyItem(item)
+	return nil
+}
+
+// Get retrieves an item by its primary key.
+func (m *MemoryDynamo) Get(_ context.Context, table string, key map[string]types.AttributeValue) (*dynamodb.GetItemOutput, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, err := m.table(table)
+	if err != nil {
+		return nil, err
+	}
+	k, err := t.itemKey(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get item")
+	}
+	return &dynamodb.GetItemOutput{Item: copyItem(t.items[k])}, nil
+}
+
+// BatchGet retrieves the items at the keys, missing items are left out of the result.
+func (m *MemoryDynamo) BatchGet(_ context.Context, table string, keys []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, err := m.table(table)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]map[string]types.AttributeValue, 0, len(keys))
+	for _, key := range keys {
+		k, err := t.itemKey(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to batch get items")
+		}
+		if item, ok := t.items[k]; ok {
+			items = append(items, copyItem(item))
+		}
+	}
+	return items, nil
+}
+
+// Delete removes an item by its primary key.
+func (m *MemoryDynamo) Delete(_ context.Context, table string, key map[string]types.AttributeValue) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, err := m.table(table)
+	if err != nil {
+		return err
+	}
+	k, err := t.itemKey(key)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete item")
+	}
+	delete(t.items, k)
+	return nil
+}
+
+// Update applies the update expression to the item, creating it if it does not exist.
+func (m *MemoryDynamo) Update(_ context.Context, table string, key map[string]types.AttributeValue, update expression.UpdateBuilder, condition expression.ConditionBuilder) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	actions, err := updateActions(update)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, err := m.table(table)
+	if err != nil {
+		return err
+	}
+	k, item, err := t.updated(key, actions, condition)
+	if err != nil {
+		return errors.Wrap(err, "failed to update item")
+	}
+	t.items[k] = item
+	return nil
+}
+
+// TransactWrite applies the writes atomically, none of them is applied if any fails.
+// As in DynamoDB, an item may be written only once per transaction and a transaction
+// has at most MaxTransactItems writes.
+func (m *MemoryDynamo) TransactWrite(_ context.Context, items []TransactWriteItem) error {
+	if len(items) > MaxTransactItems {
+		return fmt.Errorf("transaction cannot have more than %d writes, got %d", MaxTransactItems, len(items))
+	}
+	type write struct {
+		t    *memoryTable
+		key  string
+		item map[string]types.AttributeValue // nil removes the item
+		skip bool                            // condition check only
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var (
+		writes = make([]write, 0, len(items))
+		seen   = make(map[string]bool, len(items))
+	)
+	for _, w := range items {
+		t, err := m.table(w.Table)
+		if err != nil {
+			return err
+		}
+		var next write
+		switch {
+		case w.Item != nil:
+			k, err := t.itemKey(w.Item)
+			if err != nil {
+				return errors.Wrap(err, "failed to write transaction")
+			}
+			if err := checkCondition(t.items[k], w.Condition); err != nil {
+				return errors.Wrap(err, "failed to write transaction")
+			}
+			next = write{t: t, key: k, item: copyItem(w.Item)}
+		case w.Update != nil:
+			if err := validateKey(w.Key); err != nil {
+				return err
+			}
+			actions, err := updateActions(*w.Update)
+			if err != nil {
+				return err
+			}
+			k, item, err := t.updated(w.Key, actions, w.Condition)
+			if err != nil {
+				return errors.Wrap(err, "failed to write transaction")
+			}
+			next = write{t: t, key: k, item: item}
+		case w.Delete, w.Condition.IsSet():
+			if err := validateKey(w.Key); err != nil {
+				return err
+			}
+			k, err := t.itemKey(w.Key)
+			if err != nil {
+				return errors.Wrap(err, "failed to write transaction")
+			}
+			if err := checkCondition(t.items[k], w.Condition); err != nil {
+				return errors.Wrap(err, "failed to write transaction")
+			}
+			next = write{t: t, key: k, skip: !w.Delete}
+		default:
+			return errors.New("transaction write has no operation")
+		}
+
+		id := w.Table + "/" + next.key
+		if seen[id] {
+			return errors.Errorf("failed to write transaction: item '%s' is written more than once", next.key)
+		}
+		seen[id] = true
+		writes = append(writes, next)
+	}
+
+	for _, w := range writes {
+		switch {
+		case w.skip:
+		case w.item == nil:
+			delete(w.t.items, w.key)
+		default:
+			w.t.items[w.key] = w.item
+		}
+	}
+	return nil
+}
+
+// updateActions parses the update expression into actions applied by applyUpdate.
+func updateActions(update expression.UpdateBuilder) ([]updateAction, error) {
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build update expression")
+	}
+	return parseUpdate(aws.ToString(expr.Update()), expr.Names(), expr.Values())
+}
+
+// updated returns the storage key and the item after the update, the table is left unchanged.
+func (t *memoryTable) updated(key map[string]types.AttributeValue, actions []updateAction, condition expression.ConditionBuilder) (string, map[string]types.AttributeValue, error) {
+	k, err := t.itemKey(key)
+	if err != nil {
+		return "", nil, err
+	}
+	current := t.items[k]
+	if err := checkCondition(current, condition); err != nil {
+		return "", nil, err
+	}
+
+	item := copyItem(current)
+	if item == nil {
+		item = copyItem(key)
+	}
+	if err := applyUpdate(item, actions); err != nil {
+		return "", nil, err
+	}
+	for _, attr := range []string{t.def.HashKey, t.def.RangeKey} {
+		if attr == "" {
+			continue
+		}
+		if v, ok := item[attr]; !ok || !attrEqual(v, key[attr]) {
+			return "", nil, errors.Errorf("cannot update key attribute '%s'", attr)
+		}
+	}
+	return k, item, nil
+}
+
+// Query returns items matching the key condition from the table or one of its indexes.
+func (m *MemoryDynamo) Query(_ context.Context, table string, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, err := m.table(table)
+	if err != nil {
+		return nil, err
+	}
+	hashKey, rangeKey, index, err := t.keysFor(aws.ToString(input.IndexName))
+	if err != nil {
+		return nil, err
+	}
+	keyCond, err := parseCondition(aws.ToString(input.KeyConditionExpression), input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute query")
+	}
+
+	var items []map[string]types.AttributeValue
+	for _, item := range t.items {
+		if _, ok := item[hashKey]; !ok {
+			continue
+		}
+		if rangeKey != "" {
+			if _, ok := item[rangeKey]; !ok {
+				continue
+			}
+		}
+		if keyCond.eval(item) {
+			items = append(items, item)
+		}
+	}
+	t.sortItems(items, rangeKey)
+	if input.ScanIndexForward != nil && !*input.ScanIndexForward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	page, err := t.page(items, index, input.ExclusiveStartKey, aws.ToInt32(input.Limit), input.FilterExpression, input.ProjectionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute query")
+	}
+	out := &dynamodb.QueryOutput{
+		Count:            page.count,
+		ScannedCount:     page.scanned,
+		LastEvaluatedKey: page.lastKey,
+	}
+	if input.Select != types.SelectCount {
+		out.Items = page.items
+	}
+	return out, nil
+}
+
+// BuildScanInput creates a dynamodb.ScanInput based on the provided fields and conditions.
+func (m *MemoryDynamo) BuildScanInput(table string, limit int32, exclusiveStartKey map[string]types.AttributeValue) *dynamodb.ScanInput {
+	return buildScanInput(table, limit, exclusiveStartKey)
+}
+
+// BatchWrite adds or replaces multiple items, no item is written if any of them has an invalid key.
+func (m *MemoryDynamo) BatchWrite(_ context.Context, table string, items []map[string]types.AttributeValue) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, err := m.table(table)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, len(items))
+	for i, item := range items {
+		if keys[i], err = t.itemKey(item); err != nil {
+			return errors.Wrap(err, "failed to batch write items")
+		}
+	}
+	for i, item := range items {
+		t.items[keys[i]] = copyItem(item)
+	}
+	return nil
+}
+
+// Scan returns table or index items ordered by the primary key.
+func (m *MemoryDynamo) Scan(_ context.Context, table string, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, err := m.table(table)
+	if err != nil {
+		return nil, err
+	}
+	hashKey, rangeKey, index, err := t.keysFor(aws.ToString(input.IndexName))
+	if err != nil {
+		return nil, err
+	}
+
+	var items []map[string]types.AttributeValue
+	for _, item := range t.items {
+		if _, ok := item[hashKey]; !ok {
+			continue
+		}
+		if rangeKey != "" {
+			if _, ok := item[rangeKey]; !ok {
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+	t.sortItems(items, "")
+
+	page, err := t.page(items, index, input.ExclusiveStartKey, aws.ToInt32(input.Limit), input.FilterExpression, input.ProjectionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute scan")
+	}
+	out := &dynamodb.ScanOutput{
+		Count:            page.count,
+		ScannedCount:     page.scanned,
+		LastEvaluatedKey: page.lastKey,
+	}
+	if input.Select != types.SelectCount {
+		out.Items = page.items
+	}
+	return out, nil
+}
+
+// GetRandomItem retrieves a random item from the table.
+func (m *MemoryDynamo) GetRandomItem(_ context.Context, table string) (map[string]types.AttributeValue, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, err := m.table(table)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.items) == 0 {
+		return nil, errors.New("table is empty")
+	}
+	items := make([]map[string]types.AttributeValue, 0, len(t.items))
+	for _, item := range t.items {
+		items = append(items, item)
+	}
+	t.sortItems(items, "")
+	return copyItem(items[rand.Intn(len(items))]), nil
+}
+
+// GetRandomField retrieves a random item from the table and returns specific field value.
+func (m *MemoryDynamo) GetRandomField(ctx context.Context, table, fieldName string) (string, error) {
+	item, err := m.GetRandomItem(ctx, table)
+	if err != nil {
+		return "", err
+	}
+	return stringField(item, fieldName)
+}
+
+// Exists checks if an item with the specified key exists in the table.
+func (m *MemoryDynamo) Exists(ctx context.Context, table string, key map[string]types.AttributeValue) (bool, error) {
+	out, err := m.Get(ctx, table, key)
+	if err != nil {
+		return false, err
+	}
+	return len(out.Item) > 0, nil
+}
+
+// table returns the table by name, caller must hold the lock.
+func (m *MemoryDynamo) table(name string) (*memoryTable, error) {
+	if err := validateTable(name); err != nil {
+		return nil, err
+	}
+	t, ok := m.tables[name]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String(fmt.Sprintf("table '%s' not found", name))}
+	}
+	return t, nil
+}
+
+// itemKey builds the internal storage key from the primary key attributes of the item.
+func (t *memoryTable) itemKey(item map[string]types.AttributeValue) (string, error) {
+	parts := make([]string, 0, 2)
+	for _, attr := range []string{t.def.HashKey, t.def.RangeKey} {
+		if attr == "" {
+			continue
+		}
+		v, ok := item[attr]
+		if !ok {
+			return "", errors.Errorf("missing key attribute '%s'", attr)
+		}
+		parts = append(parts, attrType(v)+":"+attrString(v))
+	}
+	return strings.Join(parts, "\x00"), nil
+}
+
+// keysFor returns the hash and range key of the table or the named index.
+func (t *memoryTable) keysFor(indexName string) (string, string, *MemoryIndex, error) {
+	if indexName == "" {
+		return t.def.HashKey, t.def.RangeKey, nil, nil
+	}
+	for i := range t.def.Indexes {
+		if t.def.Indexes[i].Name == indexName {
+			idx := &t.def.Indexes[i]
+			return idx.HashKey, idx.RangeKey, idx, nil
+		}
+	}
+	return "", "", nil, &types.ResourceNotFoundException{
+		Message: aws.String(fmt.Sprintf("index '%s' not found on table '%s'", indexName, t.def.Name)),
+	}
+}
+
+// sortItems orders items by the range key, ties are broken by the table primary key.
+func (t *memoryTable) sortItems(items []map[string]types.AttributeValue, rangeKey string) {
+	order := []string{rangeKey, t.def.HashKey, t.def.RangeKey}
+	sort.SliceStable(items, func(i, j int) bool {
+		return compareItems(items[i], items[j], order) < 0
+	})
+}
+
+func compareItems(a, b map[string]types.AttributeValue, order []string) int {
+	for _, attr := range order {
+		if attr == "" {
+			continue
+		}
+		av, aok := a[attr]
+		bv, bok := b[attr]
+		switch {
+		case !aok && !bok:
+			continue
+		case !aok:
+			return -1
+		case !bok:
+			return 1
+		}
+		if cmp, ok := compareAttr(av, bv); ok && cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+type memoryPage struct {
+	items   []map[string]types.AttributeValue
+	count   int32
+	scanned int32
+	lastKey map[string]types.AttributeValue
+}
+
+// page applies pagination, filter and projection to already ordered items.
+func (t *memoryTable) page(
+	items []map[string]types.AttributeValue,
+	index *MemoryIndex,
+	startKey map[string]types.AttributeValue,
+	limit int32,
+	filterExpr, projectionExpr *string,
+	names map[string]string,
+	values map[string]types.AttributeValue,
+) (memoryPage, error) {
+	var (
+		filter     condition
+		projection []attrPath
+		err        error
+	)
+	if filterExpr != nil && *filterExpr != "" {
+		if filter, err = parseCondition(*filterExpr, names, values); err != nil {
+			return memoryPage{}, err
+		}
+	}
+	if projectionExpr != nil && *projectionExpr != "" {
+		if projection, err = parseProjection(*projectionExpr, names); err != nil {
+			return memoryPage{}, err
+		}
+	}
+
+	start := 0
+	if len(startKey) > 0 {
+		startItemKey, err := t.itemKey(startKey)
+		if err != nil {
+			return memoryPage{}, errors.Wrap(err, "invalid exclusive start key")
+		}
+		for i, item := range items {
+			if k, _ := t.itemKey(item); k == startItemKey {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	var page memoryPage
+	for i := start; i < len(items); i++ {
+		item := t.indexProjection(items[i], index)
+		page.scanned++
+
+		if filter == nil || filter.eval(item) {
+			if projection != nil {
+				item = project(item, projection)
+			}
+			page.items = append(page.items, copyItem(item))
+			page.count++
+		}
+		if limit > 0 && page.scanned == limit && i < len(items)-1 {
+			page.lastKey = t.lastEvaluatedKey(items[i], index)
+			break
+		}
+	}
+	return page, nil
+}
+
+// indexProjection returns the attributes of the item visible through the index.
+func (t *memoryTable) indexProjection(item map[string]types.AttributeValue, index *MemoryIndex) map[string]types.AttributeValue {
+	if index == nil || index.ProjectionType == "" || index.ProjectionType == string(types.ProjectionTypeAll) {
+		return item
+	}
+	attrs := []string{t.def.HashKey, t.def.RangeKey, index.HashKey, index.RangeKey}
+	if index.ProjectionType == string(types.ProjectionTypeInclude) {
+		attrs = append(attrs, index.NonKeyAttributes...)
+	}
+	out := make(map[string]types.AttributeValue, len(attrs))
+	for _, attr := range attrs {
+		if v, ok := item[attr]; ok && attr != "" {
+			out[attr] = v
+		}
+	}
+	return out
+}
+
+// lastEvaluatedKey returns the table and index key attributes of the item.
+func (t *memoryTable) lastEvaluatedKey(item map[string]types.AttributeValue, index *MemoryIndex) map[string]types.AttributeValue {
+	attrs := []string{t.def.HashKey, t.def.RangeKey}
+	if index != nil {
+		attrs = append(attrs, index.HashKey, index.RangeKey)
+	}
+	key := make(map[string]types.AttributeValue, len(attrs))
+	for _, attr := range attrs {
+		if v, ok := item[attr]; ok && attr != "" {
+			key[attr] = copyAttr(v)
+		}
+	}
+	return key
+}
+
+// checkCondition evaluates the condition against the current item,
+// returning ConditionalCheckFailedException if it is not satisfied.
+func checkCondition(item map[string]types.AttributeValue, condition expression.ConditionBuilder) error {
+	if !condition.IsSet() {
+		return nil
+	}
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build condition expression: %w", err)
+	}
+	cond, err := parseCondition(aws.ToString(expr.Condition()), expr.Names(), expr.Values())
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		item = map[string]types.AttributeValue{}
+	}
+	if !cond.eval(item) {
+		return &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	}
+	return nil
+}
+
+// attrString returns a canonical string form of a scalar key value.
+func attrString(v types.AttributeValue) string {
+	switch av := v.(type) {
+	case *types.AttributeValueMemberS:
+		return av.Value
+	case *types.AttributeValueMemberN:
+		if n, err := strconv.ParseFloat(av.Value, 64); err == nil {
+			return formatNumber(n)
+		}
+		return av.Value
+	case *types.AttributeValueMemberB:
+		return string(av.Value)
+	}
+	return ""
+}
@@ -0,0 +1,1235 @@
+package cloud
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+)
+
+// This file implements a subset of the DynamoDB expression language used by MemoryDynamo:
+// condition, key condition, filter, projection and update expressions as produced
+// by the expression builder package.
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokName
+	tokValue
+	tokNumber
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(input string) ([]token, error) {
+	var (
+		tokens []token
+		runes  = []rune(input)
+	)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '#' || r == ':' || r == '_' || unicode.IsLetter(r):
+			start := i
+			i++
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			kind := tokIdent
+			switch r {
+			case '#':
+				kind = tokName
+			case ':':
+				kind = tokValue
+			}
+			tokens = append(tokens, token{kind: kind, text: string(runes[start:i])})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: string(runes[start:i])})
+		case r == '<' || r == '>':
+			if i+1 < len(runes) && (runes[i+1] == '=' || (r == '<' && runes[i+1] == '>')) {
+				tokens = append(tokens, token{kind: tokPunct, text: string(runes[i : i+2])})
+				i += 2
+				continue
+			}
+			tokens = append(tokens, token{kind: tokPunct, text: string(r)})
+			i++
+		case strings.ContainsRune("()[],.=+-", r):
+			tokens = append(tokens, token{kind: tokPunct, text: string(r)})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' in expression", r)
+		}
+	}
+	return append(tokens, token{kind: tokEOF}), nil
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+	names  map[string]string
+	values map[string]types.AttributeValue
+}
+
+func newExprParser(input string, names map[string]string, values map[string]types.AttributeValue) (*exprParser, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	return &exprParser{tokens: tokens, names: names, values: values}, nil
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) isKeyword(word string) bool {
+	t := p.peek()
+	return t.kind == tokIdent && strings.EqualFold(t.text, word)
+}
+
+func (p *exprParser) isPunct(text string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.text == text
+}
+
+func (p *exprParser) expectPunct(text string) error {
+	if t := p.next(); t.kind != tokPunct || t.text != text {
+		return fmt.Errorf("expected '%s', got '%s'", text, t.text)
+	}
+	return nil
+}
+
+func (p *exprParser) expectEOF() error {
+	if t := p.peek(); t.kind != tokEOF {
+		return fmt.Errorf("unexpected token '%s'", t.text)
+	}
+	return nil
+}
+
+// attrPath is a document path like "a.b[1].c".
+type attrPath []pathElem
+
+type pathElem struct {
+	name  string
+	index int
+	list  bool
+}
+
+func (p attrPath) String() string {
+	var sb strings.Builder
+	for i, e := range p {
+		if e.list {
+			fmt.Fprintf(&sb, "[%d]", e.index)
+			continue
+		}
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(e.name)
+	}
+	return sb.String()
+}
+
+func (p *exprParser) parsePath() (attrPath, error) {
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	path := attrPath{{name: name}}
+	for {
+		switch {
+		case p.isPunct("."):
+			p.next()
+			name, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, pathElem{name: name})
+		case p.isPunct("["):
+			p.next()
+			t := p.next()
+			if t.kind != tokNumber {
+				return nil, fmt.Errorf("expected list index, got '%s'", t.text)
+			}
+			index, _ := strconv.Atoi(t.text)
+			if err := p.expectPunct("]"); err != nil {
+				return nil, err
+			}
+			path = append(path, pathElem{index: index, list: true})
+		default:
+			return path, nil
+		}
+	}
+}
+
+func (p *exprParser) parseName() (string, error) {
+	t := p.next()
+	switch t.kind {
+	case tokName:
+		name, ok := p.names[t.text]
+		if !ok {
+			return "", fmt.Errorf("undefined expression attribute name '%s'", t.text)
+		}
+		return name, nil
+	case tokIdent:
+		return t.text, nil
+	default:
+		return "", fmt.Errorf("expected attribute name, got '%s'", t.text)
+	}
+}
+
+func (p *exprParser) parseValue() (types.AttributeValue, error) {
+	t := p.next()
+	if t.kind != tokValue {
+		return nil, fmt.Errorf("expected expression attribute value, got '%s'", t.text)
+	}
+	v, ok := p.values[t.text]
+	if !ok {
+		return nil, fmt.Errorf("undefined expression attribute value '%s'", t.text)
+	}
+	return v, nil
+}
+
+// operand is a value referenced by a condition: an attribute path, a placeholder value or size().
+type operand interface {
+	resolve(item map[string]types.AttributeValue) (types.AttributeValue, bool)
+}
+
+type pathOperand struct{ path attrPath }
+
+func (o pathOperand) resolve(item map[string]types.AttributeValue) (types.AttributeValue, bool) {
+	return getPath(item, o.path)
+}
+
+type valueOperand struct{ value types.AttributeValue }
+
+func (o valueOperand) resolve(map[string]types.AttributeValue) (types.AttributeValue, bool) {
+	return o.value, true
+}
+
+type sizeOperand struct{ path attrPath }
+
+func (o sizeOperand) resolve(item map[string]types.AttributeValue) (types.AttributeValue, bool) {
+	v, ok := getPath(item, o.path)
+	if !ok {
+		return nil, false
+	}
+	var size int
+	switch av := v.(type) {
+	case *types.AttributeValueMemberS:
+		size = len(av.Value)
+	case *types.AttributeValueMemberB:
+		size = len(av.Value)
+	case *types.AttributeValueMemberSS:
+		size = len(av.Value)
+	case *types.AttributeValueMemberNS:
+		size = len(av.Value)
+	case *types.AttributeValueMemberBS:
+		size = len(av.Value)
+	case *types.AttributeValueMemberL:
+		size = len(av.Value)
+	case *types.AttributeValueMemberM:
+		size = len(av.Value)
+	default:
+		return nil, false
+	}
+	return &types.AttributeValueMemberN{Value: strconv.Itoa(size)}, true
+}
+
+func (p *exprParser) parseOperand() (operand, error) {
+	t := p.peek()
+	if t.kind == tokValue {
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return valueOperand{value: v}, nil
+	}
+	if t.kind == tokIdent && strings.EqualFold(t.text, "size") && p.tokens[p.pos+1].text == "(" {
+		p.next()
+		p.next()
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+		return sizeOperand{path: path}, nil
+	}
+	path, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	return pathOperand{path: path}, nil
+}
+
+// condition is a parsed condition, key condition or filter expression.
+type condition interface {
+	eval(item map[string]types.AttributeValue) bool
+}
+
+type andCondition struct{ left, right condition }
+
+func (c andCondition) eval(item map[string]types.AttributeValue) bool {
+	return c.left.eval(item) && c.right.eval(item)
+}
+
+type orCondition struct{ left, right condition }
+
+func (c orCondition) eval(item map[string]types.AttributeValue) bool {
+	return c.left.eval(item) || c.right.eval(item)
+}
+
+type notCondition struct{ inner condition }
+
+func (c notCondition) eval(item map[string]types.AttributeValue) bool {
+	return !c.inner.eval(item)
+}
+
+type compareCondition struct {
+	op          string
+	left, right operand
+}
+
+func (c compareCondition) eval(item map[string]types.AttributeValue) bool {
+	l, lok := c.left.resolve(item)
+	r, rok := c.right.resolve(item)
+	if !lok || !rok {
+		return false
+	}
+	switch c.op {
+	case "=":
+		return attrEqual(l, r)
+	case "<>":
+		return !attrEqual(l, r)
+	}
+	cmp, ok := compareAttr(l, r)
+	if !ok {
+		return false
+	}
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+type betweenCondition struct{ value, low, high operand }
+
+func (c betweenCondition) eval(item map[string]types.AttributeValue) bool {
+	v, ok := c.value.resolve(item)
+	if !ok {
+		return false
+	}
+	low, lok := c.low.resolve(item)
+	high, hok := c.high.resolve(item)
+	if !lok || !hok {
+		return false
+	}
+	lcmp, ok1 := compareAttr(v, low)
+	hcmp, ok2 := compareAttr(v, high)
+	return ok1 && ok2 && lcmp >= 0 && hcmp <= 0
+}
+
+type inCondition struct {
+	value operand
+	list  []operand
+}
+
+func (c inCondition) eval(item map[string]types.AttributeValue) bool {
+	v, ok := c.value.resolve(item)
+	if !ok {
+		return false
+	}
+	for _, o := range c.list {
+		if candidate, ok := o.resolve(item); ok && attrEqual(v, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+type funcCondition struct {
+	name string
+	path attrPath
+	arg  operand
+}
+
+func (c funcCondition) eval(item map[string]types.AttributeValue) bool {
+	v, exists := getPath(item, c.path)
+	switch c.name {
+	case "attribute_exists":
+		return exists
+	case "attribute_not_exists":
+		return !exists
+	}
+	if !exists {
+		return false
+	}
+	arg, ok := c.arg.resolve(item)
+	if !ok {
+		return false
+	}
+
+	switch c.name {
+	case "attribute_type":
+		t, ok := arg.(*types.AttributeValueMemberS)
+		return ok && attrType(v) == t.Value
+	case "begins_with":
+		switch av := v.(type) {
+		case *types.AttributeValueMemberS:
+			prefix, ok := arg.(*types.AttributeValueMemberS)
+			return ok && strings.HasPrefix(av.Value, prefix.Value)
+		case *types.AttributeValueMemberB:
+			prefix, ok := arg.(*types.AttributeValueMemberB)
+			return ok && bytes.HasPrefix(av.Value, prefix.Value)
+		}
+	case "contains":
+		switch av := v.(type) {
+		case *types.AttributeValueMemberS:
+			sub, ok := arg.(*types.AttributeValueMemberS)
+			return ok && strings.Contains(av.Value, sub.Value)
+		case *types.AttributeValueMemberB:
+			sub, ok := arg.(*types.AttributeValueMemberB)
+			return ok && bytes.Contains(av.Value, sub.Value)
+		case *types.AttributeValueMemberSS, *types.AttributeValueMemberNS, *types.AttributeValueMemberBS:
+			return setContains(v, arg)
+		case *types.AttributeValueMemberL:
+			for _, elem := range av.Value {
+				if attrEqual(elem, arg) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// parseCondition parses a complete condition expression.
+func parseCondition(input string, names map[string]string, values map[string]types.AttributeValue) (condition, error) {
+	p, err := newExprParser(input, names, values)
+	if err != nil {
+		return nil, err
+	}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid expression '%s'", input)
+	}
+	if err := p.expectEOF(); err != nil {
+		return nil, errors.Wrapf(err, "invalid expression '%s'", input)
+	}
+	return cond, nil
+}
+
+func (p *exprParser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orCondition{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (condition, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andCondition{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseNot() (condition, error) {
+	if p.isKeyword("NOT") {
+		p.next()
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notCondition{inner: inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (condition, error) {
+	if p.isPunct("(") {
+		p.next()
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return cond, p.expectPunct(")")
+	}
+
+	t := p.peek()
+	if t.kind == tokIdent && p.tokens[p.pos+1].text == "(" {
+		switch name := strings.ToLower(t.text); name {
+		case "attribute_exists", "attribute_not_exists", "attribute_type", "begins_with", "contains":
+			p.next()
+			p.next()
+			path, err := p.parsePath()
+			if err != nil {
+				return nil, err
+			}
+			cond := funcCondition{name: name, path: path}
+			if name != "attribute_exists" && name != "attribute_not_exists" {
+				if err := p.expectPunct(","); err != nil {
+					return nil, err
+				}
+				if cond.arg, err = p.parseOperand(); err != nil {
+					return nil, err
+				}
+			}
+			return cond, p.expectPunct(")")
+		}
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.isKeyword("BETWEEN"):
+		p.next()
+		low, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isKeyword("AND") {
+			return nil, errors.New("expected AND in BETWEEN")
+		}
+		p.next()
+		high, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return betweenCondition{value: left, low: low, high: high}, nil
+	case p.isKeyword("IN"):
+		p.next()
+		if err := p.expectPunct("("); err != nil {
+			return nil, err
+		}
+		cond := inCondition{value: left}
+		for {
+			o, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			cond.list = append(cond.list, o)
+			if !p.isPunct(",") {
+				break
+			}
+			p.next()
+		}
+		return cond, p.expectPunct(")")
+	}
+
+	op := p.next()
+	switch op.text {
+	case "=", "<>", "<", "<=", ">", ">=":
+	default:
+		return nil, fmt.Errorf("expected comparator, got '%s'", op.text)
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return compareCondition{op: op.text, left: left, right: right}, nil
+}
+
+// parseProjection parses a projection expression into a list of paths.
+func parseProjection(input string, names map[string]string) ([]attrPath, error) {
+	p, err := newExprParser(input, names, nil)
+	if err != nil {
+		return nil, err
+	}
+	var paths []attrPath
+	for {
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid projection '%s'", input)
+		}
+		paths = append(paths, path)
+		if !p.isPunct(",") {
+			break
+		}
+		p.next()
+	}
+	return paths, p.expectEOF()
+}
+
+// project returns a copy of the item containing only the given paths.
+func project(item map[string]types.AttributeValue, paths []attrPath) map[string]types.AttributeValue {
+	out := make(map[string]types.AttributeValue, len(paths))
+	for _, path := range paths {
+		if v, ok := getPath(item, path); ok {
+			_ = setPath(out, path, copyAttr(v))
+		}
+	}
+	return out
+}
+
+// updateValue is the right-hand side of a SET action.
+type updateValue interface {
+	resolve(item map[string]types.AttributeValue) (types.AttributeValue, error)
+}
+
+type operandValue struct{ operand operand }
+
+func (v operandValue) resolve(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+	av, ok := v.operand.resolve(item)
+	if !ok {
+		return nil, errors.New("the provided expression refers to an attribute that does not exist in the item")
+	}
+	return av, nil
+}
+
+type ifNotExistsValue struct {
+	path     attrPath
+	fallback updateValue
+}
+
+func (v ifNotExistsValue) resolve(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+	if av, ok := getPath(item, v.path); ok {
+		return av, nil
+	}
+	return v.fallback.resolve(item)
+}
+
+type listAppendValue struct{ left, right updateValue }
+
+func (v listAppendValue) resolve(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+	l, err := v.left.resolve(item)
+	if err != nil {
+		return nil, err
+	}
+	r, err := v.right.resolve(item)
+	if err != nil {
+		return nil, err
+	}
+	ll, lok := l.(*types.AttributeValueMemberL)
+	rl, rok := r.(*types.AttributeValueMemberL)
+	if !lok || !rok {
+		return nil, errors.New("list_append operands must be lists")
+	}
+	out := make([]types.AttributeValue, 0, len(ll.Value)+len(rl.Value))
+	out = append(out, ll.Value...)
+	out = append(out, rl.Value...)
+	return &types.AttributeValueMemberL{Value: out}, nil
+}
+
+type arithmeticValue struct {
+	op          string
+	left, right updateValue
+}
+
+func (v arithmeticValue) resolve(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+	l, err := v.left.resolve(item)
+	if err != nil {
+		return nil, err
+	}
+	r, err := v.right.resolve(item)
+	if err != nil {
+		return nil, err
+	}
+	ln, lok := l.(*types.AttributeValueMemberN)
+	rn, rok := r.(*types.AttributeValueMemberN)
+	if !lok || !rok {
+		return nil, errors.New("arithmetic operands must be numbers")
+	}
+	a, _ := strconv.ParseFloat(ln.Value, 64)
+	b, _ := strconv.ParseFloat(rn.Value, 64)
+	if v.op == "-" {
+		b = -b
+	}
+	return &types.AttributeValueMemberN{Value: formatNumber(a + b)}, nil
+}
+
+type updateAction struct {
+	kind  string
+	path  attrPath
+	value updateValue
+}
+
+// parseUpdate parses an update expression with SET, REMOVE, ADD and DELETE clauses.
+func parseUpdate(input string, names map[string]string, values map[string]types.AttributeValue) ([]updateAction, error) {
+	p, err := newExprParser(input, names, values)
+	if err != nil {
+		return nil, err
+	}
+	var actions []updateAction
+	for p.peek().kind != tokEOF {
+		clause := strings.ToUpper(p.next().text)
+		switch clause {
+		case "SET", "REMOVE", "ADD", "DELETE":
+		default:
+			return nil, fmt.Errorf("invalid update expression '%s', unexpected clause '%s'", input, clause)
+		}
+		for {
+			action, err := p.parseUpdateAction(clause)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid update expression '%s'", input)
+			}
+			actions = append(actions, action)
+			if !p.isPunct(",") {
+				break
+			}
+			p.next()
+		}
+	}
+	return actions, nil
+}
+
+func (p *exprParser) parseUpdateAction(clause string) (updateAction, error) {
+	path, err := p.parsePath()
+	if err != nil {
+		return updateAction{}, err
+	}
+	action := updateAction{kind: clause, path: path}
+
+	switch clause {
+	case "SET":
+		if err := p.expectPunct("="); err != nil {
+			return updateAction{}, err
+		}
+		left, err := p.parseSetOperand()
+		if err != nil {
+			return updateAction{}, err
+		}
+		if p.isPunct("+") || p.isPunct("-") {
+			op := p.next().text
+			right, err := p.parseSetOperand()
+			if err != nil {
+				return updateAction{}, err
+			}
+			left = arithmeticValue{op: op, left: left, right: right}
+		}
+		action.value = left
+	case "ADD", "DELETE":
+		v, err := p.parseValue()
+		if err != nil {
+			return updateAction{}, err
+		}
+		action.value = operandValue{operand: valueOperand{value: v}}
+	}
+	return action, nil
+}
+
+func (p *exprParser) parseSetOperand() (updateValue, error) {
+	t := p.peek()
+	if t.kind == tokIdent && p.tokens[p.pos+1].text == "(" {
+		switch strings.ToLower(t.text) {
+		case "if_not_exists":
+			p.next()
+			p.next()
+			path, err := p.parsePath()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectPunct(","); err != nil {
+				return nil, err
+			}
+			fallback, err := p.parseSetOperand()
+			if err != nil {
+				return nil, err
+			}
+			return ifNotExistsValue{path: path, fallback: fallback}, p.expectPunct(")")
+		case "list_append":
+			p.next()
+			p.next()
+			left, err := p.parseSetOperand()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectPunct(","); err != nil {
+				return nil, err
+			}
+			right, err := p.parseSetOperand()
+			if err != nil {
+				return nil, err
+			}
+			return listAppendValue{left: left, right: right}, p.expectPunct(")")
+		}
+	}
+	o, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return operandValue{operand: o}, nil
+}
+
+// applyUpdate applies parsed update actions to the item in place.
+// All values are resolved against the original item, as DynamoDB does.
+func applyUpdate(item map[string]types.AttributeValue, actions []updateAction) error {
+	original := copyItem(item)
+
+	resolved := make([]types.AttributeValue, len(actions))
+	for i, a := range actions {
+		if a.value == nil {
+			continue
+		}
+		v, err := a.value.resolve(original)
+		if err != nil {
+			return errors.Wrapf(err, "failed to resolve value for '%s'", a.path)
+		}
+		resolved[i] = copyAttr(v)
+	}
+
+	for i, a := range actions {
+		switch a.kind {
+		case "SET":
+			if err := setPath(item, a.path, resolved[i]); err != nil {
+				return err
+			}
+		case "REMOVE":
+			removePath(item, a.path)
+		case "ADD":
+			current, exists := getPath(item, a.path)
+			if !exists {
+				if err := setPath(item, a.path, resolved[i]); err != nil {
+					return err
+				}
+				continue
+			}
+			sum, err := addAttr(current, resolved[i])
+			if err != nil {
+				return errors.Wrapf(err, "failed to ADD to '%s'", a.path)
+			}
+			if err := setPath(item, a.path, sum); err != nil {
+				return err
+			}
+		case "DELETE":
+			current, exists := getPath(item, a.path)
+			if !exists {
+				continue
+			}
+			diff, err := deleteFromSet(current, resolved[i])
+			if err != nil {
+				return errors.Wrapf(err, "failed to DELETE from '%s'", a.path)
+			}
+			if diff == nil {
+				removePath(item, a.path)
+				continue
+			}
+			if err := setPath(item, a.path, diff); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func getPath(item map[string]types.AttributeValue, path attrPath) (types.AttributeValue, bool) {
+	if len(path) == 0 || path[0].list {
+		return nil, false
+	}
+	current, ok := item[path[0].name]
+	if !ok {
+		return nil, false
+	}
+	for _, e := range path[1:] {
+		switch av := current.(type) {
+		case *types.AttributeValueMemberM:
+			if e.list {
+				return nil, false
+			}
+			if current, ok = av.Value[e.name]; !ok {
+				return nil, false
+			}
+		case *types.AttributeValueMemberL:
+			if !e.list || e.index >= len(av.Value) {
+				return nil, false
+			}
+			current = av.Value[e.index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func setPath(item map[string]types.AttributeValue, path attrPath, value types.AttributeValue) error {
+	if len(path) == 1 {
+		item[path[0].name] = value
+		return nil
+	}
+	parent, ok := getPath(item, path[:len(path)-1])
+	if !ok {
+		return fmt.Errorf("the document path '%s' is invalid for update", path)
+	}
+	last := path[len(path)-1]
+	switch av := parent.(type) {
+	case *types.AttributeValueMemberM:
+		if last.list {
+			return fmt.Errorf("the document path '%s' is invalid for update", path)
+		}
+		av.Value[last.name] = value
+	case *types.AttributeValueMemberL:
+		if !last.list {
+			return fmt.Errorf("the document path '%s' is invalid for update", path)
+		}
+		if last.index >= len(av.Value) {
+			av.Value = append(av.Value, value)
+			return nil
+		}
+		av.Value[last.index] = value
+	default:
+		return fmt.Errorf("the document path '%s' is invalid for update", path)
+	}
+	return nil
+}
+
+func removePath(item map[string]types.AttributeValue, path attrPath) {
+	if len(path) == 1 {
+		delete(item, path[0].name)
+		return
+	}
+	parent, ok := getPath(item, path[:len(path)-1])
+	if !ok {
+		return
+	}
+	last := path[len(path)-1]
+	switch av := parent.(type) {
+	case *types.AttributeValueMemberM:
+		delete(av.Value, last.name)
+	case *types.AttributeValueMemberL:
+		if last.list && last.index < len(av.Value) {
+			av.Value = append(av.Value[:last.index], av.Value[last.index+1:]...)
+		}
+	}
+}
+
+func addAttr(current, value types.AttributeValue) (types.AttributeValue, error) {
+	switch cv := current.(type) {
+	case *types.AttributeValueMemberN:
+		n, ok := value.(*types.AttributeValueMemberN)
+		if !ok {
+			return nil, errors.New("type mismatch")
+		}
+		a, _ := strconv.ParseFloat(cv.Value, 64)
+		b, _ := strconv.ParseFloat(n.Value, 64)
+		return &types.AttributeValueMemberN{Value: formatNumber(a + b)}, nil
+	case *types.AttributeValueMemberSS:
+		s, ok := value.(*types.AttributeValueMemberSS)
+		if !ok {
+			return nil, errors.New("type mismatch")
+		}
+		return &types.AttributeValueMemberSS{Value: unionStrings(cv.Value, s.Value)}, nil
+	case *types.AttributeValueMemberNS:
+		s, ok := value.(*types.AttributeValueMemberNS)
+		if !ok {
+			return nil, errors.New("type mismatch")
+		}
+		return &types.AttributeValueMemberNS{Value: unionStrings(cv.Value, s.Value)}, nil
+	}
+	return nil, errors.New("ADD supports only numbers and sets")
+}
+
+func deleteFromSet(current, value types.AttributeValue) (types.AttributeValue, error) {
+	var remaining []string
+	switch cv := current.(type) {
+	case *types.AttributeValueMemberSS:
+		s, ok := value.(*types.AttributeValueMemberSS)
+		if !ok {
+			return nil, errors.New("type mismatch")
+		}
+		if remaining = subtractStrings(cv.Value, s.Value); len(remaining) > 0 {
+			return &types.AttributeValueMemberSS{Value: remaining}, nil
+		}
+	case *types.AttributeValueMemberNS:
+		s, ok := value.(*types.AttributeValueMemberNS)
+		if !ok {
+			return nil, errors.New("type mismatch")
+		}
+		if remaining = subtractStrings(cv.Value, s.Value); len(remaining) > 0 {
+			return &types.AttributeValueMemberNS{Value: remaining}, nil
+		}
+	default:
+		return nil, errors.New("DELETE supports only sets")
+	}
+	return nil, nil
+}
+
+func unionStrings(a, b []string) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	out := make([]string, 0, len(a)+len(b))
+	for _, s := range append(append([]string{}, a...), b...) {
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		out = append(out, s)
+	}
+	return out
+}
+
+func subtractStrings(a, b []string) []string {
+	drop := make(map[string]struct{}, len(b))
+	for _, s := range b {
+		drop[s] = struct{}{}
+	}
+	var out []string
+	for _, s := range a {
+		if _, ok := drop[s]; !ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func attrType(v types.AttributeValue) string {
+	switch v.(type) {
+	case *types.AttributeValueMemberS:
+		return "S"
+	case *types.AttributeValueMemberN:
+		return "N"
+	case *types.AttributeValueMemberB:
+		return "B"
+	case *types.AttributeValueMemberBOOL:
+		return "BOOL"
+	case *types.AttributeValueMemberNULL:
+		return "NULL"
+	case *types.AttributeValueMemberSS:
+		return "SS"
+	case *types.AttributeValueMemberNS:
+		return "NS"
+	case *types.AttributeValueMemberBS:
+		return "BS"
+	case *types.AttributeValueMemberL:
+		return "L"
+	case *types.AttributeValueMemberM:
+		return "M"
+	}
+	return ""
+}
+
+// compareAttr orders two scalar values of the same type (S, N or B).
+func compareAttr(a, b types.AttributeValue) (int, bool) {
+	switch av := a.(type) {
+	case *types.AttributeValueMemberS:
+		bv, ok := b.(*types.AttributeValueMemberS)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(av.Value, bv.Value), true
+	case *types.AttributeValueMemberN:
+		bv, ok := b.(*types.AttributeValueMemberN)
+		if !ok {
+			return 0, false
+		}
+		x, err1 := strconv.ParseFloat(av.Value, 64)
+		y, err2 := strconv.ParseFloat(bv.Value, 64)
+		if err1 != nil || err2 != nil {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	case *types.AttributeValueMemberB:
+		bv, ok := b.(*types.AttributeValueMemberB)
+		if !ok {
+			return 0, false
+		}
+		return bytes.Compare(av.Value, bv.Value), true
+	}
+	return 0, false
+}
+
+func attrEqual(a, b types.AttributeValue) bool {
+	if attrType(a) != attrType(b) {
+		return false
+	}
+	switch av := a.(type) {
+	case *types.AttributeValueMemberS, *types.AttributeValueMemberN, *types.AttributeValueMemberB:
+		cmp, ok := compareAttr(a, b)
+		return ok && cmp == 0
+	case *types.AttributeValueMemberBOOL:
+		return av.Value == b.(*types.AttributeValueMemberBOOL).Value
+	case *types.AttributeValueMemberNULL:
+		return true
+	case *types.AttributeValueMemberSS:
+		return sameStrings(av.Value, b.(*types.AttributeValueMemberSS).Value)
+	case *types.AttributeValueMemberNS:
+		return sameStrings(av.Value, b.(*types.AttributeValueMemberNS).Value)
+	case *types.AttributeValueMemberBS:
+		bv := b.(*types.AttributeValueMemberBS)
+		if len(av.Value) != len(bv.Value) {
+			return false
+		}
+		for _, x := range av.Value {
+			if !setContains(b, &types.AttributeValueMemberB{Value: x}) {
+				return false
+			}
+		}
+		return true
+	case *types.AttributeValueMemberL:
+		bv := b.(*types.AttributeValueMemberL)
+		if len(av.Value) != len(bv.Value) {
+			return false
+		}
+		for i := range av.Value {
+			if !attrEqual(av.Value[i], bv.Value[i]) {
+				return false
+			}
+		}
+		return true
+	case *types.AttributeValueMemberM:
+		bv := b.(*types.AttributeValueMemberM)
+		if len(av.Value) != len(bv.Value) {
+			return false
+		}
+		for k, v := range av.Value {
+			other, ok := bv.Value[k]
+			if !ok || !attrEqual(v, other) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := append([]string{}, a...)
+	y := append([]string{}, b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func setContains(set, value types.AttributeValue) bool {
+	switch sv := set.(type) {
+	case *types.AttributeValueMemberSS:
+		if v, ok := value.(*types.AttributeValueMemberS); ok {
+			for _, s := range sv.Value {
+				if s == v.Value {
+					return true
+				}
+			}
+		}
+	case *types.AttributeValueMemberNS:
+		if v, ok := value.(*types.AttributeValueMemberN); ok {
+			for _, s := range sv.Value {
+				if cmp, ok := compareAttr(&types.AttributeValueMemberN{Value: s}, v); ok && cmp == 0 {
+					return true
+				}
+			}
+		}
+	case *types.AttributeValueMemberBS:
+		if v, ok := value.(*types.AttributeValueMemberB); ok {
+			for _, b := range sv.Value {
+				if bytes.Equal(b, v.Value) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func copyItem(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+	out := make(map[string]types.AttributeValue, len(item))
+	for k, v := range item {
+		out[k] = copyAttr(v)
+	}
+	return out
+}
+
+func copyAttr(v types.AttributeValue) types.AttributeValue {
+	switch av := v.(type) {
+	case *types.AttributeValueMemberS:
+		return &types.AttributeValueMemberS{Value: av.Value}
+	case *types.AttributeValueMemberN:
+		return &types.AttributeValueMemberN{Value: av.Value}
+	case *types.AttributeValueMemberB:
+		return &types.AttributeValueMemberB{Value: append([]byte{}, av.Value...)}
+	case *types.AttributeValueMemberBOOL:
+		return &types.AttributeValueMemberBOOL{Value: av.Value}
+	case *types.AttributeValueMemberNULL:
+		return &types.AttributeValueMemberNULL{Value: av.Value}
+	case *types.AttributeValueMemberSS:
+		return &types.AttributeValueMemberSS{Value: append([]string{}, av.Value...)}
+	case *types.AttributeValueMemberNS:
+		return &types.AttributeValueMemberNS{Value: append([]string{}, av.Value...)}
+	case *types.AttributeValueMemberBS:
+		out := make([][]byte, len(av.Value))
+		for i, b := range av.Value {
+			out[i] = append([]byte{}, b...)
+		}
+		return &types.AttributeValueMemberBS{Value: out}
+	case *types.AttributeValueMemberL:
+		out := make([]types.AttributeValue, len(av.Value))
+		for i, e := range av.Value {
+			out[i] = copyAttr(e)
+		}
+		return &types.AttributeValueMemberL{Value: out}
+	case *types.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: copyItem(av.Value)}
+	}
+	return v
+}
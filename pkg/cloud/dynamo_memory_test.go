@@ -0,0 +1,265 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTable = "items"
+
+func newTestDynamo(t *testing.T) *MemoryDynamo {
+	t.Helper()
+
+	db := NewMemoryDynamo(MemoryTable{
+		Name:     testTable,
+		HashKey:  "id",
+		RangeKey: "group",
+		Indexes: []MemoryIndex{
+			{Name: "ByScore", HashKey: "kind", RangeKey: "score", ProjectionType: "INCLUDE", NonKeyAttributes: []string{"name"}},
+			{Name: "KeysByScore", HashKey: "kind", RangeKey: "score", ProjectionType: "KEYS_ONLY"},
+		},
+	})
+
+	var items []map[string]types.AttributeValue
+	for i := 1; i <= 5; i++ {
+		items = append(items, map[string]types.AttributeValue{
+			"id":    &types.AttributeValueMemberS{Value: fmt.Sprintf("id-%d", i)},
+			"group": &types.AttributeValueMemberS{Value: "g"},
+			"kind":  &types.AttributeValueMemberS{Value: "word"},
+			"score": &types.AttributeValueMemberN{Value: fmt.Sprint(i * 10)},
+			"name":  &types.AttributeValueMemberS{Value: fmt.Sprintf("name-%d", i)},
+			"extra": &types.AttributeValueMemberS{Value: "hidden"},
+		})
+	}
+	require.NoError(t, db.BatchWrite(context.Background(), testTable, items))
+	return db
+}
+
+func testKey(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"id":    &types.AttributeValueMemberS{Value: id},
+		"group": &types.AttributeValueMemberS{Value: "g"},
+	}
+}
+
+func TestNewMemoryTable(t *testing.T) {
+	type index struct {
+		Name, HashKey, RangeKey, ProjectionType string
+		NonKeyAttributes                        []string
+	}
+	schema := struct {
+		TableName, HashKey, RangeKey string
+		SecondaryIndexes             []index
+	}{
+		TableName: testTable,
+		HashKey:   "id",
+		RangeKey:  "group",
+		SecondaryIndexes: []index{
+			{Name: "ByScore", HashKey: "kind", RangeKey: "score", ProjectionType: "INCLUDE", NonKeyAttributes: []string{"name"}},
+		},
+	}
+
+	assert.Equal(t, MemoryTable{
+		Name:     testTable,
+		HashKey:  "id",
+		RangeKey: "group",
+		Indexes: []MemoryIndex{
+			{Name: "ByScore", HashKey: "kind", RangeKey: "score", ProjectionType: "INCLUDE", NonKeyAttributes: []string{"name"}},
+		},
+	}, NewMemoryTable(schema))
+}
+
+func TestMemoryDynamoPutCondition(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDynamo(t)
+
+	item := testKey("id-1")
+	item["name"] = &types.AttributeValueMemberS{Value: "replaced"}
+
+	err := db.Put(ctx, testTable, item, expression.AttributeNotExists(expression.Name("id")))
+	var condErr *types.ConditionalCheckFailedException
+	require.True(t, errors.As(err, &condErr))
+
+	require.NoError(t, db.Put(ctx, testTable, item, expression.Name("name").Equal(expression.Value("name-1"))))
+	out, err := db.Get(ctx, testTable, testKey("id-1"))
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "replaced"}, out.Item["name"])
+	assert.NotContains(t, out.Item, "extra")
+
+	_, err = db.Get(ctx, "missing", testKey("id-1"))
+	var notFound *types.ResourceNotFoundException
+	assert.True(t, errors.As(err, &notFound))
+}
+
+func TestMemoryDynamoBatchGet(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDynamo(t)
+
+	items, err := db.BatchGet(ctx, testTable, []map[string]types.AttributeValue{testKey("id-2"), testKey("id-9"), testKey("id-4")})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "id-2"}, items[0]["id"])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "id-4"}, items[1]["id"])
+
+	_, err = db.BatchGet(ctx, testTable, []map[string]types.AttributeValue{{"id": &types.AttributeValueMemberS{Value: "id-1"}}})
+	assert.Error(t, err)
+}
+
+func TestMemoryDynamoUpdate(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDynamo(t)
+
+	update := expression.
+		Add(expression.Name("score"), expression.Value(5)).
+		Set(expression.Name("tags"), expression.ListAppend(
+			expression.IfNotExists(expression.Name("tags"), expression.Value([]string{})),
+			expression.Value([]string{"new"}),
+		)).
+		Remove(expression.Name("extra"))
+	require.NoError(t, db.Update(ctx, testTable, testKey("id-1"), update, expression.AttributeExists(expression.Name("id"))))
+
+	out, err := db.Get(ctx, testTable, testKey("id-1"))
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "15"}, out.Item["score"])
+	assert.Equal(t, &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "new"}}}, out.Item["tags"])
+	assert.NotContains(t, out.Item, "extra")
+
+	err = db.Update(ctx, testTable, testKey("id-9"), update, expression.AttributeExists(expression.Name("id")))
+	var condErr *types.ConditionalCheckFailedException
+	assert.True(t, errors.As(err, &condErr))
+
+	err = db.Update(ctx, testTable, testKey("id-1"), expression.Set(expression.Name("id"), expression.Value("other")), expression.ConditionBuilder{})
+	assert.Error(t, err)
+}
+
+func TestMemoryDynamoTransactWrite(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDynamo(t)
+
+	added := testKey("id-6")
+	added["score"] = &types.AttributeValueMemberN{Value: "60"}
+	bump := expression.Add(expression.Name("score"), expression.Value(1))
+
+	// a failed condition cancels every write of the transaction.
+	err := db.TransactWrite(ctx, []TransactWriteItem{
+		{Table: testTable, Item: added, Condition: expression.AttributeNotExists(expression.Name("id"))},
+		{Table: testTable, Key: testKey("id-1"), Update: &bump},
+		{Table: testTable, Key: testKey("id-9"), Condition: expression.AttributeExists(expression.Name("id"))},
+	})
+	var condErr *types.ConditionalCheckFailedException
+	require.True(t, errors.As(err, &condErr))
+	exists, err := db.Exists(ctx, testTable, testKey("id-6"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, db.TransactWrite(ctx, []TransactWriteItem{
+		{Table: testTable, Item: added, Condition: expression.AttributeNotExists(expression.Name("id"))},
+		{Table: testTable, Key: testKey("id-1"), Update: &bump},
+		{Table: testTable, Key: testKey("id-2"), Delete: true},
+	}))
+	out, err := db.Get(ctx, testTable, testKey("id-1"))
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "11"}, out.Item["score"])
+	exists, err = db.Exists(ctx, testTable, testKey("id-6"))
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = db.Exists(ctx, testTable, testKey("id-2"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	err = db.TransactWrite(ctx, []TransactWriteItem{
+		{Table: testTable, Key: testKey("id-1"), Update: &bump},
+		{Table: testTable, Key: testKey("id-1"), Delete: true},
+	})
+	assert.Error(t, err)
+
+	writes := make([]TransactWriteItem, 0, MaxTransactItems+1)
+	for i := range MaxTransactItems + 1 {
+		writes = append(writes, TransactWriteItem{Table: testTable, Item: testKey(fmt.Sprintf("bulk-%d", i))})
+	}
+	assert.Error(t, db.TransactWrite(ctx, writes))
+	require.NoError(t, db.TransactWrite(ctx, writes[:MaxTransactItems]))
+}
+
+func TestMemoryDynamoQueryIndex(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDynamo(t)
+
+	input, err := db.BuildQueryInput(QueryInput{
+		IndexName:       "ByScore",
+		KeyCondition:    expression.Key("kind").Equal(expression.Value("word")).And(expression.Key("score").GreaterThan(expression.Value(10))),
+		FilterCondition: expression.Name("name").NotEqual(expression.Value("name-4")),
+		Limit:           2,
+		ScanForward:     false,
+	})
+	require.NoError(t, err)
+
+	var names []string
+	for {
+		out, err := db.Query(ctx, testTable, input)
+		require.NoError(t, err)
+		for _, item := range out.Items {
+			names = append(names, item["name"].(*types.AttributeValueMemberS).Value)
+			assert.NotContains(t, item, "extra")
+		}
+		if out.LastEvaluatedKey == nil {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+	assert.Equal(t, []string{"name-5", "name-3", "name-2"}, names)
+
+	input, err = db.BuildQueryInput(QueryInput{
+		IndexName:    "KeysByScore",
+		KeyCondition: expression.Key("kind").Equal(expression.Value("word")),
+		ScanForward:  true,
+	})
+	require.NoError(t, err)
+	out, err := db.Query(ctx, testTable, input)
+	require.NoError(t, err)
+	require.Len(t, out.Items, 5)
+	assert.Len(t, out.Items[0], 4)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "id-1"}, out.Items[0]["id"])
+}
+
+func TestMemoryDynamoScan(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDynamo(t)
+
+	var (
+		ids   []string
+		input = db.BuildScanInput(testTable, 2, nil)
+	)
+	for {
+		out, err := db.Scan(ctx, testTable, input)
+		require.NoError(t, err)
+		for _, item := range out.Items {
+			ids = append(ids, item["id"].(*types.AttributeValueMemberS).Value)
+		}
+		if out.LastEvaluatedKey == nil {
+			break
+		}
+		input = db.BuildScanInput(testTable, 2, out.LastEvaluatedKey)
+	}
+	assert.Equal(t, []string{"id-1", "id-2", "id-3", "id-4", "id-5"}, ids)
+
+	name, err := db.GetRandomField(ctx, testTable, "name")
+	require.NoError(t, err)
+	assert.Contains(t, []string{"name-1", "name-2", "name-3", "name-4", "name-5"}, name)
+
+	exists, err := db.Exists(ctx, testTable, testKey("id-3"))
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, db.Delete(ctx, testTable, testKey("id-3")))
+	exists, err = db.Exists(ctx, testTable, testKey("id-3"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
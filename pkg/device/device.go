@@ -0,0 +1,54 @@
+// Package device looks up the devices registered to a profile. A profile without active devices is
+// still used by clients signing with the shared token, so the first device of a profile registers
+// without credentials and these clients reach the profile until it does.
+package device
+
+import (
+	"context"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodevice"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+)
+
+// Registered reports whether the profile has a device which is not revoked.
+func Registered(ctx context.Context, dynamo cloud.DynamoAPI, profileID string) (bool, error) {
+	queryInput, err := dynamo.BuildQueryInput(cloud.QueryInput{
+		IndexName:    applingodevice.IndexProfileIndex,
+		KeyCondition: expression.Key(applingodevice.ColumnProfileId).Equal(expression.Value(profileID)),
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to build devices query")
+	}
+	result, err := dynamo.Query(ctx, applingodevice.TableSchema.TableName, queryInput)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to query devices")
+	}
+
+	// devices registered before the link used the profile id as the device id.
+	legacy, err := dynamo.Get(ctx, applingodevice.TableSchema.TableName, map[string]types.AttributeValue{
+		applingodevice.ColumnId: &types.AttributeValueMemberS{Value: profileID},
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get device")
+	}
+	items := result.Items
+	if legacy.Item != nil {
+		items = append(items, legacy.Item)
+	}
+
+	var devices []applingodevice.SchemaItem
+	if err := attributevalue.UnmarshalListOfMaps(items, &devices); err != nil {
+		return false, errors.Wrap(err, "failed to unmarshal devices")
+	}
+	for _, device := range devices {
+		if !applingodevice.IntToBool(device.IsRevoked) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
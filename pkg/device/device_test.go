@@ -0,0 +1,44 @@
+package device
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodevice"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistered(t *testing.T) {
+	ctx := context.Background()
+	db := cloud.NewMemoryDynamo(cloud.NewMemoryTable(applingodevice.TableSchema))
+	put := func(item applingodevice.SchemaItem) {
+		t.Helper()
+		av, err := applingodevice.PutItem(item)
+		require.NoError(t, err)
+		require.NoError(t, db.Put(ctx, applingodevice.TableSchema.TableName, av, expression.ConditionBuilder{}))
+	}
+
+	registered, err := Registered(ctx, db, "profile-1")
+	require.NoError(t, err)
+	assert.False(t, registered)
+
+	put(applingodevice.SchemaItem{Id: "device-1", ProfileId: "profile-1", IsRevoked: applingodevice.BoolToInt(true)})
+	registered, err = Registered(ctx, db, "profile-1")
+	require.NoError(t, err)
+	assert.False(t, registered, "revoked devices do not count")
+
+	put(applingodevice.SchemaItem{Id: "device-2", ProfileId: "profile-1"})
+	registered, err = Registered(ctx, db, "profile-1")
+	require.NoError(t, err)
+	assert.True(t, registered)
+
+	// a legacy device has the id of its profile.
+	put(applingodevice.SchemaItem{Id: "profile-2"})
+	registered, err = Registered(ctx, db, "profile-2")
+	require.NoError(t, err)
+	assert.True(t, registered)
+}
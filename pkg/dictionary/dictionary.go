@@ -0,0 +1,189 @@
+// Package dictionary validates dictionary files before they are published.
+// A file is the JSON of forge.WordsContainer, it is checked for the shape, the number of words,
+// duplicates, empty and oversized fields and the encoding. It is shared by the validator
+// triggered by uploads and by the processing pipeline which publishes dictionaries.
+package dictionary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/forge"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MaxFileSize is the largest accepted file in bytes.
+	MaxFileSize = 1 << 20
+
+	// MinWords and MaxWords bound the number of words in a file.
+	MinWords = 1
+	MaxWords = 1000
+
+	// Field length caps in runes.
+	MaxWordLength        = 100
+	MaxTranslationLength = 100
+	MaxDescriptionLength = 500
+	MaxHintLength        = 200
+
+	// RejectedPrefix starts the reason of every rejected file.
+	RejectedPrefix = "file rejected: "
+
+	// maxReasonProblems bounds the problems listed by Report.Reason.
+	maxReasonProblems = 5
+)
+
+// utf8BOM is rejected at the start of a file, clients send plain UTF-8.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Problem is a single reason to reject a file. Word is the 1-based position
+// of the word in the file, 0 for problems of the whole file.
+type Problem struct {
+	Word    int
+	Message string
+}
+
+// String returns the problem prefixed with the word position.
+func (p Problem) String() string {
+	if p.Word == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("word %d: %s", p.Word, p.Message)
+}
+
+// Report is the result of a file validation.
+type Report struct {
+	// Words is the number of words in the file, 0 if the file cannot be decoded.
+	Words int
+	// Problems lists every reason to reject the file.
+	Problems []Problem
+}
+
+// Valid reports whether the file can be published.
+func (r Report) Valid() bool {
+	return len(r.Problems) == 0
+}
+
+// Reason returns a short description of the result for the processing table.
+func (r Report) Reason() string {
+	if r.Valid() {
+		return fmt.Sprintf("file validated: %d words", r.Words)
+	}
+
+	problems := make([]string, 0, min(len(r.Problems), maxReasonProblems))
+	for _, p := range r.Problems[:min(len(r.Problems), maxReasonProblems)] {
+		problems = append(problems, p.String())
+	}
+	reason := RejectedPrefix + strings.Join(problems, "; ")
+	if more := len(r.Problems) - maxReasonProblems; more > 0 {
+		reason += fmt.Sprintf(" (and %d more)", more)
+	}
+	return reason
+}
+
+func (r *Report) add(word int, format string, args ...any) {
+	r.Problems = append(r.Problems, Problem{Word: word, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks the content of a dictionary file.
+func Validate(data []byte) Report {
+	var report Report
+	switch {
+	case len(data) > MaxFileSize:
+		report.add(0, "file exceeds %d bytes", MaxFileSize)
+		return report
+	case !utf8.Valid(data):
+		report.add(0, "file is not valid UTF-8")
+		return report
+	case bytes.HasPrefix(data, utf8BOM):
+		report.add(0, "file starts with a byte order mark")
+		return report
+	}
+
+	// unknown fields and trailing data mean the file is not a words container.
+	var container forge.WordsContainer
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&container); err != nil {
+		report.add(0, "invalid format: %s", err)
+		return report
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		report.add(0, "invalid format: unexpected data after the words")
+		return report
+	}
+	if container.Words == nil {
+		report.add(0, "words are missing")
+		return report
+	}
+
+	report.Words = len(container.Words)
+	if report.Words < MinWords || report.Words > MaxWords {
+		report.add(0, "words count must be between %d and %d, got %d", MinWords, MaxWords, report.Words)
+	}
+
+	seen := make(map[string]int, len(container.Words))
+	for i, word := range container.Words {
+		position := i + 1
+
+		checkField(&report, position, "word", word.Word, MaxWordLength, true)
+		checkField(&report, position, "translation", word.Translation, MaxTranslationLength, true)
+		checkField(&report, position, "description", word.Description, MaxDescriptionLength, false)
+		checkField(&report, position, "hint", word.Hint, MaxHintLength, false)
+
+		key := normalize(word.Word)
+		if key == "" {
+			continue
+		}
+		if first, ok := seen[key]; ok {
+			report.add(position, "duplicate of word %d", first)
+			continue
+		}
+		seen[key] = position
+	}
+	return report
+}
+
+// ValidateObject reads the file from the bucket and validates it.
+// It returns cloud.ErrBucketObjectNotFound if the file does not exist.
+func ValidateObject(ctx context.Context, s3Bucket cloud.BucketAPI, key, bucket string) (Report, error) {
+	body, err := s3Bucket.Get(ctx, key, bucket)
+	if err != nil {
+		return Report{}, err
+	}
+	defer body.Close()
+
+	// one byte over the limit is enough to reject the file without reading all of it.
+	data, err := io.ReadAll(io.LimitReader(body, MaxFileSize+1))
+	if err != nil {
+		return Report{}, errors.Wrap(err, "failed to read dictionary file")
+	}
+	return Validate(data), nil
+}
+
+// checkField reports an empty, oversized or control character containing field.
+func checkField(report *Report, position int, name, value string, maxLength int, required bool) {
+	switch {
+	case strings.TrimSpace(value) == "":
+		if required {
+			report.add(position, "empty %s", name)
+		}
+	case utf8.RuneCountInString(value) > maxLength:
+		report.add(position, "%s is longer than %d characters", name, maxLength)
+	case strings.ContainsFunc(value, unicode.IsControl):
+		report.add(position, "%s contains control characters", name)
+	}
+}
+
+// normalize returns the form of the word duplicates are compared by.
+func normalize(word string) string {
+	return strings.ToLower(strings.Join(strings.Fields(word), " "))
+}
@@ -0,0 +1,83 @@
+package dictionary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func words(entries ...string) string {
+	return `{"words":[` + strings.Join(entries, ",") + `]}`
+}
+
+func word(w, translation string) string {
+	return fmt.Sprintf(`{"word":%q,"translation":%q,"description":"","hint":""}`, w, translation)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		words    int
+		problems []string
+	}{
+		{"valid", words(word("cat", "кот"), word("dog", "собака")), 2, nil},
+		{"not json", `words`, 0, []string{"invalid format"}},
+		{"unknown field", `{"words":[],"meta":{}}`, 0, []string{"invalid format"}},
+		{"trailing data", words(word("cat", "кот")) + `{}`, 0, []string{"unexpected data"}},
+		{"missing words", `{}`, 0, []string{"words are missing"}},
+		{"no words", words(), 0, []string{"words count must be between"}},
+		{"empty translation", words(word("cat", " ")), 1, []string{"word 1: empty translation"}},
+		{"empty word", words(word("", "кот")), 1, []string{"word 1: empty word"}},
+		{"duplicate", words(word("cat", "кот"), word("dog", "собака"), word(" Cat ", "кошка")), 3, []string{"word 3: duplicate of word 1"}},
+		{"long word", words(word(strings.Repeat("я", MaxWordLength+1), "кот")), 1, []string{"word 1: word is longer than"}},
+		{"control characters", words(`{"word":"c\u0007at","translation":"кот"}`), 1, []string{"word 1: word contains control characters"}},
+		{"invalid utf8", "{\"words\":[{\"word\":\"\xff\"}]}", 0, []string{"not valid UTF-8"}},
+		{"byte order mark", "\xEF\xBB\xBF" + words(word("cat", "кот")), 0, []string{"byte order mark"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Validate([]byte(tt.data))
+			assert.Equal(t, tt.words, report.Words)
+			require.Len(t, report.Problems, len(tt.problems), report.Reason())
+			for i, problem := range tt.problems {
+				assert.Contains(t, report.Problems[i].String(), problem)
+			}
+			assert.Equal(t, len(tt.problems) == 0, report.Valid())
+		})
+	}
+}
+
+func TestReportReason(t *testing.T) {
+	entries := make([]string, 0, 8)
+	for range 8 {
+		entries = append(entries, word("cat", ""))
+	}
+	report := Validate([]byte(words(entries...)))
+
+	assert.False(t, report.Valid())
+	assert.True(t, strings.HasPrefix(report.Reason(), "file rejected: word 1: empty translation; "))
+	assert.True(t, strings.HasSuffix(report.Reason(), "(and 10 more)"), report.Reason())
+	assert.Equal(t, "file validated: 1 words", Validate([]byte(words(word("cat", "кот")))).Reason())
+}
+
+func TestValidateObject(t *testing.T) {
+	ctx := context.Background()
+	bucket := cloud.NewMemoryBucket()
+
+	_, err := ValidateObject(ctx, bucket, "missing.json", "processing")
+	assert.ErrorIs(t, err, cloud.ErrBucketObjectNotFound)
+
+	large := strings.Repeat(" ", MaxFileSize) + words(word("cat", "кот"))
+	require.NoError(t, bucket.Put(ctx, "large.json", "processing", strings.NewReader(large), cloud.ContentTypeJSON))
+	report, err := ValidateObject(ctx, bucket, "large.json", "processing")
+	require.NoError(t, err)
+	require.Len(t, report.Problems, 1)
+	assert.Contains(t, report.Problems[0].Message, "file exceeds")
+}
@@ -110,7 +110,7 @@ func (r *DictionaryCheckData) Setup(
 	ctx context.Context,
 	req *RequestDictionaryCheck,
 	item *applingoprocessing.SchemaItem,
-	s3cli *cloud.Bucket,
+	s3cli cloud.BucketAPI,
 	promptBucketName string,
 	dictionaryBucketName string,
 ) error {
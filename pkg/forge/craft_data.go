@@ -169,7 +169,7 @@ func (r *DictionaryCraftData) toPromptTemplate() craftDictionaryPromptTemplate {
 func (r *DictionaryCraftData) Setup(
 	ctx context.Context,
 	req *RequestDictionaryCraft,
-	s3cli *cloud.Bucket,
+	s3cli cloud.BucketAPI,
 	promptBucketName string,
 ) error {
 	r.request = req.Clone()
@@ -225,7 +225,7 @@ func (r *DictionaryCraftData) Setup(
 	}
 }
 
-func (r *DictionaryCraftData) setupOpenAI(ctx context.Context, req *RequestDictionaryCraft, s3cli *cloud.Bucket, bucket string) error {
+func (r *DictionaryCraftData) setupOpenAI(ctx context.Context, req *RequestDictionaryCraft, s3cli cloud.BucketAPI, bucket string) error {
 	if req.PromptName == nil {
 		prompt, err := s3cli.GetRandomKey(ctx, bucket, craftPromptPrefix)
 		if err != nil {
@@ -57,7 +57,7 @@ func Check(
 	promptBucket string,
 	processingBucket string,
 	chatgptCli *chatgpt.Client,
-	s3Cli *cloud.Bucket,
+	s3Cli cloud.BucketAPI,
 ) (*DictionaryCheckData, error) {
 	data := NewDictionaryCheckData()
 	if err := data.Setup(ctx, req, item, s3Cli, promptBucket, processingBucket); err != nil {
@@ -112,7 +112,7 @@ func Check(
 // Returns:
 //   - *DictionaryCraftData: Full dictionary object.
 //   - error: An error if any step of the process fails.
-func Craft(ctx context.Context, req *RequestDictionaryCraft, promptBucket string, chatgptCli *chatgpt.Client, s3Cli *cloud.Bucket) (*DictionaryCraftData, error) {
+func Craft(ctx context.Context, req *RequestDictionaryCraft, promptBucket string, chatgptCli *chatgpt.Client, s3Cli cloud.BucketAPI) (*DictionaryCraftData, error) {
 	data := NewDictionaryCraftData()
 	if err := data.Setup(ctx, req, s3Cli, promptBucket); err != nil {
 		return nil, errors.Join(ErrorForgeDictionaryCraft, err)
@@ -166,7 +166,7 @@ func Craft(ctx context.Context, req *RequestDictionaryCraft, promptBucket string
 // Returns:
 //   - []*DictionaryCraftData: A slice of successful dictionary generation objects.
 //   - []error: A slice of errors encountered during processing.
-func CraftMultiple(ctx context.Context, req *RequestDictionaryCraft, promptBucket string, chatgptCli *chatgpt.Client, s3Cli *cloud.Bucket) ([]*DictionaryCraftData, []error) {
+func CraftMultiple(ctx context.Context, req *RequestDictionaryCraft, promptBucket string, chatgptCli *chatgpt.Client, s3Cli cloud.BucketAPI) ([]*DictionaryCraftData, []error) {
 	var dictionariesCount, maxConcurrent int
 	if req == nil {
 		req = NewDictionaryCraftRequest()
@@ -267,7 +267,7 @@ func CraftMultiple(ctx context.Context, req *RequestDictionaryCraft, promptBucke
 // LoadResponseDictionaryCraft loads and unmarshals a ResponseDictionaryCraft from S3.
 // It retrieves the object using the provided key and bucket, reads the JSON content,
 // and deserializes it into a ResponseDictionaryCraft structure.
-func LoadResponseDictionaryCraft(ctx context.Context, s3cli *cloud.Bucket, key, bucket string) (*ResponseDictionaryCraft, error) {
+func LoadResponseDictionaryCraft(ctx context.Context, s3cli cloud.BucketAPI, key, bucket string) (*ResponseDictionaryCraft, error) {
 	rc, err := s3cli.GetObjectBody(ctx, key, bucket)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get dictionary file %q from bucket %q: %w", key, bucket, err)
@@ -0,0 +1,264 @@
+// Package leaderboard ranks profiles by the XP of their ledger events. Every board is a partition
+// of the leaderboard table with an entry per profile, the XP of an event is added to the overall
+// and the language pair boards of all time and of the week it was awarded in. Boards are read
+// through an index ordered by XP, so the top of a board and the rank of a profile are plain queries.
+package leaderboard
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoleaderboard"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoledger"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/ledger"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+)
+
+// Periods of the boards.
+const (
+	// PeriodWeekly boards count the XP of an ISO week, a new board starts every Monday 00:00 UTC.
+	PeriodWeekly = "weekly"
+	// PeriodAllTime boards count all XP of the profiles.
+	PeriodAllTime = "all_time"
+)
+
+const (
+	// MaxRank is the last rank which is counted, profiles below it are reported without a rank.
+	MaxRank = 1000
+	// MaxTop caps the entries returned from the top of a board.
+	MaxTop = 100
+
+	boardSeparator = "#"
+	playerLength   = 16
+	queryLimit     = 100
+)
+
+// Entry is the XP of a profile on a board.
+type Entry struct {
+	ProfileID string
+	XP        int
+	Rank      int
+}
+
+// Week returns the ISO week of the time, e.g. 2026-W07.
+func Week(t time.Time) string {
+	year, week := t.UTC().ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", year, week)
+}
+
+// Board returns the name of the board of the period, the weekly board is the one of the week of at.
+// An empty subcategory selects the overall board.
+func Board(period string, at time.Time, subcategory string) string {
+	board := PeriodAllTime
+	if period == PeriodWeekly {
+		board = PeriodWeekly + boardSeparator + Week(at)
+	}
+	if subcategory != "" {
+		board += boardSeparator + subcategory
+	}
+	return board
+}
+
+// Boards returns the boards the ledger event counts in. The progress kept before the ledger
+// has no week and no language pair, so it counts only in the overall all time board.
+func Boards(event applingoledger.SchemaItem) []string {
+	if event.Kind == ledger.TypeLegacy {
+		return []string{Board(PeriodAllTime, time.Time{}, "")}
+	}
+	awarded := time.Unix(int64(event.Created), 0)
+	boards := []string{Board(PeriodAllTime, awarded, ""), Board(PeriodWeekly, awarded, "")}
+	if event.Subcategory != "" {
+		boards = append(boards, Board(PeriodAllTime, awarded, event.Subcategory), Board(PeriodWeekly, awarded, event.Subcategory))
+	}
+	return boards
+}
+
+// Add adds the XP of the ledger event to its boards. The event is marked as ranked in the same
+// transaction, so a redelivered event is not counted twice, false is returned for it.
+func Add(ctx context.Context, dynamo cloud.DynamoAPI, event applingoledger.SchemaItem, now time.Time) (bool, error) {
+	if event.Xp <= 0 {
+		return false, nil
+	}
+	ranked := expression.Set(expression.Name(applingoledger.ColumnRanked), expression.Value(1))
+	writes := []cloud.TransactWriteItem{{
+		Table: applingoledger.TableSchema.TableName,
+		Key: map[string]types.AttributeValue{
+			applingoledger.ColumnProfileId: &types.AttributeValueMemberS{Value: event.ProfileId},
+			applingoledger.ColumnId:        &types.AttributeValueMemberS{Value: event.Id},
+		},
+		Update: &ranked,
+		Condition: expression.AttributeExists(expression.Name(applingoledger.ColumnId)).And(
+			expression.Or(
+				expression.AttributeNotExists(expression.Name(applingoledger.ColumnRanked)),
+				expression.Name(applingoledger.ColumnRanked).Equal(expression.Value(0)),
+			),
+		),
+	}}
+
+	week := Week(time.Unix(int64(event.Created), 0))
+	for _, board := range Boards(event) {
+		update := expression.
+			Add(expression.Name(applingoleaderboard.ColumnXp), expression.Value(event.Xp)).
+			Set(expression.Name(applingoleaderboard.ColumnUpdated), expression.Value(now.Unix()))
+		if isWeekly(board) {
+			// only weekly entries have the week, so they alone are in the index the boards are purged by.
+			update = update.Set(expression.Name(applingoleaderboard.ColumnWeek), expression.Value(week))
+		}
+		writes = append(writes, cloud.TransactWriteItem{
+			Table:  applingoleaderboard.TableSchema.TableName,
+			Key:    entryKey(board, event.ProfileId),
+			Update: &update,
+		})
+	}
+
+	if err := dynamo.TransactWrite(ctx, writes); err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed to add event to leaderboards")
+	}
+	return true, nil
+}
+
+// Top returns the entries with the most XP on the board, ranked by XP. Profiles with equal XP share the rank.
+func Top(ctx context.Context, dynamo cloud.DynamoAPI, board string, limit int) ([]Entry, error) {
+	limit = max(1, min(limit, MaxTop))
+	input, err := dynamo.BuildQueryInput(cloud.QueryInput{
+		IndexName:    applingoleaderboard.IndexBoardByXpIndex,
+		KeyCondition: expression.Key(applingoleaderboard.ColumnBoard).Equal(expression.Value(board)),
+		Limit:        int32(limit),
+		ScanForward:  false,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, err := dynamo.Query(ctx, applingoleaderboard.TableSchema.TableName, input)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query leaderboard")
+	}
+	var items []applingoleaderboard.SchemaItem
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal leaderboard")
+	}
+
+	entries := make([]Entry, 0, len(items))
+	for i, item := range items {
+		entry := Entry{ProfileID: item.ProfileId, XP: item.Xp, Rank: i + 1}
+		if i > 0 && entries[i-1].XP == item.Xp {
+			entry.Rank = entries[i-1].Rank
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Get returns the entry of the profile on the board without its rank, false if the profile has no XP there.
+func Get(ctx context.Context, dynamo cloud.DynamoAPI, board, profileID string) (Entry, bool, error) {
+	out, err := dynamo.Get(ctx, applingoleaderboard.TableSchema.TableName, entryKey(board, profileID))
+	if err != nil {
+		return Entry{}, false, errors.Wrap(err, "failed to get leaderboard entry")
+	}
+	if out == nil || len(out.Item) == 0 {
+		return Entry{}, false, nil
+	}
+	var item applingoleaderboard.SchemaItem
+	if err := attributevalue.UnmarshalMap(out.Item, &item); err != nil {
+		return Entry{}, false, errors.Wrap(err, "failed to unmarshal leaderboard entry")
+	}
+	return Entry{ProfileID: item.ProfileId, XP: item.Xp}, true, nil
+}
+
+// Rank returns the rank of the XP on the board, which is one more than the entries with more XP.
+// Only the entries above MaxRank are counted, 0 is returned for the XP below it.
+func Rank(ctx context.Context, dynamo cloud.DynamoAPI, board string, xp int) (int, error) {
+	var (
+		above    int
+		startKey map[string]types.AttributeValue
+	)
+	for {
+		input, err := dynamo.BuildQueryInput(cloud.QueryInput{
+			IndexName: applingoleaderboard.IndexBoardByXpIndex,
+			KeyCondition: expression.Key(applingoleaderboard.ColumnBoard).Equal(expression.Value(board)).
+				And(expression.Key(applingoleaderboard.ColumnXp).GreaterThan(expression.Value(xp))),
+			Limit:             int32(min(queryLimit, MaxRank-above)),
+			ScanForward:       false,
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return 0, err
+		}
+		result, err := dynamo.Query(ctx, applingoleaderboard.TableSchema.TableName, input)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to query leaderboard")
+		}
+		above += len(result.Items)
+		if above >= MaxRank {
+			return 0, nil
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return above + 1, nil
+		}
+		startKey = result.LastEvaluatedKey
+	}
+}
+
+// Purge removes the entries of all weekly boards of the week and returns how many were removed.
+func Purge(ctx context.Context, dynamo cloud.DynamoAPI, week string) (int, error) {
+	var removed int
+	for {
+		// removed entries leave the index, so the first page always holds the remaining ones.
+		input, err := dynamo.BuildQueryInput(cloud.QueryInput{
+			IndexName:    applingoleaderboard.IndexWeekIndex,
+			KeyCondition: expression.Key(applingoleaderboard.ColumnWeek).Equal(expression.Value(week)),
+			Limit:        queryLimit,
+			ScanForward:  true,
+		})
+		if err != nil {
+			return removed, err
+		}
+		result, err := dynamo.Query(ctx, applingoleaderboard.TableSchema.TableName, input)
+		if err != nil {
+			return removed, errors.Wrap(err, "failed to query weekly leaderboards")
+		}
+		var items []applingoleaderboard.SchemaItem
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			return removed, errors.Wrap(err, "failed to unmarshal weekly leaderboards")
+		}
+		if len(items) == 0 {
+			return removed, nil
+		}
+		for _, item := range items {
+			if err := dynamo.Delete(ctx, applingoleaderboard.TableSchema.TableName, entryKey(item.Board, item.ProfileId)); err != nil {
+				return removed, errors.Wrap(err, "failed to delete leaderboard entry")
+			}
+			removed++
+		}
+	}
+}
+
+// Player returns the public name of the profile on the boards, profile ids are iCloud ids and are never shown.
+func Player(profileID string) string {
+	sum := sha256.Sum256([]byte(profileID))
+	return hex.EncodeToString(sum[:])[:playerLength]
+}
+
+func isWeekly(board string) bool {
+	return strings.HasPrefix(board, PeriodWeekly+boardSeparator)
+}
+
+func entryKey(board, profileID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		applingoleaderboard.ColumnBoard:     &types.AttributeValueMemberS{Value: board},
+		applingoleaderboard.ColumnProfileId: &types.AttributeValueMemberS{Value: profileID},
+	}
+}
@@ -0,0 +1,140 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoleaderboard"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoledger"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/ledger"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 2023-11-14 is a Tuesday of the week 2023-W46.
+var now = time.Unix(1_700_000_000, 0)
+
+func setupDynamo(t *testing.T) cloud.DynamoAPI {
+	t.Helper()
+
+	return cloud.NewMemoryDynamo(cloud.NewMemoryTable(applingoleaderboard.TableSchema), cloud.NewMemoryTable(applingoledger.TableSchema))
+}
+
+func addEvent(t *testing.T, db cloud.DynamoAPI, event applingoledger.SchemaItem) bool {
+	t.Helper()
+
+	ctx := context.Background()
+	av, err := applingoledger.PutItem(event)
+	require.NoError(t, err)
+	require.NoError(t, db.Put(ctx, applingoledger.TableSchema.TableName, av, expression.ConditionBuilder{}))
+
+	added, err := Add(ctx, db, event, now)
+	require.NoError(t, err)
+	return added
+}
+
+func TestBoards(t *testing.T) {
+	assert.Equal(t, "2023-W46", Week(now))
+	assert.Equal(t, "2024-W01", Week(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "all_time", Board(PeriodAllTime, now, ""))
+	assert.Equal(t, "weekly#2023-W46#en-ru", Board(PeriodWeekly, now, "en-ru"))
+
+	event := applingoledger.SchemaItem{Kind: ledger.TypeSessionCompleted, Created: int(now.Unix())}
+	assert.Equal(t, []string{"all_time", "weekly#2023-W46"}, Boards(event))
+
+	event.Subcategory = "en-ru"
+	assert.Equal(t, []string{"all_time", "weekly#2023-W46", "all_time#en-ru", "weekly#2023-W46#en-ru"}, Boards(event))
+
+	event.Kind = ledger.TypeLegacy
+	assert.Equal(t, []string{"all_time"}, Boards(event))
+}
+
+func TestAddAndRank(t *testing.T) {
+	ctx := context.Background()
+	db := setupDynamo(t)
+	created := int(now.Unix())
+
+	assert.True(t, addEvent(t, db, applingoledger.SchemaItem{ProfileId: "p1", Id: "a", Kind: ledger.TypeSessionCompleted, Xp: 10, Subcategory: "en-ru", Created: created}))
+	assert.True(t, addEvent(t, db, applingoledger.SchemaItem{ProfileId: "p1", Id: "b", Kind: ledger.TypeWordsReviewed, Xp: 30, Created: created}))
+	assert.True(t, addEvent(t, db, applingoledger.SchemaItem{ProfileId: "p2", Id: "a", Kind: ledger.TypeLegacy, Xp: 500, Created: created}))
+	assert.True(t, addEvent(t, db, applingoledger.SchemaItem{ProfileId: "p3", Id: "a", Kind: ledger.TypeWordsReviewed, Xp: 40, Subcategory: "en-ru", Created: created}))
+	assert.False(t, addEvent(t, db, applingoledger.SchemaItem{ProfileId: "p3", Id: "b", Kind: ledger.TypeWordsReviewed, Created: created}))
+
+	// a redelivered event is counted once.
+	added, err := Add(ctx, db, applingoledger.SchemaItem{ProfileId: "p1", Id: "a", Kind: ledger.TypeSessionCompleted, Xp: 10, Subcategory: "en-ru", Created: created}, now)
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	top, err := Top(ctx, db, Board(PeriodAllTime, now, ""), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{"p2", 500, 1}, {"p3", 40, 2}, {"p1", 40, 2}}, top)
+
+	top, err = Top(ctx, db, Board(PeriodWeekly, now, ""), 1)
+	require.NoError(t, err)
+	assert.Len(t, top, 1)
+	assert.Equal(t, 40, top[0].XP)
+
+	top, err = Top(ctx, db, Board(PeriodWeekly, now, "en-ru"), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{"p3", 40, 1}, {"p1", 10, 2}}, top)
+
+	entry, found, err := Get(ctx, db, Board(PeriodWeekly, now, "en-ru"), "p1")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, 10, entry.XP)
+	rank, err := Rank(ctx, db, Board(PeriodWeekly, now, "en-ru"), entry.XP)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rank)
+
+	_, found, err = Get(ctx, db, Board(PeriodWeekly, now, ""), "p2")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	removed, err := Purge(ctx, db, Week(now))
+	require.NoError(t, err)
+	assert.Equal(t, 4, removed)
+	top, err = Top(ctx, db, Board(PeriodWeekly, now, ""), 10)
+	require.NoError(t, err)
+	assert.Empty(t, top)
+	top, err = Top(ctx, db, Board(PeriodAllTime, now, ""), 10)
+	require.NoError(t, err)
+	assert.Len(t, top, 3)
+}
+
+func TestRankLimit(t *testing.T) {
+	ctx := context.Background()
+	db := setupDynamo(t)
+
+	board := Board(PeriodAllTime, now, "")
+	for i := range MaxRank + 1 {
+		require.NoError(t, db.Put(ctx, applingoleaderboard.TableSchema.TableName, map[string]types.AttributeValue{
+			applingoleaderboard.ColumnBoard:     &types.AttributeValueMemberS{Value: board},
+			applingoleaderboard.ColumnProfileId: &types.AttributeValueMemberS{Value: fmt.Sprintf("p%d", i)},
+			applingoleaderboard.ColumnXp:        &types.AttributeValueMemberN{Value: strconv.Itoa(i + 10)},
+		}, expression.ConditionBuilder{}))
+	}
+
+	rank, err := Rank(ctx, db, board, MaxRank+10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rank)
+	// 999 entries have more than 11 XP.
+	rank, err = Rank(ctx, db, board, 11)
+	require.NoError(t, err)
+	assert.Equal(t, MaxRank, rank)
+	rank, err = Rank(ctx, db, board, 5)
+	require.NoError(t, err)
+	assert.Zero(t, rank)
+}
+
+func TestPlayer(t *testing.T) {
+	assert.Len(t, Player("profile"), playerLength)
+	assert.Equal(t, Player("profile"), Player("profile"))
+	assert.NotEqual(t, Player("profile"), Player("other"))
+}
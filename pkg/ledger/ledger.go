@@ -0,0 +1,188 @@
+// Package ledger turns learning events reported by devices into XP. Events are kept in an
+// append-only table per profile together with the XP they were awarded, the level and the XP
+// of the profile are derived from the sum of the ledger and can be recomputed from it at any time.
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoledger"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+)
+
+// Event types.
+const (
+	// TypeSessionCompleted is reported when a learning session is finished.
+	TypeSessionCompleted = "session_completed"
+	// TypeWordsReviewed is reported for the words answered during a session.
+	TypeWordsReviewed = "words_reviewed"
+	// TypeDictionaryFinished is reported once all words of a dictionary are learned.
+	TypeDictionaryFinished = "dictionary_finished"
+	// TypeDictionaryStudied is recorded by the server when the first word of a dictionary is reviewed.
+	TypeDictionaryStudied = "dictionary_studied"
+	// TypeLegacy holds the progress a profile had before the ledger was introduced.
+	TypeLegacy = "legacy"
+)
+
+// Rules.
+const (
+	// SessionXP is awarded for a completed session.
+	SessionXP = 10
+	// WordXP is awarded for every reviewed word.
+	WordXP = 1
+	// DictionaryXP is awarded once per finished dictionary.
+	DictionaryXP = 50
+	// DailyLimit caps the XP a profile earns per day, events above it are kept with less or no XP.
+	DailyLimit = 1000
+
+	// MaxWords caps the words of one words_reviewed event.
+	MaxWords = 200
+	// MinSession and MaxSession bound the duration of a session which earns XP.
+	MinSession = time.Minute
+	MaxSession = 4 * time.Hour
+	// MaxAge is how long a device may keep an event before reporting it.
+	MaxAge = 7 * 24 * time.Hour
+	// MaxSkew tolerates device clocks which are ahead of the server.
+	MaxSkew = 5 * time.Minute
+
+	// LegacyID is the id of the event with the progress kept before the ledger.
+	LegacyID = "legacy"
+
+	queryLimit = 100
+)
+
+// ErrRejected is wrapped by the errors of events which earn no XP.
+var ErrRejected = errors.New("event rejected")
+
+// Event is a learning event reported by a device.
+type Event struct {
+	Type         string
+	Occurred     int64
+	Words        int
+	Duration     int
+	DictionaryID string
+}
+
+// Key returns the ledger id of the event. A dictionary is finished and studied only once,
+// so its events are keyed by the dictionary and repeated reports are ignored.
+func Key(id string, event Event) string {
+	switch event.Type {
+	case TypeDictionaryFinished, TypeDictionaryStudied:
+		return event.Type + "#" + event.DictionaryID
+	}
+	return id
+}
+
+// Award returns the XP earned by the event or an error wrapping ErrRejected with the reason.
+func Award(event Event, now time.Time) (int, error) {
+	occurred := time.Unix(event.Occurred, 0)
+	switch {
+	case occurred.After(now.Add(MaxSkew)):
+		return 0, rejected("event is in the future")
+	case occurred.Before(now.Add(-MaxAge)):
+		return 0, rejected("event is older than %s", MaxAge)
+	}
+
+	duration := time.Duration(event.Duration) * time.Second
+	switch event.Type {
+	case TypeSessionCompleted:
+		if duration < MinSession || duration > MaxSession {
+			return 0, rejected("session must last between %s and %s", MinSession, MaxSession)
+		}
+		return SessionXP, nil
+	case TypeWordsReviewed:
+		if event.Words < 1 || event.Words > MaxWords {
+			return 0, rejected("words must be between 1 and %d", MaxWords)
+		}
+		if event.Duration < event.Words {
+			return 0, rejected("%d words cannot be reviewed in %s", event.Words, duration)
+		}
+		return event.Words * WordXP, nil
+	case TypeDictionaryFinished:
+		if event.DictionaryID == "" {
+			return 0, rejected("dictionary is missing")
+		}
+		return DictionaryXP, nil
+	}
+	return 0, rejected("unknown event type %q", event.Type)
+}
+
+// Limit returns the part of xp which fits the daily limit after earnedToday.
+func Limit(xp, earnedToday int) int {
+	return max(0, min(xp, DailyLimit-earnedToday))
+}
+
+// Day returns the number of the day the daily limit is counted for.
+func Day(now time.Time) int {
+	return int(now.UTC().Unix() / int64(24*time.Hour/time.Second))
+}
+
+// LevelXP returns the XP needed to pass the level.
+func LevelXP(level int) int {
+	return 100 * level
+}
+
+// Level splits the total XP into the level, starting at 1, and the XP earned within it.
+func Level(total int) (level, xp int) {
+	level, xp = 1, max(total, 0)
+	for xp >= LevelXP(level) {
+		xp -= LevelXP(level)
+		level++
+	}
+	return level, xp
+}
+
+// Total returns the total XP of the level and the XP earned within it.
+func Total(level, xp int) int {
+	total := max(xp, 0)
+	for l := 1; l < level; l++ {
+		total += LevelXP(l)
+	}
+	return total
+}
+
+// Sum returns the XP of all events of the profile.
+func Sum(ctx context.Context, dynamo cloud.DynamoAPI, profileID string) (int, error) {
+	var (
+		total    int
+		startKey map[string]types.AttributeValue
+	)
+	for {
+		input, err := dynamo.BuildQueryInput(cloud.QueryInput{
+			KeyCondition:      expression.Key(applingoledger.ColumnProfileId).Equal(expression.Value(profileID)),
+			ProjectionFields:  []string{applingoledger.ColumnXp},
+			Limit:             queryLimit,
+			ScanForward:       true,
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return 0, err
+		}
+		result, err := dynamo.Query(ctx, applingoledger.TableSchema.TableName, input)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to query ledger")
+		}
+		var events []applingoledger.SchemaItem
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &events); err != nil {
+			return 0, errors.Wrap(err, "failed to unmarshal ledger")
+		}
+		for _, event := range events {
+			total += event.Xp
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return total, nil
+		}
+		startKey = result.LastEvaluatedKey
+	}
+}
+
+func rejected(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrRejected, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,106 @@
+package ledger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoledger"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAward(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	at := now.Unix() - 60
+
+	tests := []struct {
+		name   string
+		event  Event
+		xp     int
+		reason string
+	}{
+		{"session", Event{Type: TypeSessionCompleted, Occurred: at, Duration: 600}, SessionXP, ""},
+		{"short session", Event{Type: TypeSessionCompleted, Occurred: at, Duration: 10}, 0, "session must last"},
+		{"long session", Event{Type: TypeSessionCompleted, Occurred: at, Duration: 5 * 3600}, 0, "session must last"},
+		{"words", Event{Type: TypeWordsReviewed, Occurred: at, Words: 20, Duration: 120}, 20, ""},
+		{"too many words", Event{Type: TypeWordsReviewed, Occurred: at, Words: MaxWords + 1, Duration: 3600}, 0, "words must be between"},
+		{"words too fast", Event{Type: TypeWordsReviewed, Occurred: at, Words: 100, Duration: 30}, 0, "cannot be reviewed"},
+		{"dictionary", Event{Type: TypeDictionaryFinished, Occurred: at, DictionaryID: "dict"}, DictionaryXP, ""},
+		{"no dictionary", Event{Type: TypeDictionaryFinished, Occurred: at}, 0, "dictionary is missing"},
+		{"future", Event{Type: TypeSessionCompleted, Occurred: now.Add(time.Hour).Unix(), Duration: 600}, 0, "in the future"},
+		{"skewed clock", Event{Type: TypeSessionCompleted, Occurred: now.Add(time.Minute).Unix(), Duration: 600}, SessionXP, ""},
+		{"old", Event{Type: TypeSessionCompleted, Occurred: now.Add(-MaxAge - time.Second).Unix(), Duration: 600}, 0, "older than"},
+		{"unknown", Event{Type: TypeLegacy, Occurred: at}, 0, "unknown event type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xp, err := Award(tt.event, now)
+			assert.Equal(t, tt.xp, xp)
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrRejected)
+			assert.Contains(t, err.Error(), tt.reason)
+		})
+	}
+}
+
+func TestLimit(t *testing.T) {
+	assert.Equal(t, 50, Limit(50, 0))
+	assert.Equal(t, 20, Limit(50, DailyLimit-20))
+	assert.Equal(t, 0, Limit(50, DailyLimit))
+	assert.Equal(t, 0, Limit(50, DailyLimit+10))
+}
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		total, level, xp int
+	}{
+		{0, 1, 0},
+		{99, 1, 99},
+		{100, 2, 0},
+		{299, 2, 199},
+		{300, 3, 0},
+		{1050, 5, 50},
+	}
+	for _, tt := range tests {
+		level, xp := Level(tt.total)
+		assert.Equal(t, tt.level, level, tt.total)
+		assert.Equal(t, tt.xp, xp, tt.total)
+		assert.Equal(t, tt.total, Total(level, xp))
+	}
+	assert.Equal(t, 0, Total(0, 0))
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "event-1", Key("event-1", Event{Type: TypeSessionCompleted}))
+	assert.Equal(t, "dictionary_finished#dict", Key("event-1", Event{Type: TypeDictionaryFinished, DictionaryID: "dict"}))
+	assert.Equal(t, "dictionary_studied#dict", Key("", Event{Type: TypeDictionaryStudied, DictionaryID: "dict"}))
+}
+
+func TestSum(t *testing.T) {
+	ctx := context.Background()
+	db := cloud.NewMemoryDynamo(cloud.NewMemoryTable(applingoledger.TableSchema))
+	for i, item := range []applingoledger.SchemaItem{
+		{ProfileId: "profile-1", Id: "a", Xp: 10},
+		{ProfileId: "profile-1", Id: "b", Xp: 25},
+		{ProfileId: "profile-2", Id: "a", Xp: 100},
+	} {
+		av, err := applingoledger.PutItem(item)
+		require.NoError(t, err, i)
+		require.NoError(t, db.Put(ctx, applingoledger.TableSchema.TableName, av, expression.ConditionBuilder{}))
+	}
+
+	total, err := Sum(ctx, db, "profile-1")
+	require.NoError(t, err)
+	assert.Equal(t, 35, total)
+
+	total, err = Sum(ctx, db, "profile-3")
+	require.NoError(t, err)
+	assert.Equal(t, 0, total)
+}
@@ -0,0 +1,132 @@
+// Package search builds the inverted index entries for dictionaries and ranks search results.
+// It is shared by the trigger which maintains the index and by the API which queries it.
+package search
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Field weights applied to a token depending on where it was found.
+const (
+	WeightName        = 4
+	WeightTopic       = 3
+	WeightAuthor      = 2
+	WeightDescription = 1
+)
+
+const (
+	// MinTokenLength is the shortest token (in runes) which is indexed or searched.
+	MinTokenLength = 2
+
+	// MaxPrefixLength is the longest prefix (in runes) stored in the index.
+	// Longer query terms are matched exactly only.
+	MaxPrefixLength = 20
+
+	// RatingBoost and DownloadsBoost blend popularity into the relevance.
+	RatingBoost    = 0.1
+	DownloadsBoost = 0.05
+)
+
+// Document is the searchable part of a dictionary.
+type Document struct {
+	Name        string
+	Description string
+	Topic       string
+	Author      string
+}
+
+// Posting is the index entry of a single token for a single dictionary.
+// Weight is the sum of field weights where the token is a whole word,
+// PrefixWeight is the sum of field weights where the token starts a word.
+type Posting struct {
+	Weight       int
+	PrefixWeight int
+}
+
+// Tokenize splits text into lowercase words of letters and digits.
+// Words shorter than MinTokenLength are dropped, duplicates are removed and order is kept.
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var (
+		tokens = make([]string, 0, len(words))
+		seen   = make(map[string]struct{}, len(words))
+	)
+	for _, word := range words {
+		if len([]rune(word)) < MinTokenLength {
+			continue
+		}
+		if _, ok := seen[word]; ok {
+			continue
+		}
+		seen[word] = struct{}{}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+// Prefixes returns the prefixes of token from MinTokenLength up to MaxPrefixLength runes,
+// including the token itself when it is short enough.
+func Prefixes(token string) []string {
+	runes := []rune(token)
+	limit := min(len(runes), MaxPrefixLength)
+
+	prefixes := make([]string, 0, max(limit-MinTokenLength+1, 0))
+	for l := MinTokenLength; l <= limit; l++ {
+		prefixes = append(prefixes, string(runes[:l]))
+	}
+	return prefixes
+}
+
+// Index returns the postings of every term of the document.
+func Index(doc Document) map[string]Posting {
+	postings := make(map[string]Posting)
+
+	for _, field := range []struct {
+		text   string
+		weight int
+	}{
+		{doc.Name, WeightName},
+		{doc.Topic, WeightTopic},
+		{doc.Author, WeightAuthor},
+		{doc.Description, WeightDescription},
+	} {
+		prefixes := make(map[string]struct{})
+		for _, token := range Tokenize(field.text) {
+			p := postings[token]
+			p.Weight += field.weight
+			postings[token] = p
+
+			for _, prefix := range Prefixes(token) {
+				prefixes[prefix] = struct{}{}
+			}
+		}
+		for prefix := range prefixes {
+			p := postings[prefix]
+			p.PrefixWeight += field.weight
+			postings[prefix] = p
+		}
+	}
+	return postings
+}
+
+// Relevance returns the contribution of a posting to the document relevance.
+// Whole word matches count fully, prefix only matches count half and are used
+// only when prefix is set, which is the case for the last term of a query.
+func Relevance(p Posting, prefix bool) float64 {
+	if !prefix {
+		return float64(p.Weight)
+	}
+	return float64(p.Weight) + float64(max(p.PrefixWeight-p.Weight, 0))/2
+}
+
+// Score blends the relevance with the dictionary rating and downloads.
+func Score(relevance float64, rating, downloads int) float64 {
+	return relevance * (1 +
+		RatingBoost*math.Log1p(float64(max(rating, 0))) +
+		DownloadsBoost*math.Log1p(float64(max(downloads, 0))))
+}
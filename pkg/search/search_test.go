@@ -0,0 +1,47 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"wild", "animals", "2nd", "édition", "животные"}, Tokenize("Wild animals, a 2nd  Édition: животные! wild"))
+	assert.Empty(t, Tokenize("a - b"))
+}
+
+func TestPrefixes(t *testing.T) {
+	assert.Equal(t, []string{"an", "ani", "anim"}, Prefixes("anim"))
+	assert.Empty(t, Prefixes("a"))
+	assert.Len(t, Prefixes("internationalizationally"), MaxPrefixLength-MinTokenLength+1)
+}
+
+func TestIndex(t *testing.T) {
+	postings := Index(Document{
+		Name:        "Animals",
+		Topic:       "animal world",
+		Description: "animals and animals",
+	})
+
+	assert.Equal(t, Posting{Weight: WeightName + WeightDescription, PrefixWeight: WeightName + WeightDescription}, postings["animals"])
+	assert.Equal(t, Posting{Weight: WeightTopic, PrefixWeight: WeightName + WeightTopic + WeightDescription}, postings["animal"])
+	assert.Equal(t, Posting{PrefixWeight: WeightName + WeightTopic + WeightDescription}, postings["an"])
+	assert.Equal(t, Posting{Weight: WeightDescription, PrefixWeight: WeightDescription}, postings["and"])
+}
+
+func TestRelevance(t *testing.T) {
+	whole := Posting{Weight: WeightName, PrefixWeight: WeightName}
+	prefixOnly := Posting{PrefixWeight: WeightName}
+
+	assert.Equal(t, float64(WeightName), Relevance(whole, false))
+	assert.Zero(t, Relevance(prefixOnly, false))
+	assert.Greater(t, Relevance(whole, true), Relevance(prefixOnly, true))
+}
+
+func TestScore(t *testing.T) {
+	assert.Equal(t, 2.0, Score(2, 0, 0))
+	assert.Equal(t, 2.0, Score(2, -5, 0))
+	assert.Greater(t, Score(2, 10, 0), Score(2, 0, 0))
+	assert.Greater(t, Score(4, 0, 0), Score(2, 10, 100))
+}
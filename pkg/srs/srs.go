@@ -0,0 +1,152 @@
+// Package srs schedules the reviews of dictionary words with a variant of the SM-2 algorithm.
+// Every answer is graded from Again to Easy, the grade moves the ease of the card and the ease
+// stretches the interval to the next review. Scheduling runs on the server, so every device
+// gets the same due dates for the same answers.
+package srs
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Grade of an answer to a card.
+type Grade int
+
+const (
+	// Again means the word was forgotten, the card is relearned.
+	Again Grade = iota + 1
+	// Hard means the word was recalled with serious difficulty.
+	Hard
+	// Good means the word was recalled after some hesitation.
+	Good
+	// Easy means the word was recalled at once.
+	Easy
+)
+
+const (
+	// DefaultEase is the ease of a new card in permille, 2500 stretches intervals 2.5 times.
+	DefaultEase = 2500
+	// MinEase keeps intervals of often forgotten cards growing.
+	MinEase = 1300
+	// MaxInterval caps the interval in days.
+	MaxInterval = 36500
+	// RelearnDelay is the time after which a forgotten card is due again.
+	RelearnDelay = 10 * time.Minute
+
+	day = 24 * time.Hour
+
+	againEase   = -200
+	hardEase    = -150
+	easyEase    = 150
+	hardFactor  = 1.2
+	easyFactor  = 1.3
+	firstGood   = 1
+	secondGood  = 6
+	firstEasy   = 4
+	relearnDays = 0
+)
+
+var (
+	// ErrInvalidGrade is returned for grades out of Again..Easy.
+	ErrInvalidGrade = errors.New("grade must be between 1 and 4")
+	// ErrOutOfOrder is returned for a review made not after the last review of the card,
+	// so a replayed review never counts twice.
+	ErrOutOfOrder = errors.New("review is not after the last review of the card")
+)
+
+// Valid reports whether the grade is one of Again, Hard, Good or Easy.
+func (g Grade) Valid() bool {
+	return g >= Again && g <= Easy
+}
+
+// Card is the review state of a word.
+type Card struct {
+	// Ease in permille, see DefaultEase.
+	Ease int
+	// Interval to the next review in days, 0 while the card is relearned.
+	Interval int
+	// Reps counts the successful reviews since the card was last forgotten.
+	Reps int
+	// Lapses counts how many times the learned card was forgotten.
+	Lapses int
+	// Due is the unix time the card should be reviewed at.
+	Due int64
+	// Reviewed is the unix time of the last review, 0 for a new card.
+	Reviewed int64
+}
+
+// NewCard returns the state of a word that was never reviewed.
+func NewCard() Card {
+	return Card{Ease: DefaultEase}
+}
+
+// IsNew reports whether the card was never reviewed.
+func (c Card) IsNew() bool {
+	return c.Reviewed == 0
+}
+
+// Review returns the card state after the answer graded at the reviewed time.
+func (c Card) Review(grade Grade, reviewed time.Time) (Card, error) {
+	if !grade.Valid() {
+		return c, ErrInvalidGrade
+	}
+	if !c.IsNew() && reviewed.Unix() <= c.Reviewed {
+		return c, ErrOutOfOrder
+	}
+	if c.Ease == 0 {
+		c.Ease = DefaultEase
+	}
+
+	switch grade {
+	case Again:
+		if c.Reps > 0 {
+			c.Lapses++
+		}
+		c.Reps = 0
+		c.Ease += againEase
+		c.Interval = relearnDays
+	case Hard:
+		c.Ease += hardEase
+		c.Interval = c.next(hardFactor * float64(c.Interval))
+		c.Reps++
+	case Good:
+		c.Interval = c.good()
+		c.Reps++
+	case Easy:
+		c.Ease += easyEase
+		if c.Reps == 0 {
+			c.Interval = firstEasy
+		} else {
+			c.Interval = max(c.good()+1, c.next(easyFactor*float64(c.good())))
+		}
+		c.Reps++
+	}
+	c.Ease = max(c.Ease, MinEase)
+	c.Interval = min(c.Interval, MaxInterval)
+
+	c.Reviewed = reviewed.Unix()
+	if c.Interval == 0 {
+		c.Due = reviewed.Add(RelearnDelay).Unix()
+	} else {
+		c.Due = reviewed.Add(time.Duration(c.Interval) * day).Unix()
+	}
+	return c, nil
+}
+
+// good returns the interval of a Good answer: 1 and 6 days for the first reviews,
+// then the previous interval stretched by the ease.
+func (c Card) good() int {
+	switch c.Reps {
+	case 0:
+		return firstGood
+	case 1:
+		return max(secondGood, c.Interval+1)
+	}
+	return c.next(float64(c.Interval) * float64(c.Ease) / 1000)
+}
+
+// next rounds the interval and makes it longer than the current one.
+func (c Card) next(interval float64) int {
+	return max(int(math.Round(interval)), c.Interval+1, 1)
+}
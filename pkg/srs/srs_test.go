@@ -0,0 +1,92 @@
+package srs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func review(t *testing.T, card Card, now time.Time, grades ...Grade) Card {
+	t.Helper()
+	for _, grade := range grades {
+		var err error
+		card, err = card.Review(grade, now)
+		require.NoError(t, err)
+		now = time.Unix(card.Due, 0)
+	}
+	return card
+}
+
+func TestReview(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name     string
+		grades   []Grade
+		interval int
+		ease     int
+		reps     int
+		lapses   int
+	}{
+		{"first good", []Grade{Good}, 1, 2500, 1, 0},
+		{"second good", []Grade{Good, Good}, 6, 2500, 2, 0},
+		{"third good", []Grade{Good, Good, Good}, 15, 2500, 3, 0},
+		{"first easy", []Grade{Easy}, 4, 2650, 1, 0},
+		{"hard", []Grade{Good, Good, Hard}, 7, 2350, 3, 0},
+		{"new forgotten", []Grade{Again}, 0, 2300, 0, 0},
+		{"lapse", []Grade{Good, Good, Again}, 0, 2300, 0, 1},
+		{"relearned", []Grade{Good, Good, Again, Good}, 1, 2300, 1, 1},
+		{"min ease", []Grade{Again, Again, Again, Again, Again, Again}, 0, MinEase, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := review(t, NewCard(), now, tt.grades...)
+			assert.Equal(t, tt.interval, card.Interval)
+			assert.Equal(t, tt.ease, card.Ease)
+			assert.Equal(t, tt.reps, card.Reps)
+			assert.Equal(t, tt.lapses, card.Lapses)
+		})
+	}
+}
+
+func TestReviewDue(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	card, err := NewCard().Review(Good, now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Unix(), card.Reviewed)
+	assert.Equal(t, now.Add(24*time.Hour).Unix(), card.Due)
+
+	later := now.Add(time.Hour)
+	card, err = card.Review(Again, later)
+	require.NoError(t, err)
+	assert.Equal(t, later.Add(RelearnDelay).Unix(), card.Due)
+}
+
+func TestReviewIntervalGrows(t *testing.T) {
+	card := review(t, NewCard(), time.Unix(1_700_000_000, 0), Again, Again, Again, Again, Again)
+	for range 20 {
+		previous := card.Interval
+		card = review(t, card, time.Unix(card.Due, 0), Hard)
+		assert.Greater(t, card.Interval, previous)
+	}
+	card.Interval = MaxInterval
+	card = review(t, card, time.Unix(card.Due, 0), Easy)
+	assert.Equal(t, MaxInterval, card.Interval)
+}
+
+func TestReviewErrors(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	card := review(t, NewCard(), now, Good)
+
+	_, err := card.Review(Grade(0), now)
+	assert.ErrorIs(t, err, ErrInvalidGrade)
+	_, err = card.Review(Grade(5), now)
+	assert.ErrorIs(t, err, ErrInvalidGrade)
+	_, err = card.Review(Good, now.Add(-time.Second))
+	assert.ErrorIs(t, err, ErrOutOfOrder)
+	_, err = card.Review(Good, now)
+	assert.ErrorIs(t, err, ErrOutOfOrder)
+}
@@ -5,13 +5,17 @@ import (
 	"encoding/hex"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 const (
 	dictionaryFileExt = ".json"
+	uploadsDir        = "uploads"
 )
 
+var uploadIDRegex = regexp.MustCompile(`^` + uploadsDir + `/[a-f0-9]{32}/[a-f0-9]{32}` + regexp.QuoteMeta(dictionaryFileExt) + `$`)
+
 // GenerateDictionaryID generates an MD5 hash from the concatenation of the dictionary name and author,
 // separated by a hyphen. This hash serves as the unique object ID for an item in the DynamoDB dictionary table.
 func GenerateDictionaryID(name, author string) string {
@@ -36,6 +40,29 @@ func RecordToFileID(id string) string {
 	}
 }
 
+// VersionFileID return file identifier of the dictionary version, versions are kept under the dictionary ID prefix.
+func VersionFileID(id string, version int) string {
+	if id == "" {
+		return ""
+	}
+	return id + "/" + strconv.Itoa(version) + dictionaryFileExt
+}
+
+// UploadFileID return file identifier of the dictionary uploaded by the user, uploads are kept under the prefix
+// derived from the user ID, so a user cannot replace files of other users or of the processing pipeline.
+func UploadFileID(userID, id string) string {
+	if userID == "" || id == "" {
+		return ""
+	}
+	hash := md5.Sum([]byte(userID))
+	return uploadsDir + "/" + hex.EncodeToString(hash[:]) + "/" + RecordToFileID(id)
+}
+
+// IsUploadFileID checks if the provided string is a file identifier returned by UploadFileID.
+func IsUploadFileID(s string) bool {
+	return uploadIDRegex.MatchString(s)
+}
+
 // IsFileID checks if the provided string is a valid file identifier
 // A valid file ID must have a .json extension and the filename (without extension)
 // must be a valid MD5 hash (32 hexadecimal characters)
@@ -2,7 +2,9 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"unicode"
 
@@ -15,9 +17,18 @@ type Validator struct {
 	validate *validator.Validate
 }
 
+// FieldError describes a single failed validation rule of a struct field.
+type FieldError struct {
+	Field string // Field path using json names, e.g. "words[0].name"
+	Rule  string // Failed validation tag, e.g. "required"
+	Param string // Tag parameter, e.g. "date rating" for "oneof=date rating"
+}
+
 // New returns a new Validator instance with registered custom validation tags.
+// Field names in validation errors are taken from json tags.
 func New() *Validator {
 	v := validator.New()
+	v.RegisterTagNameFunc(jsonFieldName)
 	registerCustomTags(v)
 
 	return &Validator{validate: v}
@@ -45,6 +56,41 @@ func (v *Validator) StructErrorToString(err error) string {
 	return err.Error()
 }
 
+// FieldErrors extracts per-field details from a ValidateStruct error.
+// Returns nil if err is not a validation error.
+func FieldErrors(err error) []FieldError {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+
+	fields := make([]FieldError, 0, len(errs))
+	for _, e := range errs {
+		field := e.Field()
+		if _, path, ok := strings.Cut(e.Namespace(), "."); ok {
+			field = path
+		}
+		fields = append(fields, FieldError{
+			Field: field,
+			Rule:  e.Tag(),
+			Param: e.Param(),
+		})
+	}
+	return fields
+}
+
+// jsonFieldName returns the json name of the struct field or the Go name if the tag is absent.
+func jsonFieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	}
+	return name
+}
+
 // registerCustomTags registers project-specific custom validation tags.
 func registerCustomTags(v *validator.Validate) {
 	_ = v.RegisterValidation("base_str", func(fl validator.FieldLevel) bool {
@@ -0,0 +1,183 @@
+// Package versions keeps the history of dictionary files. Every published file is stored
+// as an immutable object <id>/<version>.json and recorded in the version table, the dictionary
+// item points to the current version. The current file is also mirrored to <id>.json for clients
+// which download dictionaries by the file identifier.
+package versions
+
+import (
+	"context"
+	"time"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoversion"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+)
+
+// ErrConflict is returned when the dictionary was created, published or removed concurrently.
+var ErrConflict = errors.New("dictionary version was changed concurrently")
+
+// Store publishes dictionary files as versions.
+type Store struct {
+	dynamo           cloud.DynamoAPI
+	bucket           cloud.BucketAPI
+	dictionaryBucket string
+}
+
+// NewStore creates a new Store, versioned files are kept in dictionaryBucket.
+func NewStore(dynamo cloud.DynamoAPI, bucket cloud.BucketAPI, dictionaryBucket string) *Store {
+	return &Store{
+		dynamo:           dynamo,
+		bucket:           bucket,
+		dictionaryBucket: dictionaryBucket,
+	}
+}
+
+// Create copies the file as the first version of a new dictionary and puts the dictionary item.
+// It returns ErrConflict if the dictionary already exists.
+func (s *Store) Create(ctx context.Context, dict applingodictionary.SchemaItem, sourceKey, sourceBucket, actor string) error {
+	dict.Version = 1
+	record := applingoversion.SchemaItem{
+		DictionaryId: dict.Id,
+		Version:      dict.Version,
+		Created:      int(time.Now().Unix()),
+		Actor:        actor,
+	}
+	recordItem, err := applingoversion.PutItem(record)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal version")
+	}
+	dictItem, err := applingodictionary.PutItem(dict)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal dictionary")
+	}
+
+	writes := []cloud.TransactWriteItem{
+		{
+			Table: applingoversion.TableSchema.TableName,
+			Item:  recordItem,
+		},
+		{
+			Table:     applingodictionary.TableSchema.TableName,
+			Item:      dictItem,
+			Condition: expression.AttributeNotExists(expression.Name(applingodictionary.ColumnId)),
+		},
+	}
+	return s.publish(ctx, dict.Id, dict.Version, sourceKey, sourceBucket, writes)
+}
+
+// Publish copies the file as the next version of the existing dictionary and makes it current.
+// rollbackFrom is the version the file was copied from, 0 for new content.
+// It returns ErrConflict if the dictionary version is not dict.Version anymore.
+func (s *Store) Publish(ctx context.Context, dict applingodictionary.SchemaItem, sourceKey, sourceBucket, actor string, rollbackFrom int) (applingoversion.SchemaItem, error) {
+	record := applingoversion.SchemaItem{
+		DictionaryId: dict.Id,
+		Version:      dict.Version + 1,
+		Created:      int(time.Now().Unix()),
+		Actor:        actor,
+		RollbackFrom: rollbackFrom,
+	}
+	recordItem, err := applingoversion.PutItem(record)
+	if err != nil {
+		return applingoversion.SchemaItem{}, errors.Wrap(err, "failed to marshal version")
+	}
+	key, err := applingodictionary.CreateKeyFromItem(dict)
+	if err != nil {
+		return applingoversion.SchemaItem{}, errors.Wrap(err, "failed to create dictionary key")
+	}
+	update := expression.Set(expression.Name(applingodictionary.ColumnVersion), expression.Value(record.Version))
+
+	writes := []cloud.TransactWriteItem{
+		{
+			Table:     applingoversion.TableSchema.TableName,
+			Item:      recordItem,
+			Condition: expression.AttributeNotExists(expression.Name(applingoversion.ColumnVersion)),
+		},
+		{
+			Table:     applingodictionary.TableSchema.TableName,
+			Key:       key,
+			Update:    &update,
+			Condition: currentVersion(dict.Version),
+		},
+	}
+	if err := s.publish(ctx, dict.Id, record.Version, sourceKey, sourceBucket, writes); err != nil {
+		return applingoversion.SchemaItem{}, err
+	}
+	return record, nil
+}
+
+// Remove deletes the files and the records of the dictionary versions up to version.
+func (s *Store) Remove(ctx context.Context, id string, version int) error {
+	if err := s.bucket.Delete(ctx, utils.RecordToFileID(id), s.dictionaryBucket); err != nil {
+		return errors.Wrap(err, "failed to delete dictionary file")
+	}
+	for v := 1; v <= version; v++ {
+		if err := s.bucket.Delete(ctx, utils.VersionFileID(id, v), s.dictionaryBucket); err != nil {
+			return errors.Wrapf(err, "failed to delete version %d file", v)
+		}
+		key, err := applingoversion.CreateKey(id, v)
+		if err != nil {
+			return errors.Wrap(err, "failed to create version key")
+		}
+		if err := s.dynamo.Delete(ctx, applingoversion.TableSchema.TableName, key); err != nil {
+			return errors.Wrapf(err, "failed to delete version %d", v)
+		}
+	}
+	return nil
+}
+
+// publish copies the file to the version, applies the writes and mirrors the version to the current file.
+// The version file is copied before the writes, so the dictionary never points to a missing file,
+// and an existing version file is never overwritten.
+func (s *Store) publish(ctx context.Context, id string, version int, sourceKey, sourceBucket string, writes []cloud.TransactWriteItem) error {
+	fileID := utils.VersionFileID(id, version)
+	exists, err := s.bucket.Exists(ctx, fileID, s.dictionaryBucket)
+	if err != nil {
+		return errors.Wrap(err, "failed to check version file")
+	}
+	if exists {
+		return errors.Wrapf(ErrConflict, "version %d file already exists", version)
+	}
+	if err := s.copy(ctx, sourceKey, sourceBucket, fileID); err != nil {
+		return err
+	}
+	if err := s.dynamo.TransactWrite(ctx, writes); err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			// the file may belong to the concurrent publish of the same version, it is left in place.
+			return errors.Wrap(ErrConflict, err.Error())
+		}
+		if delErr := s.bucket.Delete(ctx, fileID, s.dictionaryBucket); delErr != nil {
+			return errors.Wrapf(err, "failed to save version, also cannot delete version file: %s", delErr)
+		}
+		return errors.Wrap(err, "failed to save version")
+	}
+	return s.copy(ctx, fileID, s.dictionaryBucket, utils.RecordToFileID(id))
+}
+
+// copy copies the object to the dictionary bucket and waits until it is visible.
+func (s *Store) copy(ctx context.Context, sourceKey, sourceBucket, destKey string) error {
+	if err := s.bucket.Copy(ctx, sourceKey, sourceBucket, destKey, s.dictionaryBucket); err != nil {
+		return errors.Wrapf(err, "failed to copy dictionary file to '%s'", destKey)
+	}
+	if err := s.bucket.WaitOrError(ctx, destKey, s.dictionaryBucket, 3, 200*time.Millisecond); err != nil {
+		return errors.Wrapf(err, "failed to check dictionary file '%s'", destKey)
+	}
+	return nil
+}
+
+// currentVersion is the condition of the dictionary still being at the version,
+// items created before versions have no version attribute.
+func currentVersion(version int) expression.ConditionBuilder {
+	exists := expression.AttributeExists(expression.Name(applingodictionary.ColumnId))
+	if version == 0 {
+		return exists.And(expression.AttributeNotExists(expression.Name(applingodictionary.ColumnVersion)).Or(
+			expression.Name(applingodictionary.ColumnVersion).Equal(expression.Value(0)),
+		))
+	}
+	return exists.And(expression.Name(applingodictionary.ColumnVersion).Equal(expression.Value(version)))
+}
@@ -0,0 +1,60 @@
+package versions
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingodictionary"
+	"github.com/Mad-Pixels/applingo-api/dynamodb-interface/gen/applingoversion"
+	"github.com/Mad-Pixels/applingo-api/pkg/cloud"
+	"github.com/Mad-Pixels/applingo-api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) (*Store, *cloud.MemoryDynamo, *cloud.MemoryBucket) {
+	t.Helper()
+
+	db := cloud.NewMemoryDynamo(
+		cloud.NewMemoryTable(applingodictionary.TableSchema),
+		cloud.NewMemoryTable(applingoversion.TableSchema),
+	)
+	bucket := cloud.NewMemoryBucket()
+	return NewStore(db, bucket, "dictionary"), db, bucket
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store, db, bucket := newTestStore(t)
+
+	dict := applingodictionary.SchemaItem{Id: "a1b2c3", Subcategory: "en-ru"}
+	require.NoError(t, bucket.Put(ctx, "a1b2c3.json", "processing", strings.NewReader(`{}`), cloud.ContentTypeJSON))
+	require.NoError(t, store.Create(ctx, dict, "a1b2c3.json", "processing", "processing"))
+	assert.ErrorIs(t, store.Create(ctx, dict, "a1b2c3.json", "processing", "processing"), ErrConflict)
+
+	dict.Version = 1
+	record, err := store.Publish(ctx, dict, utils.VersionFileID(dict.Id, 1), "dictionary", "user-1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, record.Version)
+	assert.Equal(t, 1, record.RollbackFrom)
+
+	// the first publish won, the version it was based on is stale.
+	_, err = store.Publish(ctx, dict, utils.VersionFileID(dict.Id, 1), "dictionary", "user-2", 1)
+	assert.ErrorIs(t, err, ErrConflict)
+
+	require.NoError(t, store.Remove(ctx, dict.Id, 2))
+	for _, key := range []string{"a1b2c3.json", utils.VersionFileID(dict.Id, 1), utils.VersionFileID(dict.Id, 2)} {
+		exists, err := bucket.Exists(ctx, key, "dictionary")
+		require.NoError(t, err)
+		assert.False(t, exists, key)
+	}
+	for v := 1; v <= 2; v++ {
+		key, err := applingoversion.CreateKey(dict.Id, v)
+		require.NoError(t, err)
+		exists, err := db.Exists(ctx, applingoversion.TableSchema.TableName, key)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	}
+}
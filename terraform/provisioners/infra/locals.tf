@@ -21,4 +21,40 @@ locals {
   profile_dynamo_schema = jsondecode(
     file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_profile_table.json")
   )
+
+  device_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_device_table.json")
+  )
+
+  nonce_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_nonce_table.json")
+  )
+
+  refresh_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_refresh_table.json")
+  )
+
+  rating_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_rating_table.json")
+  )
+
+  search_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_search_table.json")
+  )
+
+  version_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_version_table.json")
+  )
+
+  progress_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_progress_table.json")
+  )
+
+  ledger_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_ledger_table.json")
+  )
+
+  leaderboard_dynamo_schema = jsondecode(
+    file("${path.module}/../../../dynamodb-interface/.tmpl/dynamo_leaderboard_table.json")
+  )
 }
\ No newline at end of file
@@ -91,4 +91,135 @@ module "dynamo-profile-table" {
   stream_enabled       = false
 
   shared_tags = local.tags
-}
\ No newline at end of file
+}
+
+module "dynamo-nonce-table" {
+  source = "../../modules/dynamo"
+
+  project              = local.project
+  table_name           = local.nonce_dynamo_schema.table_name
+  hash_key             = local.nonce_dynamo_schema.hash_key
+  range_key            = local.nonce_dynamo_schema.range_key
+  attributes           = local.nonce_dynamo_schema.attributes
+  secondary_index_list = local.nonce_dynamo_schema.secondary_indexes
+  stream_enabled       = false
+  ttl_enabled          = true
+  ttl_attribute_name   = "expires_at"
+
+  shared_tags = local.tags
+}
+
+module "dynamo-device-table" {
+  source = "../../modules/dynamo"
+
+  project              = local.project
+  table_name           = local.device_dynamo_schema.table_name
+  hash_key             = local.device_dynamo_schema.hash_key
+  range_key            = local.device_dynamo_schema.range_key
+  attributes           = local.device_dynamo_schema.attributes
+  secondary_index_list = local.device_dynamo_schema.secondary_indexes
+  stream_enabled       = false
+
+  shared_tags = local.tags
+}
+
+module "dynamo-refresh-table" {
+  source = "../../modules/dynamo"
+
+  project              = local.project
+  table_name           = local.refresh_dynamo_schema.table_name
+  hash_key             = local.refresh_dynamo_schema.hash_key
+  range_key            = local.refresh_dynamo_schema.range_key
+  attributes           = local.refresh_dynamo_schema.attributes
+  secondary_index_list = local.refresh_dynamo_schema.secondary_indexes
+  stream_enabled       = false
+  ttl_enabled          = true
+  ttl_attribute_name   = "expires_at"
+
+  shared_tags = local.tags
+}
+
+module "dynamo-search-table" {
+  source = "../../modules/dynamo"
+
+  project              = local.project
+  table_name           = local.search_dynamo_schema.table_name
+  hash_key             = local.search_dynamo_schema.hash_key
+  range_key            = local.search_dynamo_schema.range_key
+  attributes           = local.search_dynamo_schema.attributes
+  secondary_index_list = local.search_dynamo_schema.secondary_indexes
+  stream_enabled       = false
+
+  shared_tags = local.tags
+}
+
+module "dynamo-rating-table" {
+  source = "../../modules/dynamo"
+
+  project              = local.project
+  table_name           = local.rating_dynamo_schema.table_name
+  hash_key             = local.rating_dynamo_schema.hash_key
+  range_key            = local.rating_dynamo_schema.range_key
+  attributes           = local.rating_dynamo_schema.attributes
+  secondary_index_list = local.rating_dynamo_schema.secondary_indexes
+  stream_enabled       = false
+
+  shared_tags = local.tags
+}
+
+module "dynamo-version-table" {
+  source = "../../modules/dynamo"
+
+  project              = local.project
+  table_name           = local.version_dynamo_schema.table_name
+  hash_key             = local.version_dynamo_schema.hash_key
+  range_key            = local.version_dynamo_schema.range_key
+  attributes           = local.version_dynamo_schema.attributes
+  secondary_index_list = local.version_dynamo_schema.secondary_indexes
+  stream_enabled       = false
+
+  shared_tags = local.tags
+}
+
+module "dynamo-progress-table" {
+  source = "../../modules/dynamo"
+
+  project              = local.project
+  table_name           = local.progress_dynamo_schema.table_name
+  hash_key             = local.progress_dynamo_schema.hash_key
+  range_key            = local.progress_dynamo_schema.range_key
+  attributes           = local.progress_dynamo_schema.attributes
+  secondary_index_list = local.progress_dynamo_schema.secondary_indexes
+  stream_enabled       = false
+
+  shared_tags = local.tags
+}
+
+module "dynamo-ledger-table" {
+  source = "../../modules/dynamo"
+
+  project              = local.project
+  table_name           = local.ledger_dynamo_schema.table_name
+  hash_key             = local.ledger_dynamo_schema.hash_key
+  range_key            = local.ledger_dynamo_schema.range_key
+  attributes           = local.ledger_dynamo_schema.attributes
+  secondary_index_list = local.ledger_dynamo_schema.secondary_indexes
+  stream_enabled       = true
+
+  stream_type = "NEW_IMAGE"
+  shared_tags = local.tags
+}
+
+module "dynamo-leaderboard-table" {
+  source = "../../modules/dynamo"
+
+  project              = local.project
+  table_name           = local.leaderboard_dynamo_schema.table_name
+  hash_key             = local.leaderboard_dynamo_schema.hash_key
+  range_key            = local.leaderboard_dynamo_schema.range_key
+  attributes           = local.leaderboard_dynamo_schema.attributes
+  secondary_index_list = local.leaderboard_dynamo_schema.secondary_indexes
+  stream_enabled       = false
+
+  shared_tags = local.tags
+}
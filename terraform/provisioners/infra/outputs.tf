@@ -64,4 +64,80 @@ output "dynamo-profile-table_name" {
 
 output "dynamo-profile-table_arn" {
   value = module.dynamo-profile-table.table_arn
-}
\ No newline at end of file
+}
+
+output "dynamo-nonce-table_name" {
+  value = module.dynamo-nonce-table.table_name
+}
+
+output "dynamo-nonce-table_arn" {
+  value = module.dynamo-nonce-table.table_arn
+}
+
+output "dynamo-device-table_name" {
+  value = module.dynamo-device-table.table_name
+}
+
+output "dynamo-device-table_arn" {
+  value = module.dynamo-device-table.table_arn
+}
+
+output "dynamo-refresh-table_name" {
+  value = module.dynamo-refresh-table.table_name
+}
+
+output "dynamo-refresh-table_arn" {
+  value = module.dynamo-refresh-table.table_arn
+}
+
+output "dynamo-search-table_name" {
+  value = module.dynamo-search-table.table_name
+}
+
+output "dynamo-search-table_arn" {
+  value = module.dynamo-search-table.table_arn
+}
+
+output "dynamo-rating-table_name" {
+  value = module.dynamo-rating-table.table_name
+}
+
+output "dynamo-rating-table_arn" {
+  value = module.dynamo-rating-table.table_arn
+}
+
+output "dynamo-version-table_name" {
+  value = module.dynamo-version-table.table_name
+}
+
+output "dynamo-version-table_arn" {
+  value = module.dynamo-version-table.table_arn
+}
+
+output "dynamo-progress-table_name" {
+  value = module.dynamo-progress-table.table_name
+}
+
+output "dynamo-progress-table_arn" {
+  value = module.dynamo-progress-table.table_arn
+}
+
+output "dynamo-ledger-table_name" {
+  value = module.dynamo-ledger-table.table_name
+}
+
+output "dynamo-ledger-table_arn" {
+  value = module.dynamo-ledger-table.table_arn
+}
+
+output "dynamo-ledger-stream_arn" {
+  value = module.dynamo-ledger-table.stream_arn
+}
+
+output "dynamo-leaderboard-table_name" {
+  value = module.dynamo-leaderboard-table.table_name
+}
+
+output "dynamo-leaderboard-table_arn" {
+  value = module.dynamo-leaderboard-table.table_arn
+}
@@ -26,8 +26,15 @@ locals {
 
   template_vars = {
     var_jwt_secret              = var.jwt_secret
+    var_jwt_jwks                = var.jwt_jwks
+    var_jwt_issuer              = var.jwt_issuer
+    var_jwt_audience            = var.jwt_audience
     var_openai_key              = var.openai_key
     var_device_api_token        = var.device_api_token
+    var_device_min_sig_version  = var.device_min_signature_version
+    var_device_master_key       = var.device_master_key
+    var_device_require_reg      = var.device_require_registration
+    var_auth_clients            = var.auth_clients
     log_errors_bucket_name      = data.terraform_remote_state.infra.outputs.s3-errors-bucket_name
     forge_bucket_name           = data.terraform_remote_state.infra.outputs.s3-forge-bucket_name
     forge_bucket_arn            = data.terraform_remote_state.infra.outputs.s3-forge-bucket_arn
@@ -41,6 +48,16 @@ locals {
     processing_table_arn        = data.terraform_remote_state.infra.outputs.dynamo-processing-table_arn
     processing_table_stream_arn = data.terraform_remote_state.infra.outputs.dynamo-processing-stream_arn
     profile_table_arn           = data.terraform_remote_state.infra.outputs.dynamo-profile-table_arn
+    nonce_table_arn             = data.terraform_remote_state.infra.outputs.dynamo-nonce-table_arn
+    device_table_arn            = data.terraform_remote_state.infra.outputs.dynamo-device-table_arn
+    refresh_table_arn           = data.terraform_remote_state.infra.outputs.dynamo-refresh-table_arn
+    search_table_arn            = data.terraform_remote_state.infra.outputs.dynamo-search-table_arn
+    rating_table_arn            = data.terraform_remote_state.infra.outputs.dynamo-rating-table_arn
+    version_table_arn           = data.terraform_remote_state.infra.outputs.dynamo-version-table_arn
+    progress_table_arn          = data.terraform_remote_state.infra.outputs.dynamo-progress-table_arn
+    ledger_table_arn            = data.terraform_remote_state.infra.outputs.dynamo-ledger-table_arn
+    ledger_table_stream_arn     = data.terraform_remote_state.infra.outputs.dynamo-ledger-stream_arn
+    leaderboard_table_arn       = data.terraform_remote_state.infra.outputs.dynamo-leaderboard-table_arn
   }
 }
 
@@ -51,7 +68,7 @@ locals {
 
   _lambda_functions = distinct([
     for v in local._entries : split("/", v)[0]
-    if length(split("/", v)) > 1 && !startswith(split("/", v)[0], "tool-")
+    if length(split("/", v)) > 1 && !startswith(split("/", v)[0], "tool-") && !startswith(split("/", v)[0], "local-")
   ])
 
   _lambda_configs = {
@@ -53,4 +53,56 @@ resource "aws_lambda_event_source_mapping" "dynamo-stream-dictionary" {
   maximum_retry_attempts = 0
 
   depends_on = [module.lambda_functions]
-}
\ No newline at end of file
+}
+
+resource "aws_lambda_event_source_mapping" "dynamo-stream-dictionary-index" {
+  event_source_arn       = local.template_vars.dictionary_table_stream_arn
+  function_name          = module.lambda_functions["trigger-dictionary-index"].function_arn
+  starting_position      = "LATEST"
+  maximum_retry_attempts = 2
+
+  depends_on = [module.lambda_functions]
+}
+
+resource "aws_lambda_event_source_mapping" "dynamo-stream-ledger" {
+  event_source_arn       = local.template_vars.ledger_table_stream_arn
+  function_name          = module.lambda_functions["trigger-leaderboard-update"].function_arn
+  starting_position      = "LATEST"
+  maximum_retry_attempts = 2
+
+  depends_on = [module.lambda_functions]
+}
+
+resource "aws_s3_bucket_notification" "processing-bucket-validate" {
+  bucket = local.template_vars.processing_bucket_name
+
+  lambda_function {
+    lambda_function_arn = module.lambda_functions["trigger-dictionary-validate"].function_arn
+    events              = ["s3:ObjectCreated:*"]
+    filter_suffix       = ".json"
+  }
+
+  depends_on = [module.lambda_functions]
+}
+
+resource "aws_cloudwatch_event_rule" "leaderboard-reset" {
+  name                = "${local.project}-leaderboard-reset"
+  description         = "Resets the weekly leaderboards after the ISO week ends"
+  schedule_expression = "cron(5 0 ? * MON *)"
+
+  tags = local.tags
+}
+
+resource "aws_cloudwatch_event_target" "leaderboard-reset" {
+  rule  = aws_cloudwatch_event_rule.leaderboard-reset.name
+  arn   = module.lambda_functions["scheduler-leaderboard-reset"].function_arn
+  input = jsonencode({ Records = [{ weeks = 4 }] })
+}
+
+resource "aws_lambda_permission" "leaderboard-reset" {
+  statement_id  = "AllowLeaderboardResetSchedule"
+  action        = "lambda:InvokeFunction"
+  function_name = module.lambda_functions["scheduler-leaderboard-reset"].function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.leaderboard-reset.arn
+}
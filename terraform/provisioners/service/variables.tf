@@ -10,6 +10,24 @@ variable "device_api_token" {
   type        = string
 }
 
+variable "device_master_key" {
+  description = "Master key deriving per-device secrets, per-device credentials are disabled if empty"
+  type        = string
+  default     = ""
+}
+
+variable "device_require_registration" {
+  description = "Restrict the shared device token to the device registration"
+  type        = bool
+  default     = false
+}
+
+variable "device_min_signature_version" {
+  description = "Oldest device signature scheme accepted by the authorizer (1 or 2)"
+  type        = number
+  default     = 1
+}
+
 variable "openai_key" {
   description = "OpenAI request key"
   type        = string
@@ -20,6 +38,30 @@ variable "jwt_secret" {
   type        = string
 }
 
+variable "jwt_jwks" {
+  description = "JWKS document with public keys for RS256/ES256 JWT validation"
+  type        = string
+  default     = ""
+}
+
+variable "jwt_issuer" {
+  description = "Expected JWT issuer, not checked if empty"
+  type        = string
+  default     = ""
+}
+
+variable "jwt_audience" {
+  description = "Expected JWT audience, not checked if empty"
+  type        = string
+  default     = ""
+}
+
+variable "auth_clients" {
+  description = "JSON map of client types to the role, scopes and token lifetimes issued by api-auth, defaults if empty"
+  type        = string
+  default     = ""
+}
+
 variable "environment" {
   description = "Stage environment"
   type        = string